@@ -0,0 +1,256 @@
+package rejex
+
+// Node is a single element of the syntax tree constructed by a RejexBuilder.
+// The tree is independent of the flavor and is only turned into a regex string
+// by Build()
+type Node interface {
+    isNode()
+}
+
+// Concat matches each of its nodes one after the other
+type Concat struct {
+    Nodes []Node
+}
+
+// Alternation matches any one of its alternatives
+type Alternation struct {
+    Alternatives []Node
+}
+
+// Literal matches its text exactly, regex metacharacters in it have no special meaning
+type Literal struct {
+    Text string
+}
+
+// Raw is regex syntax provided by the caller that is written to the pattern verbatim
+type Raw struct {
+    Text string
+}
+
+// ClassItemKind represents the kind of a single item of a character class
+type ClassItemKind int
+
+const (
+    RangeItem ClassItemKind = iota // A range of characters from Lo to Hi
+    DigitItem // \d
+    WordItem // \w
+    WhitespaceItem // \s
+    UnicodeItem // \p{Name}
+    AnyCharItem // .
+    GraphemeItem // \X
+    LinebreakItem // \R
+)
+
+// ClassItem is a single item of a character class
+type ClassItem struct {
+    Kind ClassItemKind
+    Lo, Hi rune
+    Name string
+    Negated bool
+}
+
+// Class matches a single character out of the set of characters described by its items
+type Class struct {
+    Items []ClassItem
+    Negated bool
+}
+
+// RepeatMode represents how a quantifier consumes characters
+type RepeatMode int
+
+const (
+    Greedy RepeatMode = iota // As many as possible
+    Lazy // As few as possible
+    Possessive // As many as possible, without backtracking
+)
+
+// Repeat matches its sub node between Min and Max times, a Max of -1 means no upper bound
+type Repeat struct {
+    Sub Node
+    Min, Max int
+    Mode RepeatMode
+}
+
+// GroupKind represents the kind of a group construct
+type GroupKind int
+
+const (
+    CaptureGroup GroupKind = iota
+    NamedCaptureGroup
+    NonCaptureGroup
+    FlagGroup
+    AtomicGroup
+    BranchResetGroup
+    PosLookahead
+    NegLookahead
+    PosLookbehind
+    NegLookbehind
+    ConditionalGroup
+    DefineGroup
+)
+
+// Group is a group construct around its body. The Flags of a FlagGroup following
+// a '-' are turned off within the group. A ConditionalGroup matches the first
+// alternative of its body if the group referenced by Num or Name has captured text
+// and the second one, if any, otherwise; a negative Num is relative to the group. A
+// DefineGroup never matches, its groups are only called as subroutines
+type Group struct {
+    Kind GroupKind
+    Name string
+    Num int
+    Flags []RejexFlag
+    Body Node
+}
+
+// AssertionKind represents the kind of a zero width assertion
+type AssertionKind int
+
+const (
+    AssertLineStart AssertionKind = iota // ^
+    AssertTextStart // \A
+    AssertLineEnd // $
+    AssertTextEnd // \z
+    AssertTextEndNewline // \Z
+    AssertWordBoundary // \b
+    AssertNonWordBoundary // \B
+    AssertLastMatchEnd // \G
+    AssertMatchStart // \K, the reported match starts here
+)
+
+// Assertion matches a position in the input without consuming any characters
+type Assertion struct {
+    Kind AssertionKind
+}
+
+// Backref matches the text previously captured by a group, referenced either by
+// its number or its name. A negative number is relative to the reference
+type Backref struct {
+    Num int
+    Name string
+}
+
+// Recursion matches the pattern of a group again at its position, referenced either by
+// its number or its name, or the whole pattern if it has neither. A negative number is
+// relative to the reference
+type Recursion struct {
+    Num int
+    Name string
+}
+
+// Verb is a backtracking control verb such as (*SKIP), which changes what happens when
+// the engine backtracks over it. Arg is the name given to the verb, if any
+type Verb struct {
+    Name string
+    Arg string
+}
+
+func (*Concat) isNode() {}
+func (*Alternation) isNode() {}
+func (*Literal) isNode() {}
+func (*Raw) isNode() {}
+func (*Class) isNode() {}
+func (*Repeat) isNode() {}
+func (*Group) isNode() {}
+func (*Assertion) isNode() {}
+func (*Backref) isNode() {}
+func (*Recursion) isNode() {}
+func (*Verb) isNode() {}
+
+// joinNodes combines a list of sequential nodes into a single node
+func joinNodes(nodes []Node) Node {
+    if len(nodes) == 1 {
+        return nodes[0]
+    }
+    return &Concat{nodes}
+}
+
+// joinAlternatives combines a list of alternatives, each a list of sequential nodes,
+// into a single node
+func joinAlternatives(alts [][]Node) Node {
+    if len(alts) == 1 {
+        return joinNodes(alts[0])
+    }
+    a := &Alternation{}
+    for _, nodes := range alts {
+        a.Alternatives = append(a.Alternatives, joinNodes(nodes))
+    }
+    return a
+}
+
+// walkNodes calls fn for a node and every node nested in it, parents before their children
+func walkNodes(n Node, fn func(Node)) {
+    fn(n)
+    switch n := n.(type) {
+    case *Concat:
+        for _, sub := range n.Nodes {
+            walkNodes(sub, fn)
+        }
+    case *Alternation:
+        for _, alt := range n.Alternatives {
+            walkNodes(alt, fn)
+        }
+    case *Repeat:
+        walkNodes(n.Sub, fn)
+    case *Group:
+        walkNodes(n.Body, fn)
+    }
+}
+
+// hasRaw reports whether a tree holds regex syntax written verbatim
+func hasRaw(n Node) bool {
+    found := false
+    walkNodes(n, func(n Node) {
+        _, raw := n.(*Raw)
+        found = found || raw
+    })
+    return found
+}
+
+// cloneNode returns a deep copy of a node, calling fn for every node copied along
+// with its copy
+func cloneNode(n Node, fn func(orig, copy Node)) Node {
+    var c Node
+    switch n := n.(type) {
+    case *Concat:
+        nodes := make([]Node, len(n.Nodes))
+        for i, sub := range n.Nodes {
+            nodes[i] = cloneNode(sub, fn)
+        }
+        c = &Concat{nodes}
+    case *Alternation:
+        alts := make([]Node, len(n.Alternatives))
+        for i, alt := range n.Alternatives {
+            alts[i] = cloneNode(alt, fn)
+        }
+        c = &Alternation{alts}
+    case *Literal:
+        c = &Literal{n.Text}
+    case *Raw:
+        c = &Raw{n.Text}
+    case *Class:
+        c = &Class{Items: append([]ClassItem{}, n.Items...), Negated: n.Negated}
+    case *Repeat:
+        c = &Repeat{Sub: cloneNode(n.Sub, fn), Min: n.Min, Max: n.Max, Mode: n.Mode}
+    case *Group:
+        c = &Group{Kind: n.Kind, Name: n.Name, Num: n.Num, Flags: append([]RejexFlag{}, n.Flags...), Body: cloneNode(n.Body, fn)}
+    case *Assertion:
+        c = &Assertion{n.Kind}
+    case *Backref:
+        c = &Backref{Num: n.Num, Name: n.Name}
+    case *Recursion:
+        c = &Recursion{Num: n.Num, Name: n.Name}
+    case *Verb:
+        c = &Verb{Name: n.Name, Arg: n.Arg}
+    }
+    fn(n, c)
+    return c
+}
+
+// conditionalBranches returns the alternative matched by a conditional group when its
+// condition holds and the one matched otherwise, which is nil if it has none
+func conditionalBranches(g *Group) (yes, no Node) {
+    if alt, ok := g.Body.(*Alternation); ok && len(alt.Alternatives) == 2 {
+        return alt.Alternatives[0], alt.Alternatives[1]
+    }
+    return g.Body, nil
+}
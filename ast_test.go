@@ -0,0 +1,118 @@
+package rejex
+
+import (
+    "reflect"
+    "testing"
+)
+
+// fromString returns a builder of the provided flavor holding a regex string
+func fromString(flavor RejexFlavor, s string) *RejexBuilder {
+    r := createRejexBuilder(flavor, []bool{true})
+    r.appendPattern(s)
+    return r
+}
+
+// failed reports whether any of the errors is not a warning
+func failed(errs []RejexError) bool {
+    for _, err := range errs {
+        if !err.Warning {
+            return true
+        }
+    }
+    return false
+}
+
+// TestBuilderTree checks the tree constructed by method chains and the pattern it is
+// rendered as
+func TestBuilderTree(t *testing.T) {
+    tests := []struct {
+        builder *RejexBuilder
+        tree Node
+        want string
+    }{
+        {
+            NewRejex().EscapedCharacters("a.b").Builder(),
+            &Literal{"a.b"},
+            `a\.b`,
+        },
+        {
+            NewRejex().Characters("a.b").Builder(),
+            &Concat{[]Node{&Literal{"a"}, &Class{Items: []ClassItem{{Kind: AnyCharItem}}}, &Literal{"b"}}},
+            `a.b`,
+        },
+        {
+            NewRejex().Starting().AnyDigit().OneOrMoreOf("").Ending().Builder(),
+            &Concat{[]Node{
+                &Assertion{AssertLineStart},
+                &Repeat{Sub: &Class{Items: []ClassItem{{Kind: DigitItem}}}, Min: 1, Max: -1},
+                &Assertion{AssertLineEnd},
+            }},
+            `^\d+$`,
+        },
+        {
+            NewRejex().Not().AnyDigit().Builder(),
+            &Class{Items: []ClassItem{{Kind: DigitItem}}, Negated: true},
+            `\D`,
+        },
+        {
+            NewRejex().Characters("ab").Or().Characters("c").Builder(),
+            &Alternation{[]Node{&Literal{"ab"}, &Literal{"c"}}},
+            `ab|c`,
+        },
+        {
+            NewRejex().BeginNamedCaptureGroup("x").Characters("a").Or().Characters("b").EndGroup().ZeroOrMoreOf("").Builder(),
+            &Repeat{Sub: &Group{Kind: NamedCaptureGroup, Name: "x", Body: &Alternation{[]Node{&Literal{"a"}, &Literal{"b"}}}}, Min: 0, Max: -1},
+            `(?P<x>a|b)*`,
+        },
+        {
+            NewRejex().Characters("ab").NOrMoreOf("", 2).PreferFewer().Builder(),
+            &Repeat{Sub: &Literal{"ab"}, Min: 2, Max: -1, Mode: Lazy},
+            `(?:ab){2,}?`,
+        },
+    }
+    for _, test := range tests {
+        got, errs := test.builder.Build()
+        if len(errs) > 0 || got != test.want {
+            t.Errorf("built %s %v, want %s", got, errs, test.want)
+        }
+        if tree := test.builder.Tree(); !reflect.DeepEqual(tree, test.tree) {
+            t.Errorf("the tree of %s differs from the expected tree of %s", test.want, render(GoFlavor, test.tree))
+        }
+    }
+}
+
+// TestBuilderErrors checks that misuses of the chain are reported as errors
+func TestBuilderErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        builder *RejexBuilder
+    }{
+        {"unclosed group", NewRejex(true).BeginCaptureGroup().Characters("a").Builder()},
+        {"unclosed selection set", NewRejex(true).BeginSelectionSet().Characters("a").Builder()},
+        {"group ended twice", NewRejex(true).BeginCaptureGroup().EndGroup().EndGroup().Builder()},
+        {"quantifier without a segment", NewRejex(true).OneOrMoreOf("").Builder()},
+        {"negative count", NewRejex(true).NOf("a", -1).Builder()},
+        {"negative minimum", NewPerlRejex(true).NOrMoreOf("a", -2).Builder()},
+        {"negative maximum", NewPerlRejex(true).NToMOf("a", 0, -1).Builder()},
+        {"minimum above the maximum", NewECMARejex(true).NToMOf("a", 3, 1).Builder()},
+    }
+    for _, test := range tests {
+        if _, errs := test.builder.Build(); !failed(errs) {
+            t.Errorf("%s is not reported as an error", test.name)
+        }
+    }
+}
+
+// TestBuildErrorsOnce checks that building a builder again does not repeat its errors
+func TestBuildErrorsOnce(t *testing.T) {
+    r := NewRejex(true).BeginCaptureGroup().Characters("a").BeginSelectionSet().Builder()
+    first, _ := r.Build()
+    for i := 0; i < 3; i++ {
+        if got, errs := r.BuildFor(PerlFlavor); len(errs) != 2 {
+            t.Errorf("build %d of %s reports %d errors: %v", i, got, len(errs), errs)
+        }
+    }
+    if got, _ := r.Build(); got != first {
+        t.Errorf("building again renders %s, want %s", got, first)
+    }
+}
@@ -0,0 +1,539 @@
+package rejex
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// AutomatonKind represents the kind of automaton exported by ExportDOT
+type AutomatonKind int
+
+const (
+    NFA AutomatonKind = iota // Nondeterministic finite automaton, as constructed from the pattern
+    DFA // Minimized deterministic finite automaton
+)
+
+// maxStates limits the size of the automatons constructed from a pattern
+const maxStates = 10000
+
+type edgeKind int
+
+const (
+    epsilonEdge edgeKind = iota
+    charEdge
+    // markerEdge is a zero width construct, such as the start of a capture group
+    markerEdge
+)
+
+type nfaEdge struct {
+    to int
+    kind edgeKind
+    set runeSet
+    label string
+}
+
+// nfaBuilder constructs a nondeterministic finite automaton out of a syntax tree by
+// Thompson's construction. Constructs which cannot be part of a DFA are reported as
+// errors when constructing one for a DFA
+type nfaBuilder struct {
+    r *RejexBuilder
+    numbers *groupNumbers
+    forDFA bool
+    // anchors holds the assertions at the start or end of the pattern, which need no
+    // transitions when matching whole inputs
+    anchors map[Node]bool
+
+    edges [][]nfaEdge
+    err *RejexError
+}
+
+func (b *nfaBuilder) state() int {
+    if len(b.edges) == maxStates {
+        b.fail(nil, "The pattern needs more than %d states", maxStates)
+        return 0
+    }
+    b.edges = append(b.edges, nil)
+    return len(b.edges) - 1
+}
+
+func (b *nfaBuilder) edge(from int, e nfaEdge) {
+    if b.err == nil {
+        b.edges[from] = append(b.edges[from], e)
+    }
+}
+
+// marker adds a transition through a zero width construct, which is an epsilon
+// transition in a DFA
+func (b *nfaBuilder) marker(from int, label string) int {
+    to := b.state()
+    if b.forDFA {
+        b.edge(from, nfaEdge{to: to})
+    } else {
+        b.edge(from, nfaEdge{to: to, kind: markerEdge, label: label})
+    }
+    return to
+}
+
+func (b *nfaBuilder) fail(n Node, format string, a ...interface{}) {
+    if b.err != nil {
+        return
+    }
+    b.err = &RejexError{Err: fmt.Sprintf(format, a...)}
+    if n != nil {
+        b.err.Position = b.r.positionOf(b.r.flavor, n)
+    }
+}
+
+// unsupported fails for a node which cannot be expressed in a DFA
+func (b *nfaBuilder) unsupported(n Node) bool {
+    if b.forDFA {
+        b.fail(n, "'%s()' cannot be expressed in a DFA", b.r.methodOf(n))
+    }
+    return b.forDFA
+}
+
+// build adds the transitions for a node starting from a state and returns the state
+// they end at
+func (b *nfaBuilder) build(n Node, f matchFlags, from int) int {
+    if b.err != nil {
+        return from
+    }
+    switch n := n.(type) {
+    case *Concat:
+        for _, sub := range n.Nodes {
+            from = b.build(sub, f, from)
+        }
+        return from
+    case *Alternation:
+        end := b.state()
+        for _, alt := range n.Alternatives {
+            start := b.state()
+            b.edge(from, nfaEdge{to: start})
+            b.edge(b.build(alt, f, start), nfaEdge{to: end})
+        }
+        return end
+    case *Literal:
+        for _, c := range n.Text {
+            to := b.state()
+            set := runeSet{{c, c}}
+            if f.foldCase {
+                set = set.foldSet()
+            }
+            b.edge(from, nfaEdge{to: to, kind: charEdge, set: set})
+            from = to
+        }
+        return from
+    case *Class:
+        if hasLinebreak(n) {
+            return b.linebreak(n, f, from)
+        }
+        to := b.state()
+        b.edge(from, nfaEdge{to: to, kind: charEdge, set: classSet(b.r.flavor, n, f)})
+        return to
+    case *Repeat:
+        return b.repeat(n, f, from)
+    case *Group:
+        return b.group(n, f, from)
+    case *Assertion:
+        if b.anchors[n] {
+            to := b.state()
+            b.edge(from, nfaEdge{to: to})
+            return to
+        }
+        if b.unsupported(n) {
+            return from
+        }
+        return b.marker(from, render(b.r.flavor, n))
+    case *Backref, *Recursion, *Verb:
+        if b.unsupported(n) {
+            return from
+        }
+        return b.marker(from, render(b.r.flavor, n))
+    }
+    b.fail(n, "The pattern has syntax errors")
+    return from
+}
+
+func (b *nfaBuilder) repeat(n *Repeat, f matchFlags, from int) int {
+    if n.Mode == Possessive && b.unsupported(n) {
+        return from
+    }
+    for i := 0; i < n.Min; i++ {
+        from = b.build(n.Sub, f, from)
+    }
+    end := b.state()
+    if n.Max == -1 {
+        loop := b.state()
+        b.edge(from, nfaEdge{to: loop})
+        b.edge(b.build(n.Sub, f, loop), nfaEdge{to: loop})
+        b.edge(loop, nfaEdge{to: end})
+        return end
+    }
+    for i := n.Min; i < n.Max; i++ {
+        b.edge(from, nfaEdge{to: end})
+        from = b.build(n.Sub, f, from)
+    }
+    b.edge(from, nfaEdge{to: end})
+    return end
+}
+
+func (b *nfaBuilder) group(n *Group, f matchFlags, from int) int {
+    switch n.Kind {
+    case CaptureGroup, NamedCaptureGroup:
+        name := fmt.Sprint(b.numbers.groups[n])
+        if n.Name != "" {
+            name = n.Name
+        }
+        from = b.marker(from, "("+name)
+        return b.marker(b.build(n.Body, f, from), name+")")
+    case FlagGroup:
+        return b.build(n.Body, f.with(n.Flags), from)
+    case AtomicGroup:
+        if b.unsupported(n) {
+            return from
+        }
+        from = b.marker(from, "(?>")
+        return b.marker(b.build(n.Body, f, from), ")")
+    case PosLookahead, NegLookahead, PosLookbehind, NegLookbehind, ConditionalGroup, DefineGroup:
+        if b.unsupported(n) {
+            return from
+        }
+        return b.marker(from, render(b.r.flavor, n))
+    }
+    return b.build(n.Body, f, from)
+}
+
+// hasLinebreak reports whether a class contains \R, which also matches \r\n
+func hasLinebreak(c *Class) bool {
+    for _, item := range c.Items {
+        if item.Kind == LinebreakItem {
+            return true
+        }
+    }
+    return false
+}
+
+// linebreak adds the transitions for a class containing \R, as the alternatives of \r\n
+// and a single character. A DFA cannot express that \r\n is never backtracked into
+func (b *nfaBuilder) linebreak(n *Class, f matchFlags, from int) int {
+    if b.unsupported(n) {
+        return from
+    }
+    end := b.state()
+    b.edge(b.build(&Literal{"\r\n"}, f, from), nfaEdge{to: end})
+    b.edge(from, nfaEdge{to: end, kind: charEdge, set: classSet(b.r.flavor, n, f)})
+    return end
+}
+
+// edgeAnchors returns the assertions which are at the start or end of a pattern
+func edgeAnchors(root Node) map[Node]bool {
+    anchors := map[Node]bool{}
+    nodes := []Node{root}
+    if concat, ok := root.(*Concat); ok {
+        nodes = concat.Nodes
+    }
+    for _, n := range nodes {
+        a, ok := n.(*Assertion)
+        if !ok || a.Kind != AssertLineStart && a.Kind != AssertTextStart {
+            break
+        }
+        anchors[n] = true
+    }
+    for i := len(nodes) - 1; i >= 0; i-- {
+        a, ok := nodes[i].(*Assertion)
+        if !ok || a.Kind != AssertLineEnd && a.Kind != AssertTextEnd && a.Kind != AssertTextEndNewline {
+            break
+        }
+        anchors[nodes[i]] = true
+    }
+    return anchors
+}
+
+// dfaState is a state of a DFA, with its transitions indexed by symbol
+type dfaState struct {
+    next map[int]int
+    accept bool
+}
+
+// symbols partitions the characters of the transitions of an NFA into sets which
+// every transition either contains entirely or not at all
+func symbols(edges [][]nfaEdge) []runeSet {
+    var sets []runeSet
+    bounds := map[rune]bool{}
+    for _, out := range edges {
+        for _, e := range out {
+            if e.kind != charEdge {
+                continue
+            }
+            sets = append(sets, e.set)
+            for _, rr := range e.set {
+                bounds[rr.lo], bounds[rr.hi+1] = true, true
+            }
+        }
+    }
+    var points []rune
+    for p := range bounds {
+        points = append(points, p)
+    }
+    sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+    var keys []string
+    grouped := map[string][]runeRange{}
+    for i := 0; i+1 < len(points); i++ {
+        var key strings.Builder
+        for _, s := range sets {
+            if s.contains(points[i]) {
+                key.WriteByte('1')
+            } else {
+                key.WriteByte('0')
+            }
+        }
+        if !strings.Contains(key.String(), "1") {
+            continue
+        }
+        if _, ok := grouped[key.String()]; !ok {
+            keys = append(keys, key.String())
+        }
+        grouped[key.String()] = append(grouped[key.String()], runeRange{points[i], points[i+1] - 1})
+    }
+    var syms []runeSet
+    for _, k := range keys {
+        syms = append(syms, newRuneSet(grouped[k]...))
+    }
+    return syms
+}
+
+// closure returns the sorted states reachable from a set of states through epsilon transitions
+func closure(edges [][]nfaEdge, states []int) []int {
+    seen := map[int]bool{}
+    stack := append([]int{}, states...)
+    for len(stack) > 0 {
+        s := stack[len(stack)-1]
+        stack = stack[:len(stack)-1]
+        if seen[s] {
+            continue
+        }
+        seen[s] = true
+        for _, e := range edges[s] {
+            if e.kind == epsilonEdge {
+                stack = append(stack, e.to)
+            }
+        }
+    }
+    var result []int
+    for s := range seen {
+        result = append(result, s)
+    }
+    sort.Ints(result)
+    return result
+}
+
+// determinize converts an NFA into a DFA by the subset construction
+func determinize(edges [][]nfaEdge, start, accept int, syms []runeSet) ([]dfaState, *RejexError) {
+    var states []dfaState
+    var subsets [][]int
+    index := map[string]int{}
+    add := func(subset []int) int {
+        key := fmt.Sprint(subset)
+        if i, ok := index[key]; ok {
+            return i
+        }
+        index[key] = len(states)
+        st := dfaState{next: map[int]int{}}
+        for _, s := range subset {
+            st.accept = st.accept || s == accept
+        }
+        states = append(states, st)
+        subsets = append(subsets, subset)
+        return len(states) - 1
+    }
+
+    add(closure(edges, []int{start}))
+    for i := 0; i < len(states); i++ {
+        if len(states) > maxStates {
+            return nil, &RejexError{Err: fmt.Sprintf("The DFA needs more than %d states", maxStates)}
+        }
+        for sym, set := range syms {
+            var next []int
+            for _, s := range subsets[i] {
+                for _, e := range edges[s] {
+                    if e.kind == charEdge && e.set.contains(set[0].lo) {
+                        next = append(next, e.to)
+                    }
+                }
+            }
+            if len(next) > 0 {
+                states[i].next[sym] = add(closure(edges, next))
+            }
+        }
+    }
+    return states, nil
+}
+
+// minimize merges the equivalent states of a DFA by partition refinement, the start
+// state stays first
+func minimize(states []dfaState, syms []runeSet) []dfaState {
+    class := make([]int, len(states))
+    for i, s := range states {
+        if s.accept {
+            class[i] = 1
+        }
+    }
+    for count := 0; ; {
+        index := map[string]int{}
+        next := make([]int, len(states))
+        for i, s := range states {
+            key := fmt.Sprint(class[i])
+            for sym := range syms {
+                if to, ok := s.next[sym]; ok {
+                    key += fmt.Sprintf(",%d", class[to])
+                } else {
+                    key += ",-"
+                }
+            }
+            if _, ok := index[key]; !ok {
+                index[key] = len(index)
+            }
+            next[i] = index[key]
+        }
+        class = next
+        if len(index) == count {
+            break
+        }
+        count = len(index)
+    }
+
+    // renumber the classes in the order their states appear, keeping the start first
+    order := map[int]int{}
+    for _, c := range class {
+        if _, ok := order[c]; !ok {
+            order[c] = len(order)
+        }
+    }
+    merged := make([]dfaState, len(order))
+    for i, s := range states {
+        m := &merged[order[class[i]]]
+        if m.next != nil {
+            continue
+        }
+        m.accept = s.accept
+        m.next = map[int]int{}
+        for sym, to := range s.next {
+            m.next[sym] = order[class[to]]
+        }
+    }
+    return merged
+}
+
+// setLabel returns a label for a set of characters in the class syntax of a flavor
+func setLabel(flavor RejexFlavor, s runeSet) string {
+    if s.size() == 1 {
+        return render(flavor, &Literal{string(s[0].lo)})
+    }
+    c := s.complement()
+    if len(c) == 0 {
+        return "any"
+    }
+    w := renderer{flavor: flavor}
+    w.WriteString("[")
+    if c.size() < s.size() {
+        w.WriteString("^")
+        s = c
+    }
+    for _, rr := range s {
+        if rr.hi == rr.lo+1 {
+            w.classItem(charRange(rr.lo, rr.lo))
+            rr.lo = rr.hi
+        }
+        w.classItem(charRange(rr.lo, rr.hi))
+    }
+    w.WriteString("]")
+    return w.String()
+}
+
+func dotQuote(s string) string {
+    return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// ExportDOT returns the Graphviz DOT source of an automaton which matches the regex.
+// The NFA has labeled transitions for the capture groups, anchors, lookarounds and
+// backreferences of the regex. The minimized DFA matches whole inputs, so it can only
+// be constructed for regexes which consist of characters, quantifiers and groups,
+// optionally anchored at the start and end
+func (r *RejexBuilder) ExportDOT(kind AutomatonKind) (string, error) {
+    root := r.Tree()
+    b := nfaBuilder{
+        r: r,
+        numbers: numberGroups(root),
+        forDFA: kind == DFA,
+        anchors: map[Node]bool{},
+    }
+    if b.forDFA {
+        b.anchors = edgeAnchors(root)
+    }
+    start := b.state()
+    accept := b.build(root, newMatchFlags(root, r.flags), start)
+    if b.err != nil {
+        return "", b.err
+    }
+
+    var out strings.Builder
+    if kind == NFA {
+        out.WriteString("digraph NFA {\n")
+    } else {
+        out.WriteString("digraph DFA {\n")
+    }
+    out.WriteString("    rankdir=LR;\n    node [shape=circle];\n    start [shape=point];\n    start -> 0;\n")
+
+    if kind == NFA {
+        fmt.Fprintf(&out, "    %d [shape=doublecircle];\n", accept)
+        for from, edges := range b.edges {
+            for _, e := range edges {
+                switch e.kind {
+                case epsilonEdge:
+                    fmt.Fprintf(&out, "    %d -> %d [label=\"ε\"];\n", from, e.to)
+                case charEdge:
+                    fmt.Fprintf(&out, "    %d -> %d [label=%s];\n", from, e.to, dotQuote(setLabel(r.flavor, e.set)))
+                case markerEdge:
+                    fmt.Fprintf(&out, "    %d -> %d [label=%s, style=dashed];\n", from, e.to, dotQuote(e.label))
+                }
+            }
+        }
+        out.WriteString("}\n")
+        return out.String(), nil
+    }
+
+    syms := symbols(b.edges)
+    states, err := determinize(b.edges, start, accept, syms)
+    if err != nil {
+        return "", err
+    }
+    states = minimize(states, syms)
+    for i, s := range states {
+        if s.accept {
+            fmt.Fprintf(&out, "    %d [shape=doublecircle];\n", i)
+        }
+    }
+    for i, s := range states {
+        // transitions to the same state are combined into a single labeled edge
+        targets := map[int][]runeRange{}
+        var order []int
+        for sym := range syms {
+            to, ok := s.next[sym]
+            if !ok {
+                continue
+            }
+            if _, seen := targets[to]; !seen {
+                order = append(order, to)
+            }
+            targets[to] = append(targets[to], syms[sym]...)
+        }
+        for _, to := range order {
+            label := setLabel(r.flavor, newRuneSet(targets[to]...))
+            fmt.Fprintf(&out, "    %d -> %d [label=%s];\n", i, to, dotQuote(label))
+        }
+    }
+    out.WriteString("}\n")
+    return out.String(), nil
+}
@@ -0,0 +1,172 @@
+package rejex
+
+import (
+    "strings"
+    "testing"
+)
+
+// minimalDFA constructs the minimized DFA of a regex like ExportDOT
+func minimalDFA(r *RejexBuilder) ([]dfaState, []runeSet, *RejexError) {
+    root := r.Tree()
+    b := nfaBuilder{r: r, numbers: numberGroups(root), forDFA: true, anchors: edgeAnchors(root)}
+    start := b.state()
+    accept := b.build(root, newMatchFlags(root, r.flags), start)
+    if b.err != nil {
+        return nil, nil, b.err
+    }
+    syms := symbols(b.edges)
+    states, err := determinize(b.edges, start, accept, syms)
+    if err != nil {
+        return nil, nil, err
+    }
+    return minimize(states, syms), syms, nil
+}
+
+// runDFA reports whether a DFA accepts the whole input
+func runDFA(states []dfaState, syms []runeSet, s string) bool {
+    state := 0
+    for _, c := range s {
+        next := -1
+        for sym, set := range syms {
+            if set.contains(c) {
+                if to, ok := states[state].next[sym]; ok {
+                    next = to
+                }
+                break
+            }
+        }
+        if next < 0 {
+            return false
+        }
+        state = next
+    }
+    return states[state].accept
+}
+
+// allStrings returns every string of up to n characters from an alphabet
+func allStrings(alphabet string, n int) []string {
+    strs := []string{""}
+    last := []string{""}
+    for i := 0; i < n; i++ {
+        var next []string
+        for _, s := range last {
+            for _, c := range alphabet {
+                next = append(next, s+string(c))
+            }
+        }
+        strs = append(strs, next...)
+        last = next
+    }
+    return strs
+}
+
+// TestDFAMatches checks that the minimized DFA accepts the same inputs as the pattern
+// matches in their entirety
+func TestDFAMatches(t *testing.T) {
+    patterns := []string{
+        `(a|b)*abb`,
+        `^[a-c]+\d?$`,
+        `(?i)ab|cd*`,
+        `x{2,4}`,
+        `(?:ab)+|a*`,
+        `[^a]b`,
+        `(a|ab)(c|bcd)`,
+        `a*?b+?`,
+    }
+    inputs := allStrings("abcdx1A", 4)
+    for _, pattern := range patterns {
+        r := fromString(GoFlavor, pattern)
+        states, syms, err := minimalDFA(r)
+        if err != nil {
+            t.Errorf("%s has no DFA: %v", pattern, err)
+            continue
+        }
+        m := newMatcher(GoFlavor, r.Tree(), r.flags)
+        for _, s := range inputs {
+            if got, want := runDFA(states, syms, s), m.fullMatch(s); got != want {
+                t.Errorf("DFA of %s accepts %q: %v, want %v", pattern, s, got, want)
+            }
+        }
+    }
+}
+
+// TestDFAStates checks that the DFA is minimized, the patterns of each case having the
+// same minimal DFA
+func TestDFAStates(t *testing.T) {
+    tests := []struct {
+        patterns []string
+        states int
+    }{
+        {[]string{`(a|b)*abb`, `[ab]*abb`, `(a*b*)*abb`}, 4},
+        {[]string{`a+`, `aa*`, `a*a`, `(a|a)+`}, 2},
+        {[]string{`[ab]*`, `(a|b)*`, `(a*b*)*`}, 1},
+        {[]string{`^a$`, `a`}, 2},
+    }
+    for _, test := range tests {
+        var want string
+        for _, pattern := range test.patterns {
+            r := fromString(GoFlavor, pattern)
+            states, _, err := minimalDFA(r)
+            if err != nil || len(states) != test.states {
+                t.Errorf("DFA of %s has %d states, want %d: %v", pattern, len(states), test.states, err)
+            }
+            dot, derr := r.ExportDOT(DFA)
+            if derr != nil {
+                t.Errorf("DFA of %s is not exported: %v", pattern, derr)
+            }
+            if want == "" {
+                want = dot
+            } else if dot != want {
+                t.Errorf("DFA of %s is exported as\n%s\nwant\n%s", pattern, dot, want)
+            }
+        }
+    }
+}
+
+func TestExportDOT(t *testing.T) {
+    dot, err := fromString(PCREFlavor, `(a)\1`).ExportDOT(NFA)
+    want := strings.Join([]string{
+        `digraph NFA {`,
+        `    rankdir=LR;`,
+        `    node [shape=circle];`,
+        `    start [shape=point];`,
+        `    start -> 0;`,
+        `    4 [shape=doublecircle];`,
+        `    0 -> 1 [label="(1", style=dashed];`,
+        `    1 -> 2 [label="a"];`,
+        `    2 -> 3 [label="1)", style=dashed];`,
+        `    3 -> 4 [label="\\1", style=dashed];`,
+        `}`,
+    }, "\n") + "\n"
+    if err != nil || dot != want {
+        t.Errorf("NFA is exported as\n%s\nwant\n%s: %v", dot, want, err)
+    }
+
+    tests := []struct {
+        r *RejexBuilder
+        pos int
+        err string
+    }{
+        {NewPCRERejex().Characters("xy").BeginCaptureGroup().Characters("a").EndGroup().CapturedPatternByNum(1).Builder(),
+            5, "'CapturedPatternByNum()' cannot be expressed in a DFA"},
+        {fromString(PCREFlavor, `(?<n>a)\k<n>`),
+            7, "'CapturedPatternByName()' cannot be expressed in a DFA"},
+        {fromString(PCREFlavor, `^a(?=b)`),
+            2, "'BeginPosLookahead()' cannot be expressed in a DFA"},
+        {fromString(PCREFlavor, `a^b`),
+            1, "'Starting()' cannot be expressed in a DFA"},
+    }
+    for _, test := range tests {
+        if _, err := test.r.ExportDOT(NFA); err != nil {
+            t.Errorf("NFA is not exported: %v", err)
+        }
+        _, err := test.r.ExportDOT(DFA)
+        var rerr *RejexError
+        if err != nil {
+            rerr, _ = err.(*RejexError)
+        }
+        if rerr == nil || rerr.Position != test.pos || rerr.Err != test.err {
+            t.Errorf("DFA is exported with %v, want '%s' at %d", err, test.err, test.pos)
+        }
+    }
+}
@@ -0,0 +1,398 @@
+package rejex
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "unicode"
+)
+
+// commonSyntax renders the constructs which are written the same way in the built-in
+// flavors, each of them overrides what differs
+type commonSyntax struct{}
+
+// escape returns the syntax of a character shared by the built-in flavors, escaping it
+// if it is a metacharacter or the delimiter of the pattern. Characters above 0xFF which
+// are not printable are written as a code point in the syntax of the flavor so they are
+// not handled here
+func escape(c rune, inClass, delimited bool) (string, bool) {
+    meta := metaChars
+    if inClass {
+        meta = classMetaChars
+    }
+    switch {
+    case strings.ContainsRune(meta, c) || c == '/' && delimited:
+        return `\` + string(c), true
+    case c == '\n':
+        return `\n`, true
+    case c == '\t':
+        return `\t`, true
+    case c == '\r':
+        return `\r`, true
+    case c == '\f':
+        return `\f`, true
+    case c == '\v':
+        return `\v`, true
+    case unicode.IsPrint(c):
+        return string(c), true
+    case c <= 0xFF:
+        return fmt.Sprintf(`\x%02X`, c), true
+    }
+    return "", false
+}
+
+func shorthand(c rune, negated bool) string {
+    if negated {
+        c = unicode.ToUpper(c)
+    }
+    return `\` + string(c)
+}
+
+func (commonSyntax) RenderClass(item ClassItem) string {
+    switch item.Kind {
+    case DigitItem:
+        return shorthand('d', item.Negated)
+    case WordItem:
+        return shorthand('w', item.Negated)
+    case WhitespaceItem:
+        return shorthand('s', item.Negated)
+    case UnicodeItem:
+        if len(item.Name) == 1 {
+            return shorthand('p', item.Negated) + item.Name
+        }
+        return fmt.Sprintf("%s{%s}", shorthand('p', item.Negated), item.Name)
+    case AnyCharItem:
+        return "."
+    case GraphemeItem:
+        return `\X`
+    case LinebreakItem:
+        return `\R`
+    }
+    return ""
+}
+
+func (commonSyntax) RenderGroup(g *Group) string {
+    switch g.Kind {
+    case CaptureGroup:
+        return "("
+    case NamedCaptureGroup:
+        return fmt.Sprintf("(?<%s>", g.Name)
+    case FlagGroup:
+        return fmt.Sprintf("(?%s:", string(g.Flags))
+    case AtomicGroup:
+        return "(?>"
+    case BranchResetGroup:
+        return "(?|"
+    case PosLookahead:
+        return "(?="
+    case NegLookahead:
+        return "(?!"
+    case PosLookbehind:
+        return "(?<="
+    case NegLookbehind:
+        return "(?<!"
+    case ConditionalGroup:
+        if g.Name != "" {
+            return fmt.Sprintf("(?(<%s>)", g.Name)
+        }
+        return fmt.Sprintf("(?(%d)", g.Num)
+    case DefineGroup:
+        return "(?(DEFINE)"
+    }
+    return "(?:"
+}
+
+func (commonSyntax) RenderAssertion(kind AssertionKind) string {
+    switch kind {
+    case AssertLineStart:
+        return "^"
+    case AssertTextStart:
+        return `\A`
+    case AssertLineEnd:
+        return "$"
+    case AssertTextEnd:
+        return `\z`
+    case AssertTextEndNewline:
+        return `\Z`
+    case AssertWordBoundary:
+        return `\b`
+    case AssertNonWordBoundary:
+        return `\B`
+    case AssertLastMatchEnd:
+        return `\G`
+    case AssertMatchStart:
+        return `\K`
+    }
+    return ""
+}
+
+func (commonSyntax) RenderBackref(ref *Backref) string {
+    switch {
+    case ref.Name != "":
+        return fmt.Sprintf(`\k<%s>`, ref.Name)
+    case ref.Num < 0:
+        return fmt.Sprintf(`\g{%d}`, ref.Num)
+    }
+    return fmt.Sprintf(`\%d`, ref.Num)
+}
+
+// delimitedFlags wraps a pattern in '/' delimiters followed by its flags
+func delimitedFlags(flags []RejexFlag) (string, string) {
+    return "/", "/" + string(flags)
+}
+
+// goFlavor is the syntax of the regexp package of the standard library
+type goFlavor struct{ commonSyntax }
+
+var goFeatures = map[Feature]bool{
+    GroupFlagsFeature: true,
+    AbsoluteAnchorFeature: true,
+    UnicodeClassFeature: true,
+    QuoteFeature: true,
+}
+
+func (goFlavor) Name() string { return "GO" }
+func (goFlavor) String() string { return "GO" }
+func (goFlavor) Base() RejexFlavor { return GoFlavor }
+func (goFlavor) Flags() map[RejexFlag]bool { return goFlavorFlags }
+func (goFlavor) Supports(f Feature) bool { return goFeatures[f] }
+func (goFlavor) MaxRepeat() int { return maxRepeat }
+
+func (goFlavor) RenderLiteral(c rune, inClass bool) string {
+    if s, ok := escape(c, inClass, false); ok {
+        return s
+    }
+    return fmt.Sprintf(`\x{%X}`, c)
+}
+
+func (f goFlavor) RenderGroup(g *Group) string {
+    if g.Kind == NamedCaptureGroup {
+        return fmt.Sprintf("(?P<%s>", g.Name)
+    }
+    return f.commonSyntax.RenderGroup(g)
+}
+
+func (goFlavor) RenderFlags(flags []RejexFlag, unicode bool) (string, string) {
+    if len(flags) == 0 {
+        return "", ""
+    }
+    return fmt.Sprintf("(?%s)", string(flags)), ""
+}
+
+// ecmaFlavor is the syntax of regex literals of ECMAScript
+type ecmaFlavor struct{ commonSyntax }
+
+var ecmaFeatures = map[Feature]bool{
+    LookaheadFeature: true,
+    LookbehindFeature: true,
+    VariableLookbehindFeature: true,
+    LookbehindAlternativesFeature: true,
+    BackrefFeature: true,
+    NamedBackrefFeature: true,
+    UnicodeClassFeature: true,
+    ControlCharFeature: true,
+}
+
+func (ecmaFlavor) Name() string { return "ECMA" }
+func (ecmaFlavor) String() string { return "ECMA" }
+func (ecmaFlavor) Base() RejexFlavor { return ECMAFlavor }
+func (ecmaFlavor) Flags() map[RejexFlag]bool { return ecmaFlavorFlags }
+func (ecmaFlavor) Supports(f Feature) bool { return ecmaFeatures[f] }
+func (ecmaFlavor) MaxRepeat() int { return 0 }
+
+func (ecmaFlavor) RenderLiteral(c rune, inClass bool) string {
+    if s, ok := escape(c, inClass, true); ok {
+        return s
+    }
+    if c <= 0xFFFF {
+        return fmt.Sprintf(`\u%04X`, c)
+    }
+    return fmt.Sprintf(`\u{%X}`, c)
+}
+
+func (f ecmaFlavor) RenderClass(item ClassItem) string {
+    if item.Kind != UnicodeItem {
+        return f.commonSyntax.RenderClass(item)
+    }
+    if _, ok := unicode.Scripts[item.Name]; ok {
+        return fmt.Sprintf("%s{Script=%s}", shorthand('p', item.Negated), item.Name)
+    }
+    return fmt.Sprintf("%s{%s}", shorthand('p', item.Negated), item.Name)
+}
+
+// RenderFlags adds the unicode flag which the code point escapes and unicode classes
+// of the pattern require
+func (ecmaFlavor) RenderFlags(flags []RejexFlag, unicode bool) (string, string) {
+    if unicode && !strings.ContainsRune(string(flags), rune(UnicodeFlag)) {
+        flags = append(append([]RejexFlag{}, flags...), UnicodeFlag)
+        sort.Slice(flags, func(i, j int) bool { return flags[i] < flags[j] })
+    }
+    return delimitedFlags(flags)
+}
+
+// perlFlavor is the syntax of Perl regexes
+type perlFlavor struct{ commonSyntax }
+
+// perlMaxRepeat is the limit of repetition counts of the usual builds of perl
+const perlMaxRepeat = 65534
+
+var perlFeatures = map[Feature]bool{
+    LookaheadFeature: true,
+    LookbehindFeature: true,
+    AtomicGroupFeature: true,
+    BranchResetFeature: true,
+    GroupFlagsFeature: true,
+    PossessiveFeature: true,
+    BackrefFeature: true,
+    NamedBackrefFeature: true,
+    RelativeBackrefFeature: true,
+    AbsoluteAnchorFeature: true,
+    NewlineEndAnchorFeature: true,
+    LastMatchEndFeature: true,
+    GraphemeFeature: true,
+    UnicodeClassFeature: true,
+    QuoteFeature: true,
+    ControlCharFeature: true,
+}
+
+func (perlFlavor) Name() string { return "PERL" }
+func (perlFlavor) String() string { return "PERL" }
+func (perlFlavor) Base() RejexFlavor { return PerlFlavor }
+func (perlFlavor) Flags() map[RejexFlag]bool { return perlFlavorFlags }
+func (perlFlavor) Supports(f Feature) bool { return perlFeatures[f] }
+func (perlFlavor) MaxRepeat() int { return perlMaxRepeat }
+
+func (perlFlavor) RenderLiteral(c rune, inClass bool) string {
+    if s, ok := escape(c, inClass, true); ok {
+        return s
+    }
+    return fmt.Sprintf(`\x{%X}`, c)
+}
+
+func (perlFlavor) RenderFlags(flags []RejexFlag, unicode bool) (string, string) {
+    return delimitedFlags(flags)
+}
+
+// pcreFlavor is the syntax of PCRE2, written without delimiters as expected by grep -P
+// and nginx
+type pcreFlavor struct{ commonSyntax }
+
+// pcreMaxRepeat is the limit of repetition counts of PCRE2
+const pcreMaxRepeat = 65535
+
+var pcreFeatures = map[Feature]bool{
+    LookaheadFeature: true,
+    LookbehindFeature: true,
+    LookbehindAlternativesFeature: true,
+    AtomicGroupFeature: true,
+    BranchResetFeature: true,
+    GroupFlagsFeature: true,
+    PossessiveFeature: true,
+    BackrefFeature: true,
+    NamedBackrefFeature: true,
+    RelativeBackrefFeature: true,
+    AbsoluteAnchorFeature: true,
+    NewlineEndAnchorFeature: true,
+    LastMatchEndFeature: true,
+    GraphemeFeature: true,
+    UnicodeClassFeature: true,
+    QuoteFeature: true,
+    ControlCharFeature: true,
+    RecursionFeature: true,
+    ConditionalFeature: true,
+    MatchStartFeature: true,
+    VerbFeature: true,
+    StartOptionFeature: true,
+    LinebreakFeature: true,
+}
+
+func (pcreFlavor) Name() string { return "PCRE" }
+func (pcreFlavor) String() string { return "PCRE" }
+func (pcreFlavor) Base() RejexFlavor { return PCREFlavor }
+func (pcreFlavor) Flags() map[RejexFlag]bool { return pcreFlavorFlags }
+func (pcreFlavor) Supports(f Feature) bool { return pcreFeatures[f] }
+func (pcreFlavor) MaxRepeat() int { return pcreMaxRepeat }
+
+func (pcreFlavor) RenderLiteral(c rune, inClass bool) string {
+    if s, ok := escape(c, inClass, false); ok {
+        return s
+    }
+    return fmt.Sprintf(`\x{%X}`, c)
+}
+
+func (pcreFlavor) RenderFlags(flags []RejexFlag, unicode bool) (string, string) {
+    if len(flags) == 0 {
+        return "", ""
+    }
+    return fmt.Sprintf("(?%s)", string(flags)), ""
+}
+
+// pythonFlavor is the syntax of the re module of Python 3.11 and later, written without
+// delimiters and with the flags as a leading inline group
+type pythonFlavor struct{ commonSyntax }
+
+var pythonFeatures = map[Feature]bool{
+    LookaheadFeature: true,
+    LookbehindFeature: true,
+    AtomicGroupFeature: true,
+    GroupFlagsFeature: true,
+    PossessiveFeature: true,
+    BackrefFeature: true,
+    NamedBackrefFeature: true,
+    AbsoluteAnchorFeature: true,
+    ConditionalFeature: true,
+}
+
+func (pythonFlavor) Name() string { return "PYTHON" }
+func (pythonFlavor) String() string { return "PYTHON" }
+func (pythonFlavor) Base() RejexFlavor { return PythonFlavor }
+func (pythonFlavor) Flags() map[RejexFlag]bool { return pythonFlavorFlags }
+func (pythonFlavor) Supports(f Feature) bool { return pythonFeatures[f] }
+func (pythonFlavor) MaxRepeat() int { return 0 }
+
+func (pythonFlavor) RenderLiteral(c rune, inClass bool) string {
+    if s, ok := escape(c, inClass, false); ok {
+        return s
+    }
+    if c <= 0xFFFF {
+        return fmt.Sprintf(`\u%04X`, c)
+    }
+    return fmt.Sprintf(`\U%08X`, c)
+}
+
+func (f pythonFlavor) RenderGroup(g *Group) string {
+    switch {
+    case g.Kind == NamedCaptureGroup:
+        return fmt.Sprintf("(?P<%s>", g.Name)
+    case g.Kind == ConditionalGroup && g.Name != "":
+        return fmt.Sprintf("(?(%s)", g.Name)
+    }
+    return f.commonSyntax.RenderGroup(g)
+}
+
+func (f pythonFlavor) RenderAssertion(kind AssertionKind) string {
+    if kind == AssertTextEnd {
+        return `\Z`
+    }
+    return f.commonSyntax.RenderAssertion(kind)
+}
+
+func (f pythonFlavor) RenderBackref(ref *Backref) string {
+    if ref.Name != "" {
+        return fmt.Sprintf("(?P=%s)", ref.Name)
+    }
+    return f.commonSyntax.RenderBackref(ref)
+}
+
+func (pythonFlavor) RenderFlags(flags []RejexFlag, unicode bool) (string, string) {
+    if len(flags) == 0 {
+        return "", ""
+    }
+    return fmt.Sprintf("(?%s)", string(flags)), ""
+}
+
+// bytesPattern reports whether the pattern has to be compiled as bytes, which the locale
+// flag requires
+func (pythonFlavor) bytesPattern(flags map[RejexFlag]bool) bool {
+    return flags[LocaleFlag]
+}
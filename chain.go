@@ -0,0 +1,674 @@
+package rejex
+
+import (
+    "io"
+    "regexp"
+)
+
+// chain holds the methods shared by the builders of every flavor. Each chain method
+// adds its segment to the underlying RejexBuilder and returns self, the builder of the
+// flavor, so the methods available later in the chain are still those of the flavor
+// and using a construct it does not support is a compile error
+type chain[B any] struct {
+    r *RejexBuilder
+    self B
+}
+
+// GoRejex is the builder of regexes of the Go standard syntax
+type GoRejex struct {
+    chain[*GoRejex]
+}
+
+// ECMARejex is the builder of regexes of the ECMAScript standard syntax
+type ECMARejex struct {
+    chain[*ECMARejex]
+}
+
+// PerlRejex is the builder of regexes of the Perl standard syntax
+type PerlRejex struct {
+    chain[*PerlRejex]
+}
+
+// PCRERejex is the builder of regexes of the PCRE2 syntax
+type PCRERejex struct {
+    chain[*PCRERejex]
+}
+
+// PythonRejex is the builder of regexes of the syntax of the re module of Python
+type PythonRejex struct {
+    chain[*PythonRejex]
+}
+
+var (
+    _ GoFlavorInterface = (*GoRejex)(nil)
+    _ ECMAFlavorInterface = (*ECMARejex)(nil)
+    _ PerlFlavorInterface = (*PerlRejex)(nil)
+    _ PCREFlavorInterface = (*PCRERejex)(nil)
+    _ PythonFlavorInterface = (*PythonRejex)(nil)
+)
+
+func newGoRejex(r *RejexBuilder) *GoRejex {
+    b := &GoRejex{}
+    b.chain = chain[*GoRejex]{r, b}
+    return b
+}
+
+func newECMARejex(r *RejexBuilder) *ECMARejex {
+    b := &ECMARejex{}
+    b.chain = chain[*ECMARejex]{r, b}
+    return b
+}
+
+func newPerlRejex(r *RejexBuilder) *PerlRejex {
+    b := &PerlRejex{}
+    b.chain = chain[*PerlRejex]{r, b}
+    return b
+}
+
+func newPCRERejex(r *RejexBuilder) *PCRERejex {
+    b := &PCRERejex{}
+    b.chain = chain[*PCRERejex]{r, b}
+    return b
+}
+
+func newPythonRejex(r *RejexBuilder) *PythonRejex {
+    b := &PythonRejex{}
+    b.chain = chain[*PythonRejex]{r, b}
+    return b
+}
+
+// Builder returns the RejexBuilder the chain adds its segments to, whose methods are
+// not restricted to the flavor
+func (c chain[B]) Builder() *RejexBuilder { return c.r }
+
+// Errors returns the errors reported so far
+func (c chain[B]) Errors() []RejexError { return c.r.Errors }
+
+// Build constructs the final regex string and returns it along with a list of errors
+func (c chain[B]) Build() (string, []RejexError) { return c.r.Build() }
+
+// BuildFor constructs the final regex string in the syntax of the provided flavor
+func (c chain[B]) BuildFor(flavor RejexFlavor) (string, []RejexError) { return c.r.BuildFor(flavor) }
+
+// Tree returns the syntax tree of the pattern constructed so far
+func (c chain[B]) Tree() Node { return c.r.Tree() }
+
+// Explain returns an indented description of every segment of the regex
+func (c chain[B]) Explain() string { return c.r.Explain() }
+
+// Examples returns n random strings which are matched in their entirety by the regex
+func (c chain[B]) Examples(n int, seed int64) []string { return c.r.Examples(n, seed) }
+
+// CounterExamples returns up to n strings which the regex does not match anywhere
+func (c chain[B]) CounterExamples(n int) []CounterExample { return c.r.CounterExamples(n) }
+
+// Matches reports whether the regex matches anywhere in the input, following the
+// semantics of the flavor
+func (c chain[B]) Matches(s string) (bool, error) { return c.r.Matches(s) }
+
+// RenderRailroad writes a standalone SVG railroad diagram of the regex
+func (c chain[B]) RenderRailroad(w io.Writer) error { return c.r.RenderRailroad(w) }
+
+// ExportDOT returns the Graphviz DOT source of an automaton which matches the regex
+func (c chain[B]) ExportDOT(kind AutomatonKind) (string, error) { return c.r.ExportDOT(kind) }
+
+// Spec returns the spec describing the pattern constructed so far
+func (c chain[B]) Spec() (*Spec, error) { return c.r.Spec() }
+
+// Marshal returns the spec describing the pattern as indented JSON
+func (c chain[B]) Marshal() ([]byte, error) { return c.r.Marshal() }
+
+// MarshalJSON encodes the builder as the spec describing its pattern
+func (c chain[B]) MarshalJSON() ([]byte, error) { return c.r.MarshalJSON() }
+
+// MarshalYAML encodes the builder as the spec describing its pattern
+func (c chain[B]) MarshalYAML() (interface{}, error) { return c.r.MarshalYAML() }
+
+// Optimize makes Build() and BuildFor() render an equivalent but shorter pattern
+func (c chain[B]) Optimize() B { c.r.Optimize(); return c.self }
+
+// General
+
+// Not queues the following segment to be negated, converting '\d' to '\D' for instance
+func (c chain[B]) Not() B { c.r.Not(); return c.self }
+
+// Characters matches the exact input provided to it
+func (c chain[B]) Characters(s string) B { c.r.Characters(s); return c.self }
+
+// EscapedCharacters matches the input provided after escaping the regex special
+// characters from it
+func (c chain[B]) EscapedCharacters(s string) B { c.r.EscapedCharacters(s); return c.self }
+
+// AnyChar matches any single character
+func (c chain[B]) AnyChar() B { c.r.AnyChar(); return c.self }
+
+// Pattern matches the pattern constructed by another builder as a single segment
+func (c chain[B]) Pattern(other Fragment) B { c.r.Pattern(other); return c.self }
+
+// NumberRange matches any integer between min and max, both included
+func (c chain[B]) NumberRange(min, max int64, opts ...NumberRangeOptions) B {
+    c.r.NumberRange(min, max, opts...)
+    return c.self
+}
+
+// AnyOfWords matches any of the provided words, escaped for the flavor
+func (c chain[B]) AnyOfWords(words []string, opts ...WordsOptions) B {
+    c.r.AnyOfWords(words, opts...)
+    return c.self
+}
+
+// Anchors
+
+// Starting matches the beginning of a string, or of a line when the multiline flag is
+// set
+func (c chain[B]) Starting() B { c.r.Starting(); return c.self }
+
+// Ending matches the end of a string, or of a line when the multiline flag is set
+func (c chain[B]) Ending() B { c.r.Ending(); return c.self }
+
+// WordBoundary matches between a word character and a non word character
+func (c chain[B]) WordBoundary() B { c.r.WordBoundary(); return c.self }
+
+// Quantifiers
+
+// ZeroOrOneOf matches 0 or 1 occurance of the provided input, or of the preceding
+// segment if it is empty
+func (c chain[B]) ZeroOrOneOf(s string) B { c.r.ZeroOrOneOf(s); return c.self }
+
+// ZeroOrMoreOf matches any number of occurances of the provided input, or of the
+// preceding segment if it is empty
+func (c chain[B]) ZeroOrMoreOf(s string) B { c.r.ZeroOrMoreOf(s); return c.self }
+
+// OneOrMoreOf matches 1 or more occurances of the provided input, or of the preceding
+// segment if it is empty
+func (c chain[B]) OneOrMoreOf(s string) B { c.r.OneOrMoreOf(s); return c.self }
+
+// NOf matches exactly n occurances of the provided input, or of the preceding segment if
+// it is empty
+func (c chain[B]) NOf(s string, n int) B { c.r.NOf(s, n); return c.self }
+
+// NOrMoreOf matches n or more occurances of the provided input, or of the preceding
+// segment if it is empty
+func (c chain[B]) NOrMoreOf(s string, n int) B { c.r.NOrMoreOf(s, n); return c.self }
+
+// NToMOf matches n to m occurances of the provided input, or of the preceding segment if
+// it is empty
+func (c chain[B]) NToMOf(s string, n, m int) B { c.r.NToMOf(s, n, m); return c.self }
+
+// Meta
+
+// PreferFewer makes the preceding quantifier match as few characters as it can
+func (c chain[B]) PreferFewer() B { c.r.PreferFewer(); return c.self }
+
+// Or represents an alternative between whatever precedes it and whatever follows it
+func (c chain[B]) Or() B { c.r.Or(); return c.self }
+
+// EitherOr matches any of the provided input strings
+func (c chain[B]) EitherOr(s ...string) B { c.r.EitherOr(s...); return c.self }
+
+// Group Constructs
+
+// BeginCaptureGroup represents the start of a new capture group with a group number
+func (c chain[B]) BeginCaptureGroup() B { c.r.BeginCaptureGroup(); return c.self }
+
+// BeginNamedCaptureGroup represents the start of a new capture group with a group name
+func (c chain[B]) BeginNamedCaptureGroup(name string) B { c.r.BeginNamedCaptureGroup(name); return c.self }
+
+// BeginNonCaptureGroup represents the start of a new group with no group number or name
+func (c chain[B]) BeginNonCaptureGroup() B { c.r.BeginNonCaptureGroup(); return c.self }
+
+// EndGroup represents the end of the last opened group
+func (c chain[B]) EndGroup() B { c.r.EndGroup(); return c.self }
+
+// BeginSelectionSet represents the start of a set of characters out of which only one
+// needs be matched
+func (c chain[B]) BeginSelectionSet() B { c.r.BeginSelectionSet(); return c.self }
+
+// BeginNonSelectionSet represents the start of a set of characters out of which none
+// should be matched
+func (c chain[B]) BeginNonSelectionSet() B { c.r.BeginNonSelectionSet(); return c.self }
+
+// EndSelectionSet represents the end of the last opened selection set
+func (c chain[B]) EndSelectionSet() B { c.r.EndSelectionSet(); return c.self }
+
+// Char Classes
+
+// AnyFrom matches any single character from the provided input
+func (c chain[B]) AnyFrom(s string) B { c.r.AnyFrom(s); return c.self }
+
+// AnyFromCharRange matches any single character in the range between the 2 characters
+// provided
+func (c chain[B]) AnyFromCharRange(from, to string) B { c.r.AnyFromCharRange(from, to); return c.self }
+
+// AnyWhitespace matches any single whitespace character
+func (c chain[B]) AnyWhitespace() B { c.r.AnyWhitespace(); return c.self }
+
+// AnyWordChar matches any single word character
+func (c chain[B]) AnyWordChar() B { c.r.AnyWordChar(); return c.self }
+
+// AnyDigit matches any single decimal digit
+func (c chain[B]) AnyDigit() B { c.r.AnyDigit(); return c.self }
+
+// AnyLetter matches any single english letter
+func (c chain[B]) AnyLetter() B { c.r.AnyLetter(); return c.self }
+
+// AnyUppercase matches any single uppercase english letter
+func (c chain[B]) AnyUppercase() B { c.r.AnyUppercase(); return c.self }
+
+// AnyLowercase matches any single lowercase english letter
+func (c chain[B]) AnyLowercase() B { c.r.AnyLowercase(); return c.self }
+
+// AnyAlNumChar matches any single english letter or digit
+func (c chain[B]) AnyAlNumChar() B { c.r.AnyAlNumChar(); return c.self }
+
+// AnyPunctuation matches any single Punctuation character
+func (c chain[B]) AnyPunctuation() B { c.r.AnyPunctuation(); return c.self }
+
+// AnyGraphicChar matches any visible character
+func (c chain[B]) AnyGraphicChar() B { c.r.AnyGraphicChar(); return c.self }
+
+// AnyASCIIChar matches any single ASCII character
+func (c chain[B]) AnyASCIIChar() B { c.r.AnyASCIIChar(); return c.self }
+
+// AnyControlChar matches any single control character
+func (c chain[B]) AnyControlChar() B { c.r.AnyControlChar(); return c.self }
+
+// OctalChar matches the character represented by the provided octal character code
+func (c chain[B]) OctalChar(n int) B { c.r.OctalChar(n); return c.self }
+
+// HexChar matches the character represented by the provided hex character code
+func (c chain[B]) HexChar(s string) B { c.r.HexChar(s); return c.self }
+
+// Flags
+
+// AddFlags adds the provided flags to the regex
+func (c chain[B]) AddFlags(f ...RejexFlag) B { c.r.AddFlags(f...); return c.self }
+
+// RemoveFlags removes the provided flags from the regex
+func (c chain[B]) RemoveFlags(f ...RejexFlag) B { c.r.RemoveFlags(f...); return c.self }
+
+// Utils
+
+// LineEnding matches any single character that starts a new line
+func (c chain[B]) LineEnding() B { c.r.LineEnding(); return c.self }
+
+// The methods below are only available in some of the flavors
+
+// Compile builds the regex and compiles it with the regexp package
+func (g *GoRejex) Compile() (*regexp.Regexp, error) { return g.r.Compile() }
+
+// MustCompile is like Compile but panics if the regex cannot be built or compiled
+func (g *GoRejex) MustCompile() *regexp.Regexp { return g.r.MustCompile() }
+
+// Literally matches the provided input enclosed in an escape sequence (\Q...\E)
+func (g *GoRejex) Literally(s string) *GoRejex { g.r.Literally(s); return g }
+
+// AbsoluteStarting matches the very beginning of a string, regardless of the multiline
+// flag
+func (g *GoRejex) AbsoluteStarting() *GoRejex { g.r.AbsoluteStarting(); return g }
+
+// AbsoluteEnding matches the very end of a string, regardless of the multiline flag
+func (g *GoRejex) AbsoluteEnding() *GoRejex { g.r.AbsoluteEnding(); return g }
+
+// BeginGroupWithFlags represents the start of a new group which use the provided flags
+func (g *GoRejex) BeginGroupWithFlags(f []RejexFlag) *GoRejex { g.r.BeginGroupWithFlags(f); return g }
+
+// AnyUnicodeLetter matches any single unicode letter
+func (g *GoRejex) AnyUnicodeLetter() *GoRejex { g.r.AnyUnicodeLetter(); return g }
+
+// AnyUnicodeUppercase matches any single uppercase unicode character
+func (g *GoRejex) AnyUnicodeUppercase() *GoRejex { g.r.AnyUnicodeUppercase(); return g }
+
+// AnyUnicodeLowercase matches any single lowercase unicode character
+func (g *GoRejex) AnyUnicodeLowercase() *GoRejex { g.r.AnyUnicodeLowercase(); return g }
+
+// AnyUnicodeWhitespace matches any single unicode whitespace
+func (g *GoRejex) AnyUnicodeWhitespace() *GoRejex { g.r.AnyUnicodeWhitespace(); return g }
+
+// AnyUnicodeSymbol matches any single unicode symbol character
+func (g *GoRejex) AnyUnicodeSymbol() *GoRejex { g.r.AnyUnicodeSymbol(); return g }
+
+// AnyUnicodeNumber matches any single unicode number
+func (g *GoRejex) AnyUnicodeNumber() *GoRejex { g.r.AnyUnicodeNumber(); return g }
+
+// AnyUnicodePunctuation matches any single unicode punctuation character
+func (g *GoRejex) AnyUnicodePunctuation() *GoRejex { g.r.AnyUnicodePunctuation(); return g }
+
+// UnicodeClass matches any character from the provided unicode class
+func (g *GoRejex) UnicodeClass(s string) *GoRejex { g.r.UnicodeClass(s); return g }
+
+// AnalyzeReDoS looks for segments of the regex which make backtracking engines take
+// polynomial or exponential time
+func (e *ECMARejex) AnalyzeReDoS() ReDoSReport { return e.r.AnalyzeReDoS() }
+
+// WarnReDoS makes Build() and BuildFor() report the findings of AnalyzeReDoS as warnings
+func (e *ECMARejex) WarnReDoS() *ECMARejex { e.r.WarnReDoS(); return e }
+
+// CapturedPatternByNum matches the text previously captured by the group with the
+// provided group number
+func (e *ECMARejex) CapturedPatternByNum(n int) *ECMARejex { e.r.CapturedPatternByNum(n); return e }
+
+// CapturedPatternByName matches the text previously captured by the group with the
+// provided group name
+func (e *ECMARejex) CapturedPatternByName(s string) *ECMARejex { e.r.CapturedPatternByName(s); return e }
+
+// BeginPosLookahead represents the start of a new group which has to follow the
+// preceding segment without being matched
+func (e *ECMARejex) BeginPosLookahead() *ECMARejex { e.r.BeginPosLookahead(); return e }
+
+// BeginNegLookahead represents the start of a new group which must not follow the
+// preceding segment
+func (e *ECMARejex) BeginNegLookahead() *ECMARejex { e.r.BeginNegLookahead(); return e }
+
+// BeginPosLookbehind represents the start of a new group which has to precede the
+// following segment without being matched
+func (e *ECMARejex) BeginPosLookbehind() *ECMARejex { e.r.BeginPosLookbehind(); return e }
+
+// BeginNegLookbehind represents the start of a new group which must not precede the
+// following segment
+func (e *ECMARejex) BeginNegLookbehind() *ECMARejex { e.r.BeginNegLookbehind(); return e }
+
+// ControlChar matches the control character represented by the provided control
+// character code
+func (e *ECMARejex) ControlChar(s string) *ECMARejex { e.r.ControlChar(s); return e }
+
+// AnyUnicodeLetter matches any single unicode letter
+func (e *ECMARejex) AnyUnicodeLetter() *ECMARejex { e.r.AnyUnicodeLetter(); return e }
+
+// AnyUnicodeUppercase matches any single uppercase unicode character
+func (e *ECMARejex) AnyUnicodeUppercase() *ECMARejex { e.r.AnyUnicodeUppercase(); return e }
+
+// AnyUnicodeLowercase matches any single lowercase unicode character
+func (e *ECMARejex) AnyUnicodeLowercase() *ECMARejex { e.r.AnyUnicodeLowercase(); return e }
+
+// AnyUnicodeWhitespace matches any single unicode whitespace
+func (e *ECMARejex) AnyUnicodeWhitespace() *ECMARejex { e.r.AnyUnicodeWhitespace(); return e }
+
+// AnyUnicodeSymbol matches any single unicode symbol character
+func (e *ECMARejex) AnyUnicodeSymbol() *ECMARejex { e.r.AnyUnicodeSymbol(); return e }
+
+// AnyUnicodeNumber matches any single unicode number
+func (e *ECMARejex) AnyUnicodeNumber() *ECMARejex { e.r.AnyUnicodeNumber(); return e }
+
+// AnyUnicodePunctuation matches any single unicode punctuation character
+func (e *ECMARejex) AnyUnicodePunctuation() *ECMARejex { e.r.AnyUnicodePunctuation(); return e }
+
+// UnicodeClass matches any character from the provided unicode class
+func (e *ECMARejex) UnicodeClass(s string) *ECMARejex { e.r.UnicodeClass(s); return e }
+
+// AnalyzeReDoS looks for segments of the regex which make backtracking engines take
+// polynomial or exponential time
+func (p *PerlRejex) AnalyzeReDoS() ReDoSReport { return p.r.AnalyzeReDoS() }
+
+// WarnReDoS makes Build() and BuildFor() report the findings of AnalyzeReDoS as warnings
+func (p *PerlRejex) WarnReDoS() *PerlRejex { p.r.WarnReDoS(); return p }
+
+// AbsoluteStarting matches the very beginning of a string, regardless of the multiline
+// flag
+func (p *PerlRejex) AbsoluteStarting() *PerlRejex { p.r.AbsoluteStarting(); return p }
+
+// AbsoluteEnding matches the very end of a string, regardless of the multiline flag
+func (p *PerlRejex) AbsoluteEnding() *PerlRejex { p.r.AbsoluteEnding(); return p }
+
+// EndOfLastMatch matches at the end of the previous match (\G)
+func (p *PerlRejex) EndOfLastMatch() *PerlRejex { p.r.EndOfLastMatch(); return p }
+
+// PossessiveQuantifier makes the preceding quantifier match as many items as it can
+// without ever backtracking
+func (p *PerlRejex) PossessiveQuantifier() *PerlRejex { p.r.PossessiveQuantifier(); return p }
+
+// CapturedPatternByNum matches the text previously captured by the group with the
+// provided group number
+func (p *PerlRejex) CapturedPatternByNum(n int) *PerlRejex { p.r.CapturedPatternByNum(n); return p }
+
+// CapturedPatternByName matches the text previously captured by the group with the
+// provided group name
+func (p *PerlRejex) CapturedPatternByName(s string) *PerlRejex { p.r.CapturedPatternByName(s); return p }
+
+// BeginGroupWithFlags represents the start of a new group which use the provided flags
+func (p *PerlRejex) BeginGroupWithFlags(f []RejexFlag) *PerlRejex { p.r.BeginGroupWithFlags(f); return p }
+
+// BeginPosLookahead represents the start of a new group which has to follow the
+// preceding segment without being matched
+func (p *PerlRejex) BeginPosLookahead() *PerlRejex { p.r.BeginPosLookahead(); return p }
+
+// BeginNegLookahead represents the start of a new group which must not follow the
+// preceding segment
+func (p *PerlRejex) BeginNegLookahead() *PerlRejex { p.r.BeginNegLookahead(); return p }
+
+// BeginPosLookbehind represents the start of a new group which has to precede the
+// following segment without being matched
+func (p *PerlRejex) BeginPosLookbehind() *PerlRejex { p.r.BeginPosLookbehind(); return p }
+
+// BeginNegLookbehind represents the start of a new group which must not precede the
+// following segment
+func (p *PerlRejex) BeginNegLookbehind() *PerlRejex { p.r.BeginNegLookbehind(); return p }
+
+// BeginAtomicGroup represents the start of a new group which is never backtracked into
+// once it has matched
+func (p *PerlRejex) BeginAtomicGroup() *PerlRejex { p.r.BeginAtomicGroup(); return p }
+
+// BeginBranchResetGroup represents the start of a new group whose alternatives number
+// their capture groups from the same point
+func (p *PerlRejex) BeginBranchResetGroup() *PerlRejex { p.r.BeginBranchResetGroup(); return p }
+
+// AnyUnicodeGrapheme matches a single Unicode grapheme, including combining marks (\X)
+func (p *PerlRejex) AnyUnicodeGrapheme() *PerlRejex { p.r.AnyUnicodeGrapheme(); return p }
+
+// Literally matches the provided input enclosed in an escape sequence (\Q...\E)
+func (p *PerlRejex) Literally(s string) *PerlRejex { p.r.Literally(s); return p }
+
+// ControlChar matches the control character represented by the provided control
+// character code
+func (p *PerlRejex) ControlChar(s string) *PerlRejex { p.r.ControlChar(s); return p }
+
+// AnyUnicodeLetter matches any single unicode letter
+func (p *PerlRejex) AnyUnicodeLetter() *PerlRejex { p.r.AnyUnicodeLetter(); return p }
+
+// AnyUnicodeUppercase matches any single uppercase unicode character
+func (p *PerlRejex) AnyUnicodeUppercase() *PerlRejex { p.r.AnyUnicodeUppercase(); return p }
+
+// AnyUnicodeLowercase matches any single lowercase unicode character
+func (p *PerlRejex) AnyUnicodeLowercase() *PerlRejex { p.r.AnyUnicodeLowercase(); return p }
+
+// AnyUnicodeWhitespace matches any single unicode whitespace
+func (p *PerlRejex) AnyUnicodeWhitespace() *PerlRejex { p.r.AnyUnicodeWhitespace(); return p }
+
+// AnyUnicodeSymbol matches any single unicode symbol character
+func (p *PerlRejex) AnyUnicodeSymbol() *PerlRejex { p.r.AnyUnicodeSymbol(); return p }
+
+// AnyUnicodeNumber matches any single unicode number
+func (p *PerlRejex) AnyUnicodeNumber() *PerlRejex { p.r.AnyUnicodeNumber(); return p }
+
+// AnyUnicodePunctuation matches any single unicode punctuation character
+func (p *PerlRejex) AnyUnicodePunctuation() *PerlRejex { p.r.AnyUnicodePunctuation(); return p }
+
+// UnicodeClass matches any character from the provided unicode class
+func (p *PerlRejex) UnicodeClass(s string) *PerlRejex { p.r.UnicodeClass(s); return p }
+
+// AnalyzeReDoS looks for segments of the regex which make backtracking engines take
+// polynomial or exponential time
+func (p *PCRERejex) AnalyzeReDoS() ReDoSReport { return p.r.AnalyzeReDoS() }
+
+// WarnReDoS makes Build() and BuildFor() report the findings of AnalyzeReDoS as warnings
+func (p *PCRERejex) WarnReDoS() *PCRERejex { p.r.WarnReDoS(); return p }
+
+// AbsoluteStarting matches the very beginning of a string, regardless of the multiline
+// flag
+func (p *PCRERejex) AbsoluteStarting() *PCRERejex { p.r.AbsoluteStarting(); return p }
+
+// AbsoluteEnding matches the very end of a string, regardless of the multiline flag
+func (p *PCRERejex) AbsoluteEnding() *PCRERejex { p.r.AbsoluteEnding(); return p }
+
+// EndOfLastMatch matches at the end of the previous match (\G)
+func (p *PCRERejex) EndOfLastMatch() *PCRERejex { p.r.EndOfLastMatch(); return p }
+
+// ResetMatchStart makes the reported match start at this position (\K)
+func (p *PCRERejex) ResetMatchStart() *PCRERejex { p.r.ResetMatchStart(); return p }
+
+// PossessiveQuantifier makes the preceding quantifier match as many items as it can
+// without ever backtracking
+func (p *PCRERejex) PossessiveQuantifier() *PCRERejex { p.r.PossessiveQuantifier(); return p }
+
+// CapturedPatternByNum matches the text previously captured by the group with the
+// provided group number
+func (p *PCRERejex) CapturedPatternByNum(n int) *PCRERejex { p.r.CapturedPatternByNum(n); return p }
+
+// CapturedPatternByName matches the text previously captured by the group with the
+// provided group name
+func (p *PCRERejex) CapturedPatternByName(s string) *PCRERejex { p.r.CapturedPatternByName(s); return p }
+
+// Recurse matches the whole regex again at this position
+func (p *PCRERejex) Recurse() *PCRERejex { p.r.Recurse(); return p }
+
+// SubroutineByNum matches the pattern of the group with the provided group number again
+func (p *PCRERejex) SubroutineByNum(n int) *PCRERejex { p.r.SubroutineByNum(n); return p }
+
+// SubroutineByName matches the pattern of the group with the provided group name again
+func (p *PCRERejex) SubroutineByName(s string) *PCRERejex { p.r.SubroutineByName(s); return p }
+
+// BacktrackingVerb controls what happens when the regex engine backtracks over it, such
+// as "SKIP" or "FAIL"
+func (p *PCRERejex) BacktrackingVerb(s string) *PCRERejex { p.r.BacktrackingVerb(s); return p }
+
+// BeginGroupWithFlags represents the start of a new group which use the provided flags
+func (p *PCRERejex) BeginGroupWithFlags(f []RejexFlag) *PCRERejex { p.r.BeginGroupWithFlags(f); return p }
+
+// BeginPosLookahead represents the start of a new group which has to follow the
+// preceding segment without being matched
+func (p *PCRERejex) BeginPosLookahead() *PCRERejex { p.r.BeginPosLookahead(); return p }
+
+// BeginNegLookahead represents the start of a new group which must not follow the
+// preceding segment
+func (p *PCRERejex) BeginNegLookahead() *PCRERejex { p.r.BeginNegLookahead(); return p }
+
+// BeginPosLookbehind represents the start of a new group which has to precede the
+// following segment without being matched
+func (p *PCRERejex) BeginPosLookbehind() *PCRERejex { p.r.BeginPosLookbehind(); return p }
+
+// BeginNegLookbehind represents the start of a new group which must not precede the
+// following segment
+func (p *PCRERejex) BeginNegLookbehind() *PCRERejex { p.r.BeginNegLookbehind(); return p }
+
+// BeginAtomicGroup represents the start of a new group which is never backtracked into
+// once it has matched
+func (p *PCRERejex) BeginAtomicGroup() *PCRERejex { p.r.BeginAtomicGroup(); return p }
+
+// BeginBranchResetGroup represents the start of a new group whose alternatives number
+// their capture groups from the same point
+func (p *PCRERejex) BeginBranchResetGroup() *PCRERejex { p.r.BeginBranchResetGroup(); return p }
+
+// BeginConditionalGroupByNum represents the start of a new group which matches its first
+// alternative only if the group with the provided group number has captured text
+func (p *PCRERejex) BeginConditionalGroupByNum(n int) *PCRERejex { p.r.BeginConditionalGroupByNum(n); return p }
+
+// BeginConditionalGroupByName represents the start of a new group which matches its
+// first alternative only if the group with the provided group name has captured text
+func (p *PCRERejex) BeginConditionalGroupByName(s string) *PCRERejex { p.r.BeginConditionalGroupByName(s); return p }
+
+// BeginDefineGroup represents the start of a new group which is never matched, holding
+// groups used as subroutines
+func (p *PCRERejex) BeginDefineGroup() *PCRERejex { p.r.BeginDefineGroup(); return p }
+
+// AnyLinebreak matches a single line break, including a carriage return followed by a
+// newline (\R)
+func (p *PCRERejex) AnyLinebreak() *PCRERejex { p.r.AnyLinebreak(); return p }
+
+// AnyUnicodeGrapheme matches a single Unicode grapheme, including combining marks (\X)
+func (p *PCRERejex) AnyUnicodeGrapheme() *PCRERejex { p.r.AnyUnicodeGrapheme(); return p }
+
+// AnyUnicodeLetter matches any single unicode letter
+func (p *PCRERejex) AnyUnicodeLetter() *PCRERejex { p.r.AnyUnicodeLetter(); return p }
+
+// AnyUnicodeUppercase matches any single uppercase unicode character
+func (p *PCRERejex) AnyUnicodeUppercase() *PCRERejex { p.r.AnyUnicodeUppercase(); return p }
+
+// AnyUnicodeLowercase matches any single lowercase unicode character
+func (p *PCRERejex) AnyUnicodeLowercase() *PCRERejex { p.r.AnyUnicodeLowercase(); return p }
+
+// AnyUnicodeWhitespace matches any single unicode whitespace
+func (p *PCRERejex) AnyUnicodeWhitespace() *PCRERejex { p.r.AnyUnicodeWhitespace(); return p }
+
+// AnyUnicodeSymbol matches any single unicode symbol character
+func (p *PCRERejex) AnyUnicodeSymbol() *PCRERejex { p.r.AnyUnicodeSymbol(); return p }
+
+// AnyUnicodeNumber matches any single unicode number
+func (p *PCRERejex) AnyUnicodeNumber() *PCRERejex { p.r.AnyUnicodeNumber(); return p }
+
+// AnyUnicodePunctuation matches any single unicode punctuation character
+func (p *PCRERejex) AnyUnicodePunctuation() *PCRERejex { p.r.AnyUnicodePunctuation(); return p }
+
+// UnicodeClass matches any character from the provided unicode class
+func (p *PCRERejex) UnicodeClass(s string) *PCRERejex { p.r.UnicodeClass(s); return p }
+
+// Literally matches the provided input enclosed in an escape sequence (\Q...\E)
+func (p *PCRERejex) Literally(s string) *PCRERejex { p.r.Literally(s); return p }
+
+// ControlChar matches the control character represented by the provided control
+// character code
+func (p *PCRERejex) ControlChar(s string) *PCRERejex { p.r.ControlChar(s); return p }
+
+// AddStartOptions adds the provided start options to the regex, such as "UTF" written as
+// `(*UTF)`
+func (p *PCRERejex) AddStartOptions(opts ...string) *PCRERejex { p.r.AddStartOptions(opts...); return p }
+
+// BuildCompile constructs the final regex as a Python expression compiling it, such as
+// `re.compile(r"a+", re.I)`
+func (p *PythonRejex) BuildCompile() (string, []RejexError) { return p.r.BuildCompile() }
+
+// AnalyzeReDoS looks for segments of the regex which make backtracking engines take
+// polynomial or exponential time
+func (p *PythonRejex) AnalyzeReDoS() ReDoSReport { return p.r.AnalyzeReDoS() }
+
+// WarnReDoS makes Build() and BuildFor() report the findings of AnalyzeReDoS as warnings
+func (p *PythonRejex) WarnReDoS() *PythonRejex { p.r.WarnReDoS(); return p }
+
+// AbsoluteStarting matches the very beginning of a string, regardless of the multiline
+// flag
+func (p *PythonRejex) AbsoluteStarting() *PythonRejex { p.r.AbsoluteStarting(); return p }
+
+// AbsoluteEnding matches the very end of a string, regardless of the multiline flag
+func (p *PythonRejex) AbsoluteEnding() *PythonRejex { p.r.AbsoluteEnding(); return p }
+
+// PossessiveQuantifier makes the preceding quantifier match as many items as it can
+// without ever backtracking
+func (p *PythonRejex) PossessiveQuantifier() *PythonRejex { p.r.PossessiveQuantifier(); return p }
+
+// CapturedPatternByNum matches the text previously captured by the group with the
+// provided group number
+func (p *PythonRejex) CapturedPatternByNum(n int) *PythonRejex { p.r.CapturedPatternByNum(n); return p }
+
+// CapturedPatternByName matches the text previously captured by the group with the
+// provided group name
+func (p *PythonRejex) CapturedPatternByName(s string) *PythonRejex { p.r.CapturedPatternByName(s); return p }
+
+// BeginGroupWithFlags represents the start of a new group which use the provided flags
+func (p *PythonRejex) BeginGroupWithFlags(f []RejexFlag) *PythonRejex { p.r.BeginGroupWithFlags(f); return p }
+
+// BeginPosLookahead represents the start of a new group which has to follow the
+// preceding segment without being matched
+func (p *PythonRejex) BeginPosLookahead() *PythonRejex { p.r.BeginPosLookahead(); return p }
+
+// BeginNegLookahead represents the start of a new group which must not follow the
+// preceding segment
+func (p *PythonRejex) BeginNegLookahead() *PythonRejex { p.r.BeginNegLookahead(); return p }
+
+// BeginPosLookbehind represents the start of a new group which has to precede the
+// following segment without being matched
+func (p *PythonRejex) BeginPosLookbehind() *PythonRejex { p.r.BeginPosLookbehind(); return p }
+
+// BeginNegLookbehind represents the start of a new group which must not precede the
+// following segment
+func (p *PythonRejex) BeginNegLookbehind() *PythonRejex { p.r.BeginNegLookbehind(); return p }
+
+// BeginAtomicGroup represents the start of a new group which is never backtracked into
+// once it has matched
+func (p *PythonRejex) BeginAtomicGroup() *PythonRejex { p.r.BeginAtomicGroup(); return p }
+
+// BeginConditionalGroupByNum represents the start of a new group which matches its first
+// alternative only if the group with the provided group number has captured text
+func (p *PythonRejex) BeginConditionalGroupByNum(n int) *PythonRejex { p.r.BeginConditionalGroupByNum(n); return p }
+
+// BeginConditionalGroupByName represents the start of a new group which matches its
+// first alternative only if the group with the provided group name has captured text
+func (p *PythonRejex) BeginConditionalGroupByName(s string) *PythonRejex { p.r.BeginConditionalGroupByName(s); return p }
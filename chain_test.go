@@ -0,0 +1,34 @@
+package rejex
+
+import (
+    "reflect"
+    "testing"
+)
+
+// builderTypes holds the builder of each flavor
+var builderTypes = map[RejexFlavor]reflect.Type{
+    GoFlavor: reflect.TypeOf((*GoRejex)(nil)),
+    ECMAFlavor: reflect.TypeOf((*ECMARejex)(nil)),
+    PerlFlavor: reflect.TypeOf((*PerlRejex)(nil)),
+    PCREFlavor: reflect.TypeOf((*PCRERejex)(nil)),
+    PythonFlavor: reflect.TypeOf((*PythonRejex)(nil)),
+}
+
+// TestBuilderMethods checks that the builder of each flavor has the methods which
+// construct a feature exactly when the flavor supports it, so chaining a construct the
+// flavor does not support is a compile error. The builders have every method of the
+// interfaces of their flavor, which is checked when compiling
+func TestBuilderMethods(t *testing.T) {
+    for flavor, builder := range builderTypes {
+        for method, f := range methodFeatures {
+            _, ok := builder.MethodByName(method)
+            if ok != flavor.Supports(f) {
+                t.Errorf("%s has %s(): %v, %s are supported: %v", builder, method, ok, f, flavor.Supports(f))
+            }
+        }
+    }
+
+    if _, ok := builderTypes[GoFlavor].MethodByName("BeginAtomicGroup"); ok {
+        t.Errorf("atomic groups can be chained in the GO flavor")
+    }
+}
@@ -5,148 +5,156 @@ import (
     "strconv"
 )
 
-func (r *RejexBuilder) checkForSelection(segment string) *RejexBuilder {
-    if !r.selectionActive {
-        segment = fmt.Sprintf("[%s]", segment)
-        unsegment := fmt.Sprintf("[^%s]", segment)
-        r.appendSegment(characters, segment, unsegment)
-    } else {
-        r.appendSegment(characters, segment)
+var (
+    letterItems = []ClassItem{charRange('a', 'z'), charRange('A', 'Z')}
+    uppercaseItems = []ClassItem{charRange('A', 'Z')}
+    lowercaseItems = []ClassItem{charRange('a', 'z')}
+    alNumItems = []ClassItem{charRange('0', '9'), charRange('a', 'z'), charRange('A', 'Z')}
+    punctuationItems = []ClassItem{
+        charRange('!', '/'), charRange(':', '@'), charRange('[', '`'), charRange('{', '~'),
     }
-    return r
+    graphicItems = []ClassItem{charRange('!', '~')}
+    asciiItems = []ClassItem{charRange(0x00, 0x7F)}
+    controlItems = []ClassItem{charRange(0x00, 0x1F), charRange(0x7F, 0x7F)}
+)
+
+func charRange(lo, hi rune) ClassItem {
+    return ClassItem{Kind: RangeItem, Lo: lo, Hi: hi}
 }
 
 // AnyFrom matches any single character from the provided input
 func (r *RejexBuilder) AnyFrom(s string) *RejexBuilder {
-    return r.checkForSelection(s)
+    items, err := parseClassItems(r.flavor, s)
+    if err != nil {
+        r.addErrorAt(err.Position, err.Err)
+        return r
+    }
+    return r.appendClass(items...)
 }
 
 // AnyFromCharRange matches any single character in the range between the 2 characters provided
 func (r *RejexBuilder) AnyFromCharRange(from, to string) *RejexBuilder {
-    segment := fmt.Sprintf("%s-%s", from, to)
-    return r.checkForSelection(segment)
+    lo, err := parseClassChar(r.flavor, from)
+    if err == nil {
+        var hi rune
+        if hi, err = parseClassChar(r.flavor, to); err == nil && hi >= lo {
+            return r.appendClass(ClassItem{Kind: RangeItem, Lo: lo, Hi: hi})
+        }
+    }
+    r.addError(fmt.Sprintf("Invalid character range '%s-%s'", from, to))
+    return r
 }
 
 // AnyWhitespace matches any single whitespace character
 func (r *RejexBuilder) AnyWhitespace() *RejexBuilder {
-    return r.appendSegment(characters, "\\s", "\\S")
+    return r.appendClass(ClassItem{Kind: WhitespaceItem})
 }
 
 // AnyWordChar matches any single word character
 func (r *RejexBuilder) AnyWordChar() *RejexBuilder {
-    return r.appendSegment(characters, "\\w", "\\W")
+    return r.appendClass(ClassItem{Kind: WordItem})
 }
 
 // AnyDigit matches any single decimal digit
 func (r *RejexBuilder) AnyDigit() *RejexBuilder {
-    return r.appendSegment(characters, "\\d", "\\D")
+    return r.appendClass(ClassItem{Kind: DigitItem})
 }
 
 // AnyLetter matches any single english letter
 func (r *RejexBuilder) AnyLetter() *RejexBuilder {
-    return r.checkForSelection("a-zA-Z")
+    return r.appendClass(letterItems...)
 }
 
 // AnyUppercase matches any single uppercase english letter
 func (r *RejexBuilder) AnyUppercase() *RejexBuilder {
-    return r.checkForSelection("A-Z")
+    return r.appendClass(uppercaseItems...)
 }
 
 // AnyLowercase matches any single lowercase english letter
 func (r *RejexBuilder) AnyLowercase() *RejexBuilder {
-    return r.checkForSelection("a-z")
+    return r.appendClass(lowercaseItems...)
 }
 
 // AnyAlNumChar matches any single english letter or digit
 func (r *RejexBuilder) AnyAlNumChar() *RejexBuilder {
-    return r.checkForSelection("0-9a-zA-Z")
+    return r.appendClass(alNumItems...)
 }
 
 // AnyPunctuation matches any single Punctuation character
 func (r *RejexBuilder) AnyPunctuation() *RejexBuilder {
-    return r.checkForSelection("!-/:-@[-`{-~")
+    return r.appendClass(punctuationItems...)
 }
 
 // AnyGraphicChar matches any visible character
 func (r *RejexBuilder) AnyGraphicChar() *RejexBuilder {
-    return r.checkForSelection("!-~")
+    return r.appendClass(graphicItems...)
 }
 
 // AnyASCIIChar matches any single ASCII character
 func (r *RejexBuilder) AnyASCIIChar() *RejexBuilder {
-    return r.checkForSelection("\x00-\x7F")
+    return r.appendClass(asciiItems...)
 }
 
 // AnyControlChar matches any sigle control character
 func (r *RejexBuilder) AnyControlChar() *RejexBuilder {
-    return r.checkForSelection("\x00-\x1F\x7F")
+    return r.appendClass(controlItems...)
 }
 
 // Unicode Classes
 
 // UnicodeClass matches any character from the provided unicode class
 func (r *RejexBuilder) UnicodeClass(s string) *RejexBuilder {
-    var segment, unsegment string
-    if len(s) == 1 {
-        segment = fmt.Sprintf("\\p%s", s)
-        unsegment = fmt.Sprintf("\\P%s", s)
-    } else {
-        segment = fmt.Sprintf("\\p{%s}", s)
-        unsegment = fmt.Sprintf("\\P{%s}", s)
-    }
-
-    return r.appendSegment(characters, segment, unsegment)
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: s})
 }
 
 // AnyUnicodeGrapheme matches a single Unicode grapheme, whether encoded as a
 // single code point or multiple code points using combining marks. A grapheme
 // most closely resembles the everyday concept of a “character”
 func (r *RejexBuilder) AnyUnicodeGrapheme() *RejexBuilder {
-    return r.appendSegment(characters, "\\X")
+    return r.appendNode(&Class{Items: []ClassItem{{Kind: GraphemeItem}}})
 }
 
 // AnyUnicodeLetter matches any single unicode letter
 func (r *RejexBuilder) AnyUnicodeLetter() *RejexBuilder {
-    return r.appendSegment(characters, "\\pL", "\\PL")
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: "L"})
 }
 
 // AnyUnicodeUppercase matches any single uppercase unicode character
 func (r *RejexBuilder) AnyUnicodeUppercase() *RejexBuilder {
-    return r.appendSegment(characters, "\\p{Lu}", "\\P{Lu}")
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: "Lu"})
 }
 
 // AnyUnicodeLowercase matches any single lowercase unicode character
 func (r *RejexBuilder) AnyUnicodeLowercase() *RejexBuilder {
-    return r.appendSegment(characters, "\\p{Ll}", "\\P{Ll}")
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: "Ll"})
 }
 
 // AnyUnicodeWhitespace matches any single unicode whitespace
 func (r *RejexBuilder) AnyUnicodeWhitespace() *RejexBuilder {
-    return r.appendSegment(characters, "\\pZ", "\\PZ")
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: "Z"})
 }
 
 // AnyUnicodeSymbol matches any single unicode symbol character
 func (r *RejexBuilder) AnyUnicodeSymbol() *RejexBuilder {
-    return r.appendSegment(characters, "\\pS", "\\PS")
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: "S"})
 }
 
 // AnyUnicodeNumber matches any single unicode number
 func (r *RejexBuilder) AnyUnicodeNumber() *RejexBuilder {
-    return r.appendSegment(characters, "\\pN", "\\PN")
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: "N"})
 }
 
 // AnyUnicodePunctuation matches any single unicode punctuation character
 func (r *RejexBuilder) AnyUnicodePunctuation() *RejexBuilder {
-    return r.appendSegment(characters, "\\pP", "\\PP")
+    return r.appendClass(ClassItem{Kind: UnicodeItem, Name: "P"})
 }
 
 // Non Negate-able classes
 
 // OctalChar matches the character represented by the provided octal character code
 func (r *RejexBuilder) OctalChar(c int) *RejexBuilder {
-    if c >= 0 && c < 778 {
-        segment := fmt.Sprintf("\\%03d", c)
-        r.appendSegment(characters, segment)
+    if n, e := strconv.ParseInt(strconv.Itoa(c), 8, 32); e == nil && c >= 0 && c < 778 {
+        r.appendNode(&Literal{string(rune(n))})
     } else {
         r.addError("Invalid octal character code")
     }
@@ -155,15 +163,10 @@ func (r *RejexBuilder) OctalChar(c int) *RejexBuilder {
 
 // HexChar matches the character represented by the provided hex character code
 func (r *RejexBuilder) HexChar(s string) *RejexBuilder {
-    var segment string
     if c, e := strconv.ParseInt(s, 16, 64); e != nil || c < 0 || c > 1114111 {
         r.addError("Invalid hex character code")
-    } else if len(s) == 2 {
-        segment = fmt.Sprintf("\\x%s", s)
-        r.appendSegment(characters, segment)
     } else {
-        segment = fmt.Sprintf("\\x{%s}", s)
-        r.appendSegment(characters, segment)
+        r.appendNode(&Literal{string(rune(c))})
     }
 
     return r
@@ -172,12 +175,10 @@ func (r *RejexBuilder) HexChar(s string) *RejexBuilder {
 // ControlChar matches the control character represented by the provided control
 // character code
 func (r *RejexBuilder) ControlChar(s string) *RejexBuilder {
-    if len(s) == 1 {
-        segment := fmt.Sprintf("\\c%s", s)
-        r.appendSegment(characters, segment)
+    if len(s) == 1 && (s[0] >= '@' && s[0] <= '_' || s[0] >= 'a' && s[0] <= 'z') {
+        r.appendNode(&Literal{string(rune(s[0] & 0x1F))})
     } else {
         r.addError("Invalid control character code")
     }
     return r
 }
-
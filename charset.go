@@ -0,0 +1,312 @@
+package rejex
+
+import (
+    "math/rand"
+    "sort"
+    "unicode"
+)
+
+// runeRange is an inclusive range of characters
+type runeRange struct {
+    lo, hi rune
+}
+
+// runeSet is a set of characters as a sorted list of non overlapping ranges
+type runeSet []runeRange
+
+// allRunes holds every valid character, surrogate halves are not valid on their own
+var allRunes = runeSet{{0, 0xD7FF}, {0xE000, unicode.MaxRune}}
+
+var printableASCII = runeSet{{' ', '~'}}
+
+// newRuneSet returns the set of the provided ranges, which can overlap and be in any order
+func newRuneSet(ranges ...runeRange) runeSet {
+    sort.Slice(ranges, func(i, j int) bool {
+        return ranges[i].lo < ranges[j].lo
+    })
+    var s runeSet
+    for _, rr := range ranges {
+        if rr.lo > rr.hi {
+            continue
+        }
+        if last := len(s) - 1; last >= 0 && rr.lo <= s[last].hi+1 {
+            if rr.hi > s[last].hi {
+                s[last].hi = rr.hi
+            }
+            continue
+        }
+        s = append(s, rr)
+    }
+    return s
+}
+
+// tableSet returns the set of characters in a unicode range table
+func tableSet(t *unicode.RangeTable) runeSet {
+    var ranges []runeRange
+    add := func(lo, hi, stride rune) {
+        if stride == 1 {
+            ranges = append(ranges, runeRange{lo, hi})
+            return
+        }
+        for c := lo; c <= hi; c += stride {
+            ranges = append(ranges, runeRange{c, c})
+        }
+    }
+    for _, r := range t.R16 {
+        add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+    }
+    for _, r := range t.R32 {
+        add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+    }
+    return newRuneSet(ranges...)
+}
+
+func (s runeSet) contains(c rune) bool {
+    i := sort.Search(len(s), func(i int) bool {
+        return s[i].hi >= c
+    })
+    return i < len(s) && s[i].lo <= c
+}
+
+func (s runeSet) union(o runeSet) runeSet {
+    return newRuneSet(append(append([]runeRange{}, s...), o...)...)
+}
+
+// complement returns the valid characters which are not in the set
+func (s runeSet) complement() runeSet {
+    var ranges []runeRange
+    next := rune(0)
+    for _, rr := range s {
+        if rr.lo > next {
+            ranges = append(ranges, runeRange{next, rr.lo - 1})
+        }
+        next = rr.hi + 1
+    }
+    if next <= unicode.MaxRune {
+        ranges = append(ranges, runeRange{next, unicode.MaxRune})
+    }
+    return newRuneSet(ranges...).intersect(allRunes)
+}
+
+func (s runeSet) intersect(o runeSet) runeSet {
+    var ranges []runeRange
+    for i, j := 0, 0; i < len(s) && j < len(o); {
+        lo, hi := s[i].lo, s[i].hi
+        if o[j].lo > lo {
+            lo = o[j].lo
+        }
+        if o[j].hi < hi {
+            hi = o[j].hi
+        }
+        if lo <= hi {
+            ranges = append(ranges, runeRange{lo, hi})
+        }
+        if s[i].hi < o[j].hi {
+            i++
+        } else {
+            j++
+        }
+    }
+    return ranges
+}
+
+func (s runeSet) size() int {
+    n := 0
+    for _, rr := range s {
+        n += int(rr.hi-rr.lo) + 1
+    }
+    return n
+}
+
+// nth returns the nth character of the set
+func (s runeSet) nth(n int) rune {
+    for _, rr := range s {
+        if size := int(rr.hi-rr.lo) + 1; n >= size {
+            n -= size
+        } else {
+            return rr.lo + rune(n)
+        }
+    }
+    return -1
+}
+
+// pick returns a random character of the set, preferring printable ASCII characters
+// so that the strings made out of them are readable. It returns -1 for an empty set
+func (s runeSet) pick(rnd *rand.Rand) rune {
+    if ascii := s.intersect(printableASCII); len(ascii) > 0 && rnd.Intn(4) != 0 {
+        return ascii.nth(rnd.Intn(ascii.size()))
+    }
+    if len(s) == 0 {
+        return -1
+    }
+    return s.nth(rnd.Intn(s.size()))
+}
+
+// foldSet returns the set with every character that is equal to one of its characters
+// when compared case insensitively
+func (s runeSet) foldSet() runeSet {
+    var ranges []runeRange
+    for _, rr := range s {
+        ranges = append(ranges, rr)
+        if rr.hi-rr.lo > 0x10000 {
+            continue
+        }
+        for c := rr.lo; c <= rr.hi; c++ {
+            for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+                ranges = append(ranges, runeRange{f, f})
+            }
+        }
+    }
+    return newRuneSet(ranges...)
+}
+
+var (
+    digitSet = runeSet{{'0', '9'}}
+    wordSet = newRuneSet(runeRange{'0', '9'}, runeRange{'A', 'Z'}, runeRange{'_', '_'}, runeRange{'a', 'z'})
+    goWhitespaceSet = newRuneSet(runeRange{'\t', '\n'}, runeRange{'\f', '\r'}, runeRange{' ', ' '})
+    asciiWhitespaceSet = newRuneSet(runeRange{'\t', '\r'}, runeRange{' ', ' '})
+    whitespaceSet = tableSet(unicode.White_Space)
+    // ecmaWhitespaceSet holds the white space and line terminators of ECMAScript, which
+    // include the byte order mark but not NEL
+    ecmaWhitespaceSet = whitespaceSet.union(runeSet{{0xFEFF, 0xFEFF}}).intersect(runeSet{{0, 0x84}, {0x86, unicode.MaxRune}})
+    // pythonWhitespaceSet holds the characters of str.isspace, which include the
+    // information separators
+    pythonWhitespaceSet = whitespaceSet.union(runeSet{{0x1C, 0x1F}})
+    unicodeDigitSet = tableSet(unicode.Nd)
+    // perlWordSet holds the characters of \p{Word}
+    perlWordSet = newRuneSet(append(append(append(tableSet(unicode.L), tableSet(unicode.M)...),
+        unicodeDigitSet...), append(tableSet(unicode.Pc), tableSet(unicode.Join_Control)...)...)...)
+    // pythonWordSet holds the characters of str.isalnum along with the underscore
+    pythonWordSet = tableSet(unicode.L).union(tableSet(unicode.N)).union(runeSet{{'_', '_'}})
+    // ecmaLineTerminators holds the characters the dot does not match in ECMAScript
+    ecmaLineTerminators = newRuneSet(runeRange{'\n', '\n'}, runeRange{'\r', '\r'}, runeRange{0x2028, 0x2029})
+    // bmpRunes holds the characters matched as a single code unit by ECMAScript patterns
+    // without the unicode flag
+    bmpRunes = allRunes.intersect(runeSet{{0, 0xFFFF}})
+    // linebreakSet holds the characters starting a \R, which also matches \r\n
+    linebreakSet = newRuneSet(runeRange{'\n', '\r'}, runeRange{0x85, 0x85}, runeRange{0x2028, 0x2029})
+)
+
+// unicodeShorthands reports whether \d and \w match unicode characters in a flavor. The
+// start options of PCRE2 such as (*UCP) are not taken into account
+func unicodeShorthands(flavor RejexFlavor, f matchFlags) bool {
+    switch flavor.Base() {
+    case PerlFlavor:
+        return true
+    case PythonFlavor:
+        return !f.ascii
+    }
+    return false
+}
+
+// shorthandSet returns the set of characters matched by \d, \w or \s in a flavor
+func shorthandSet(flavor RejexFlavor, kind ClassItemKind, f matchFlags) runeSet {
+    uni := unicodeShorthands(flavor, f)
+    switch kind {
+    case DigitItem:
+        if uni {
+            return unicodeDigitSet
+        }
+        return digitSet
+    case WordItem:
+        switch {
+        case !uni:
+            return wordSet
+        case flavor.Base() == PythonFlavor:
+            return pythonWordSet
+        }
+        return perlWordSet
+    }
+    switch base := flavor.Base(); {
+    case base == GoFlavor:
+        return goWhitespaceSet
+    case base == ECMAFlavor:
+        return ecmaWhitespaceSet
+    case base == PythonFlavor && uni:
+        return pythonWhitespaceSet
+    case base == PerlFlavor:
+        return whitespaceSet
+    }
+    return asciiWhitespaceSet
+}
+
+// usesUnicode reports whether a tree holds constructs which make the ECMA flavor add the
+// unicode flag when rendering it
+func usesUnicode(n Node) bool {
+    found := false
+    walkNodes(n, func(n Node) {
+        switch n := n.(type) {
+        case *Class:
+            for _, item := range n.Items {
+                found = found || item.Kind == UnicodeItem || item.Kind == RangeItem && item.Hi > 0xFFFF
+            }
+        case *Literal:
+            for _, c := range n.Text {
+                found = found || c > 0xFFFF
+            }
+        }
+    })
+    return found
+}
+
+// unicodeSet returns the set of characters of a unicode category or script
+func unicodeSet(name string) runeSet {
+    if t, ok := unicode.Categories[name]; ok {
+        return tableSet(t)
+    }
+    if t, ok := unicode.Scripts[name]; ok {
+        return tableSet(t)
+    }
+    return nil
+}
+
+// itemSet returns the set of characters matched by a class item in a flavor, a grapheme
+// or a linebreak is treated as a single character
+func itemSet(flavor RejexFlavor, item ClassItem, f matchFlags) runeSet {
+    var s runeSet
+    switch item.Kind {
+    case RangeItem:
+        s = runeSet{{item.Lo, item.Hi}}
+    case DigitItem, WordItem, WhitespaceItem:
+        s = shorthandSet(flavor, item.Kind, f)
+    case UnicodeItem:
+        s = unicodeSet(item.Name)
+    case AnyCharItem:
+        s = allRunes
+        switch {
+        case f.dotAll:
+        case flavor.Base() == ECMAFlavor:
+            s = ecmaLineTerminators.complement()
+        default:
+            s = s.intersect(runeSet{{0, '\n' - 1}, {'\n' + 1, unicode.MaxRune}})
+        }
+    case GraphemeItem:
+        s = allRunes
+    case LinebreakItem:
+        s = linebreakSet
+    }
+    if item.Negated {
+        return s.complement()
+    }
+    return s
+}
+
+// classSet returns the set of characters matched by a class in a flavor. ECMA patterns
+// without the unicode flag match code units, so astral characters are left out of the
+// sets as they are only matched by pairs of classes
+func classSet(flavor RejexFlavor, c *Class, f matchFlags) runeSet {
+    var s runeSet
+    for _, item := range c.Items {
+        s = s.union(itemSet(flavor, item, f))
+    }
+    if f.foldCase {
+        s = s.foldSet()
+    }
+    if c.Negated {
+        s = s.complement()
+    }
+    if flavor.Base() == ECMAFlavor && !f.unicode {
+        return s.intersect(bmpRunes)
+    }
+    return s
+}
@@ -0,0 +1,52 @@
+package rejex
+
+import "testing"
+
+func TestFlavorCharsets(t *testing.T) {
+    tests := []struct {
+        flavor RejexFlavor
+        pattern string
+        input string
+        want bool
+    }{
+        {GoFlavor, `^\d$`, "٣", false},
+        {GoFlavor, `^\s$`, "\v", false},
+        {ECMAFlavor, `^\s$`, "\u0085", false},
+        {ECMAFlavor, `^\s$`, "\ufeff", true},
+        {ECMAFlavor, `^a.b$`, "a\rb", false},
+        {ECMAFlavor, `^a.b$`, "a\u2028b", false},
+        {ECMAFlavor, `/^a.b$/s`, "a\U0001F600b", false},
+        {ECMAFlavor, `/^a.b$/su`, "a\U0001F600b", true},
+        {ECMAFlavor, `^\w$`, "é", false},
+        {PerlFlavor, `^\d$`, "٣", true},
+        {PerlFlavor, `^a.b$`, "a\rb", true},
+        {PCREFlavor, `^\w$`, "é", false},
+        {PythonFlavor, `^\d$`, "٣", true},
+        {PythonFlavor, `^\w+$`, "é²", true},
+        {PythonFlavor, `(?a)^\w$`, "é", false},
+        {PythonFlavor, `^(?a:\d)$`, "٣", false},
+        {PythonFlavor, `^\s$`, "\x1c", true},
+        {PythonFlavor, `\bé`, "é", true},
+        {PythonFlavor, `(?a)\bé`, "é", false},
+    }
+    for _, test := range tests {
+        got, err := fromString(test.flavor, test.pattern).Matches(test.input)
+        if err != nil || got != test.want {
+            t.Errorf("%s in the %s flavor matches %q: %v %v, want %v", test.pattern, test.flavor, test.input, got, err, test.want)
+        }
+    }
+}
+
+// TestExamplesCodeUnits checks that the examples of ECMA patterns without the unicode flag
+// have no astral characters, which the dot does not match as a single character
+func TestExamplesCodeUnits(t *testing.T) {
+    for _, pattern := range []string{`/a.b/s`, `/[^a]{5}/`, `/\S+/`} {
+        for _, s := range fromString(ECMAFlavor, pattern).Examples(200, 1) {
+            for _, c := range s {
+                if c > 0xFFFF {
+                    t.Errorf("example %q of %s has the astral character %U", s, pattern, c)
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,345 @@
+// Command rejex builds, converts, explains and tests regexes from the command line, and
+// prints the features supported by each flavor as a markdown table.
+//
+// Usage:
+//
+//     rejex build [-flavor flavor] [-to flavor] [-optimize] [-redos] [-spec spec] [pattern]
+//     rejex convert -from flavor -to flavor pattern
+//     rejex explain [-flavor flavor] [-spec spec] [pattern]
+//     rejex test [-flavor flavor] [-spec spec] [pattern] inputs
+//     rejex gen [-flavor flavor] [-spec spec] [-n count] [-seed seed] [pattern]
+//     rejex features [-output file]
+//
+// Flavors are the registered flavors looked up by name, such as go, ecma, perl, pcre or
+// python.
+// Every command reads the pattern either from the pattern argument, in the syntax of
+// -flavor, or from the spec passed with -spec. Specs are JSON or YAML files read by
+// rejex.Load, a spec of "-" is read from the standard input. A pattern argument naming
+// an existing file is rejected, as it is most likely a spec missing its -spec. The inputs file of test holds an input per line, prefixed with '+'
+// if the pattern should match it and '-' if it should not. Empty lines and lines
+// starting with '#' are ignored
+package main
+
+import (
+    "bufio"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+
+    "github.com/tyagdit/rejex"
+)
+
+// flavorNames holds the names of the registered flavors in lower case
+var flavorNames = func() []string {
+    var names []string
+    for _, flavor := range rejex.Flavors() {
+        names = append(names, strings.ToLower(flavor.Name()))
+    }
+    return names
+}()
+
+// flavorUsage is the placeholder of a flavor in the usage of the commands
+var flavorUsage = strings.Join(flavorNames, "|")
+
+type command struct {
+    usage string
+    run func(args []string, out io.Writer) error
+}
+
+var commands = map[string]command{
+    "build": {"build [-flavor " + flavorUsage + "] [-to " + flavorUsage + "] [-optimize] [-redos] [-spec spec] [pattern]", build},
+    "convert": {"convert -from " + flavorUsage + " -to " + flavorUsage + " pattern", convert},
+    "explain": {"explain [-flavor " + flavorUsage + "] [-spec spec] [pattern]", explain},
+    "test": {"test [-flavor " + flavorUsage + "] [-spec spec] [pattern] inputs", test},
+    "gen": {"gen [-flavor " + flavorUsage + "] [-spec spec] [-n count] [-seed seed] [pattern]", gen},
+    "features": {"features [-output file]", features},
+}
+
+// errUsage is returned by commands called with invalid arguments
+var errUsage = fmt.Errorf("invalid arguments")
+
+func usage() {
+    fmt.Fprintln(os.Stderr, "usage:")
+    for _, name := range []string{"build", "convert", "explain", "test", "gen", "features"} {
+        fmt.Fprintln(os.Stderr, "    rejex", commands[name].usage)
+    }
+}
+
+func main() {
+    if len(os.Args) < 2 {
+        usage()
+        os.Exit(2)
+    }
+    cmd, ok := commands[os.Args[1]]
+    if !ok {
+        usage()
+        os.Exit(2)
+    }
+
+    err := cmd.run(os.Args[2:], os.Stdout)
+    switch {
+    case err == errUsage:
+        fmt.Fprintln(os.Stderr, "usage: rejex", cmd.usage)
+        os.Exit(2)
+    case err != nil:
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+}
+
+func flavorFlag(fs *flag.FlagSet, name, value string) *string {
+    return fs.String(name, value, "flavor: "+strings.Join(flavorNames, ", "))
+}
+
+func parseFlavor(name string) (rejex.RejexFlavor, error) {
+    flavor, ok := rejex.LookupFlavor(name)
+    if !ok {
+        return nil, fmt.Errorf("unknown flavor '%s'", name)
+    }
+    return flavor, nil
+}
+
+// open opens a file, or the standard input for "-"
+func open(path string) (io.ReadCloser, error) {
+    if path == "-" {
+        return io.NopCloser(os.Stdin), nil
+    }
+    return os.Open(path)
+}
+
+func loadSpec(path string) (*rejex.RejexBuilder, error) {
+    f, err := open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    return rejex.Load(f, true)
+}
+
+// source returns the builder of the spec if one is provided, or of the first argument
+// otherwise, along with the remaining arguments. Patterns naming an existing file are
+// rejected as specs passed without -spec
+func source(flavorName, spec string, args []string) (*rejex.RejexBuilder, []string, error) {
+    if spec != "" {
+        r, err := loadSpec(spec)
+        return r, args, err
+    }
+    if len(args) == 0 {
+        return nil, nil, errUsage
+    }
+    if info, err := os.Stat(args[0]); err == nil && !info.IsDir() {
+        return nil, nil, fmt.Errorf("pattern '%s' is a file, pass specs with -spec", args[0])
+    }
+    flavor, err := parseFlavor(flavorName)
+    if err != nil {
+        return nil, nil, err
+    }
+    return rejex.NewFromString(flavor, args[0], true), args[1:], nil
+}
+
+// report prints the errors to the standard error, and fails if any is not a warning
+func report(errs []rejex.RejexError) error {
+    failed := false
+    for _, err := range errs {
+        fmt.Fprintln(os.Stderr, err.Error())
+        failed = failed || !err.Warning
+    }
+    if failed {
+        return fmt.Errorf("regex has errors")
+    }
+    return nil
+}
+
+func build(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("build", flag.ContinueOnError)
+    flavorName := flavorFlag(fs, "flavor", "go")
+    toName := fs.String("to", "", "flavor to render in instead of the flavor of the pattern")
+    spec := fs.String("spec", "", "spec file to read the pattern from")
+    optimize := fs.Bool("optimize", false, "render an optimized pattern")
+    redos := fs.Bool("redos", false, "warn about catastrophic backtracking")
+    if fs.Parse(args) != nil {
+        return errUsage
+    }
+
+    r, rest, err := source(*flavorName, *spec, fs.Args())
+    if err != nil {
+        return err
+    }
+    if len(rest) > 0 {
+        return errUsage
+    }
+    if *optimize {
+        r.Optimize()
+    }
+    if *redos {
+        r.WarnReDoS()
+    }
+    var built string
+    var errs []rejex.RejexError
+    if *toName == "" {
+        built, errs = r.Build()
+    } else {
+        flavor, err := parseFlavor(*toName)
+        if err != nil {
+            return err
+        }
+        built, errs = r.BuildFor(flavor)
+    }
+    if err := report(errs); err != nil {
+        return err
+    }
+    fmt.Fprintln(out, built)
+    return nil
+}
+
+func convert(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+    fromName := flavorFlag(fs, "from", "go")
+    toName := flavorFlag(fs, "to", "go")
+    if fs.Parse(args) != nil || fs.NArg() != 1 {
+        return errUsage
+    }
+
+    from, err := parseFlavor(*fromName)
+    if err != nil {
+        return err
+    }
+    to, err := parseFlavor(*toName)
+    if err != nil {
+        return err
+    }
+    converted, errs := rejex.Convert(fs.Arg(0), from, to)
+    if err := report(errs); err != nil {
+        return err
+    }
+    fmt.Fprintln(out, converted)
+    return nil
+}
+
+func explain(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+    flavorName := flavorFlag(fs, "flavor", "go")
+    spec := fs.String("spec", "", "spec file to read the pattern from")
+    if fs.Parse(args) != nil {
+        return errUsage
+    }
+
+    r, rest, err := source(*flavorName, *spec, fs.Args())
+    if err != nil {
+        return err
+    }
+    if len(rest) > 0 {
+        return errUsage
+    }
+    if _, errs := r.Build(); report(errs) != nil {
+        return fmt.Errorf("regex has errors")
+    }
+    fmt.Fprintln(out, r.Explain())
+    return nil
+}
+
+func test(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("test", flag.ContinueOnError)
+    flavorName := flavorFlag(fs, "flavor", "go")
+    spec := fs.String("spec", "", "spec file to read the pattern from")
+    if fs.Parse(args) != nil {
+        return errUsage
+    }
+
+    r, rest, err := source(*flavorName, *spec, fs.Args())
+    if err != nil {
+        return err
+    }
+    if len(rest) != 1 {
+        return errUsage
+    }
+    built, errs := r.Build()
+    if err := report(errs); err != nil {
+        return err
+    }
+
+    f, err := open(rest[0])
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    total, failures := 0, 0
+    scanner := bufio.NewScanner(f)
+    for line := 1; scanner.Scan(); line++ {
+        text := scanner.Text()
+        if text == "" || text[0] == '#' {
+            continue
+        }
+        if text[0] != '+' && text[0] != '-' {
+            return fmt.Errorf("%s:%d: inputs should start with '+' or '-'", rest[0], line)
+        }
+
+        total++
+        input, want := text[1:], text[0] == '+'
+        got, err := r.Matches(input)
+        switch {
+        case err != nil:
+            failures++
+            fmt.Fprintf(out, "FAIL %s:%d: %v\n", rest[0], line, err)
+        case got != want && want:
+            failures++
+            fmt.Fprintf(out, "FAIL %s:%d: %s does not match %q\n", rest[0], line, built, input)
+        case got != want:
+            failures++
+            fmt.Fprintf(out, "FAIL %s:%d: %s matches %q\n", rest[0], line, built, input)
+        }
+    }
+    if err := scanner.Err(); err != nil {
+        return err
+    }
+
+    if failures > 0 {
+        return fmt.Errorf("%d of %d inputs failed", failures, total)
+    }
+    fmt.Fprintf(out, "ok %d inputs\n", total)
+    return nil
+}
+
+func gen(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+    flavorName := flavorFlag(fs, "flavor", "go")
+    spec := fs.String("spec", "", "spec file to read the pattern from")
+    n := fs.Int("n", 10, "number of examples")
+    seed := fs.Int64("seed", 1, "seed of the random source")
+    if fs.Parse(args) != nil {
+        return errUsage
+    }
+
+    r, rest, err := source(*flavorName, *spec, fs.Args())
+    if err != nil {
+        return err
+    }
+    if len(rest) > 0 {
+        return errUsage
+    }
+    if _, errs := r.Build(); report(errs) != nil {
+        return fmt.Errorf("regex has errors")
+    }
+    for _, example := range r.Examples(*n, *seed) {
+        fmt.Fprintln(out, example)
+    }
+    return nil
+}
+
+func features(args []string, out io.Writer) error {
+    fs := flag.NewFlagSet("features", flag.ContinueOnError)
+    output := fs.String("output", "", "file to write the table to instead of the standard output")
+    if fs.Parse(args) != nil || fs.NArg() != 0 {
+        return errUsage
+    }
+
+    table := rejex.FeatureTable()
+    if *output == "" {
+        fmt.Fprint(out, table)
+        return nil
+    }
+    return os.WriteFile(*output, []byte(table), 0644)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "testing"
+)
+
+// run runs a command and returns its output
+func run(t *testing.T, name string, args ...string) (string, error) {
+    t.Helper()
+    var out bytes.Buffer
+    err := commands[name].run(args, &out)
+    return out.String(), err
+}
+
+// write writes a file in a temporary directory and returns its path
+func write(t *testing.T, name, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestCommands(t *testing.T) {
+    spec := write(t, "spec.json", `{"flavor": "go", "seq": [{"text": "a.b"}, {"class": "digit", "repeat": {"min": 1}}]}`)
+    inputs := write(t, "inputs.txt", "# comment\n+a.b1\n\n-ab\n-a.b\n")
+
+    tests := []struct {
+        name string
+        args []string
+        want string
+    }{
+        {"build", []string{"-spec", spec}, "a\\.b\\d+\n"},
+        {"build", []string{"-spec", spec, "-to", "ecma"}, "/a\\.b\\d+/\n"},
+        {"build", []string{"-optimize", "abc|abd"}, "ab[cd]\n"},
+        {"build", []string{"-flavor", "pcre", "-to", "python", `(?<n>a)\k<n>`}, "(?P<n>a)(?P=n)\n"},
+        {"convert", []string{"-from", "perl", "-to", "ecma", `/a+/i`}, "/a+/i\n"},
+        {"test", []string{"-spec", spec, inputs}, "ok 3 inputs\n"},
+        {"features", nil, ""},
+    }
+    for _, test := range tests {
+        got, err := run(t, test.name, test.args...)
+        if err != nil || test.want != "" && got != test.want {
+            t.Errorf("rejex %s %q printed %q %v, want %q", test.name, test.args, got, err, test.want)
+        }
+    }
+}
+
+// TestPatternFiles checks that every command rejects a spec passed as the pattern, rather
+// than reading its path as a pattern
+func TestPatternFiles(t *testing.T) {
+    spec := write(t, "spec.json", `{"seq": [{"text": "a"}]}`)
+    for _, name := range []string{"build", "explain", "test", "gen"} {
+        _, err := run(t, name, spec, spec)
+        if err == nil || !strings.Contains(err.Error(), "-spec") {
+            t.Errorf("rejex %s with a spec as the pattern returned %v", name, err)
+        }
+    }
+}
+
+func TestExplain(t *testing.T) {
+    spec := write(t, "spec.json", `{"seq": [{"text": "ab"}]}`)
+    for _, args := range [][]string{{"ab"}, {"-spec", spec}} {
+        got, err := run(t, "explain", args...)
+        if err != nil || !strings.Contains(got, "ab") {
+            t.Errorf("rejex explain %q printed %q %v", args, got, err)
+        }
+    }
+}
+
+func TestGen(t *testing.T) {
+    got, err := run(t, "gen", "-n", "5", `[a-f]{2}\d`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+    if len(lines) != 5 {
+        t.Errorf("rejex gen printed %d examples, want 5", len(lines))
+    }
+    re := regexp.MustCompile(`^[a-f]{2}\d$`)
+    for _, line := range lines {
+        if !re.MatchString(line) {
+            t.Errorf("example %q does not match", line)
+        }
+    }
+}
+
+func TestTestFailures(t *testing.T) {
+    inputs := write(t, "inputs.txt", "+b\n-a\n")
+    got, err := run(t, "test", "a", inputs)
+    if err == nil || err.Error() != "2 of 2 inputs failed" || strings.Count(got, "FAIL") != 2 {
+        t.Errorf("rejex test printed %q %v", got, err)
+    }
+}
+
+func TestUsage(t *testing.T) {
+    tests := []struct {
+        name string
+        args []string
+    }{
+        {"build", nil},
+        {"build", []string{"a", "b"}},
+        {"convert", nil},
+        {"explain", []string{"-unknown", "a"}},
+        {"test", []string{"a"}},
+        {"gen", []string{"a", "b"}},
+        {"features", []string{"a"}},
+    }
+    for _, test := range tests {
+        if _, err := run(t, test.name, test.args...); err != errUsage {
+            t.Errorf("rejex %s %q returned %v, want the usage", test.name, test.args, err)
+        }
+    }
+    if _, err := run(t, "build", "-flavor", "cobol", "a"); err == nil {
+        t.Errorf("an unknown flavor is accepted")
+    }
+}
@@ -0,0 +1,45 @@
+// Command rejex2go prints the rejex chain which constructs an existing regex.
+//
+// Usage:
+//
+//     rejex2go [-flavor flavor] pattern
+//
+// The flavor is the name of a registered flavor, such as go, ecma, perl, pcre or python.
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "github.com/tyagdit/rejex"
+)
+
+func main() {
+    var names []string
+    for _, flavor := range rejex.Flavors() {
+        names = append(names, strings.ToLower(flavor.Name()))
+    }
+    flavorName := flag.String("flavor", "go", "flavor of the pattern: "+strings.Join(names, ", "))
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: rejex2go [-flavor %s] pattern\n", strings.Join(names, "|"))
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    flavor, ok := rejex.LookupFlavor(*flavorName)
+    if !ok || flag.NArg() != 1 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    src, errs := rejex.GenerateChain(flag.Arg(0), flavor)
+    if len(errs) > 0 {
+        for _, err := range errs {
+            fmt.Fprintln(os.Stderr, err.Error())
+        }
+        os.Exit(1)
+    }
+    fmt.Println(src)
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+    "fmt"
+    "go/ast"
+    "go/constant"
+    "go/token"
+    "reflect"
+
+    "github.com/tyagdit/rejex"
+    "github.com/tyagdit/rejex/patterns"
+)
+
+const (
+    rejexPath = "github.com/tyagdit/rejex"
+    patternsPath = "github.com/tyagdit/rejex/patterns"
+)
+
+// functions holds the functions which can be called in a chain, by import path and name
+var functions = map[string]interface{}{
+    rejexPath + ".NewRejex": rejex.NewRejex,
+    rejexPath + ".NewRejexFromString": rejex.NewRejexFromString,
+    rejexPath + ".NewECMARejex": rejex.NewECMARejex,
+    rejexPath + ".NewECMARejexFromString": rejex.NewECMARejexFromString,
+    rejexPath + ".NewPerlRejex": rejex.NewPerlRejex,
+    rejexPath + ".NewPerlRejexFromString": rejex.NewPerlRejexFromString,
+    rejexPath + ".NewPCRERejex": rejex.NewPCRERejex,
+    rejexPath + ".NewPCRERejexFromString": rejex.NewPCRERejexFromString,
+    rejexPath + ".NewPythonRejex": rejex.NewPythonRejex,
+    rejexPath + ".NewPythonRejexFromString": rejex.NewPythonRejexFromString,
+    patternsPath + ".IPv4": patterns.IPv4,
+    patternsPath + ".IPv6": patterns.IPv6,
+    patternsPath + ".UUID": patterns.UUID,
+    patternsPath + ".MAC": patterns.MAC,
+    patternsPath + ".SemVer": patterns.SemVer,
+    patternsPath + ".Date": patterns.Date,
+    patternsPath + ".Time": patterns.Time,
+    patternsPath + ".DateTime": patterns.DateTime,
+    patternsPath + ".HexColor": patterns.HexColor,
+    patternsPath + ".JWT": patterns.JWT,
+    patternsPath + ".Email": patterns.Email,
+    patternsPath + ".URL": patterns.URL,
+}
+
+// flavors holds the flavor of the builders returned by each function
+var flavors = map[string]rejex.RejexFlavor{
+    rejexPath + ".NewECMARejex": rejex.ECMAFlavor,
+    rejexPath + ".NewECMARejexFromString": rejex.ECMAFlavor,
+    rejexPath + ".NewPerlRejex": rejex.PerlFlavor,
+    rejexPath + ".NewPerlRejexFromString": rejex.PerlFlavor,
+    rejexPath + ".NewPCRERejex": rejex.PCREFlavor,
+    rejexPath + ".NewPCRERejexFromString": rejex.PCREFlavor,
+    rejexPath + ".NewPythonRejex": rejex.PythonFlavor,
+    rejexPath + ".NewPythonRejexFromString": rejex.PythonFlavor,
+}
+
+// values holds the constants which can be used as arguments, by import path and name
+var values = map[string]interface{}{
+    rejexPath + ".CaseInsensitiveFlag": rejex.CaseInsensitiveFlag,
+    rejexPath + ".MultilineFlag": rejex.MultilineFlag,
+    rejexPath + ".SingleLineFlag": rejex.SingleLineFlag,
+    rejexPath + ".UngreedyFlag": rejex.UngreedyFlag,
+    rejexPath + ".StickyFlag": rejex.StickyFlag,
+    rejexPath + ".UnicodeFlag": rejex.UnicodeFlag,
+    rejexPath + ".GlobalFlag": rejex.GlobalFlag,
+    rejexPath + ".VerboseFlag": rejex.VerboseFlag,
+    rejexPath + ".ASCIIFlag": rejex.ASCIIFlag,
+    rejexPath + ".LocaleFlag": rejex.LocaleFlag,
+    patternsPath + ".Strict": patterns.Strict,
+    patternsPath + ".Lenient": patterns.Lenient,
+}
+
+// types holds the types which can be used in composite literals, by import path and name
+var types = map[string]reflect.Type{
+    rejexPath + ".RejexFlag": reflect.TypeOf(rejex.RejexFlag(0)),
+    rejexPath + ".NumberRangeOptions": reflect.TypeOf(rejex.NumberRangeOptions{}),
+    rejexPath + ".WordsOptions": reflect.TypeOf(rejex.WordsOptions{}),
+    patternsPath + ".Variant": reflect.TypeOf(patterns.Variant(0)),
+    "string": reflect.TypeOf(""),
+    "int": reflect.TypeOf(0),
+    "bool": reflect.TypeOf(false),
+}
+
+// evaluator evaluates the expression of a rejex chain at generate time. Only calls of
+// the chain methods and of the functions above are evaluated, with arguments made of
+// literals, the constants above and the constants declared in the package
+type evaluator struct {
+    fset *token.FileSet
+    // imports maps the names of the imports of the file to their paths
+    imports map[string]string
+    consts map[string]ast.Expr
+    // flavor is the flavor of the builder the chain starts from
+    flavor rejex.RejexFlavor
+}
+
+func (e *evaluator) errorf(n ast.Node, format string, a ...interface{}) error {
+    return fmt.Errorf("%s: %s", e.fset.Position(n.Pos()), fmt.Sprintf(format, a...))
+}
+
+// qualified returns the import path and name of a selector of an imported package
+func (e *evaluator) qualified(expr ast.Expr) (string, bool) {
+    switch x := expr.(type) {
+    case *ast.SelectorExpr:
+        if pkg, ok := x.X.(*ast.Ident); ok {
+            if path, ok := e.imports[pkg.Name]; ok {
+                return path + "." + x.Sel.Name, true
+            }
+        }
+    case *ast.Ident:
+        if _, ok := types[x.Name]; ok {
+            return x.Name, true
+        }
+    }
+    return "", false
+}
+
+// typeOf returns the type written in a composite literal
+func (e *evaluator) typeOf(expr ast.Expr) (reflect.Type, error) {
+    if arr, ok := expr.(*ast.ArrayType); ok && arr.Len == nil {
+        elem, err := e.typeOf(arr.Elt)
+        if err != nil {
+            return nil, err
+        }
+        return reflect.SliceOf(elem), nil
+    }
+    if name, ok := e.qualified(expr); ok {
+        if t, ok := types[name]; ok {
+            return t, nil
+        }
+    }
+    return nil, e.errorf(expr, "unsupported type")
+}
+
+// eval evaluates an expression as a value of the provided type, a nil type is used when
+// the type is not known such as for the receiver of a method
+func (e *evaluator) eval(expr ast.Expr, want reflect.Type) (reflect.Value, error) {
+    switch x := expr.(type) {
+    case *ast.ParenExpr:
+        return e.eval(x.X, want)
+    case *ast.CallExpr:
+        return e.call(x, want)
+    case *ast.CompositeLit:
+        return e.composite(x, want)
+    case *ast.BasicLit:
+        return e.constant(x, constant.MakeFromLiteral(x.Value, x.Kind, 0), want)
+    case *ast.UnaryExpr:
+        if x.Op == token.SUB {
+            if lit, ok := x.X.(*ast.BasicLit); ok {
+                v := constant.UnaryOp(token.SUB, constant.MakeFromLiteral(lit.Value, lit.Kind, 0), 0)
+                return e.constant(x, v, want)
+            }
+        }
+    case *ast.Ident:
+        switch x.Name {
+        case "true", "false":
+            return e.constant(x, constant.MakeBool(x.Name == "true"), want)
+        }
+        if value, ok := e.consts[x.Name]; ok {
+            return e.eval(value, want)
+        }
+        return reflect.Value{}, e.errorf(x, "%s is not a constant of the package", x.Name)
+    case *ast.SelectorExpr:
+        if name, ok := e.qualified(x); ok {
+            if v, ok := values[name]; ok {
+                return e.convert(x, reflect.ValueOf(v), want)
+            }
+        }
+    }
+    return reflect.Value{}, e.errorf(expr, "unsupported expression, only rejex chains with constant arguments can be evaluated")
+}
+
+// constant converts an untyped constant to the provided type
+func (e *evaluator) constant(n ast.Node, v constant.Value, want reflect.Type) (reflect.Value, error) {
+    if want == nil {
+        return reflect.Value{}, e.errorf(n, "unexpected constant")
+    }
+    out := reflect.New(want).Elem()
+    switch {
+    case v.Kind() == constant.String && want.Kind() == reflect.String:
+        out.SetString(constant.StringVal(v))
+    case v.Kind() == constant.Bool && want.Kind() == reflect.Bool:
+        out.SetBool(constant.BoolVal(v))
+    case v.Kind() == constant.Int && out.CanInt():
+        i, exact := constant.Int64Val(v)
+        if !exact || out.OverflowInt(i) {
+            return reflect.Value{}, e.errorf(n, "constant %s overflows %s", v, want)
+        }
+        out.SetInt(i)
+    default:
+        return reflect.Value{}, e.errorf(n, "cannot use %s as %s", v, want)
+    }
+    return out, nil
+}
+
+func (e *evaluator) convert(n ast.Node, v reflect.Value, want reflect.Type) (reflect.Value, error) {
+    if want == nil || v.Type().AssignableTo(want) {
+        return v, nil
+    }
+    return reflect.Value{}, e.errorf(n, "cannot use %s as %s", v.Type(), want)
+}
+
+func (e *evaluator) composite(lit *ast.CompositeLit, want reflect.Type) (reflect.Value, error) {
+    t := want
+    if lit.Type != nil {
+        var err error
+        if t, err = e.typeOf(lit.Type); err != nil {
+            return reflect.Value{}, err
+        }
+    }
+    if t == nil {
+        return reflect.Value{}, e.errorf(lit, "unexpected composite literal")
+    }
+
+    out := reflect.New(t).Elem()
+    switch t.Kind() {
+    case reflect.Slice:
+        for _, elt := range lit.Elts {
+            v, err := e.eval(elt, t.Elem())
+            if err != nil {
+                return reflect.Value{}, err
+            }
+            out = reflect.Append(out, v)
+        }
+    case reflect.Struct:
+        for _, elt := range lit.Elts {
+            kv, ok := elt.(*ast.KeyValueExpr)
+            key, isIdent := kv.Key.(*ast.Ident)
+            if !ok || !isIdent {
+                return reflect.Value{}, e.errorf(elt, "struct literals should use field names")
+            }
+            field, ok := t.FieldByName(key.Name)
+            if !ok {
+                return reflect.Value{}, e.errorf(elt, "unknown field %s of %s", key.Name, t)
+            }
+            v, err := e.eval(kv.Value, field.Type)
+            if err != nil {
+                return reflect.Value{}, err
+            }
+            out.FieldByIndex(field.Index).Set(v)
+        }
+    default:
+        return reflect.Value{}, e.errorf(lit, "unsupported composite literal")
+    }
+    return e.convert(lit, out, want)
+}
+
+// call evaluates a call of one of the functions above or of a method of a builder
+func (e *evaluator) call(call *ast.CallExpr, want reflect.Type) (reflect.Value, error) {
+    if call.Ellipsis.IsValid() {
+        return reflect.Value{}, e.errorf(call, "variadic arguments are not supported")
+    }
+
+    var fn reflect.Value
+    if name, ok := e.qualified(call.Fun); ok {
+        f, ok := functions[name]
+        if !ok {
+            return reflect.Value{}, e.errorf(call, "unsupported function %s", name)
+        }
+        fn = reflect.ValueOf(f)
+        if e.flavor == nil {
+            e.flavor = rejex.GoFlavor
+            if flavor, ok := flavors[name]; ok {
+                e.flavor = flavor
+            }
+        }
+    } else if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+        recv, err := e.eval(sel.X, nil)
+        if err != nil {
+            return reflect.Value{}, err
+        }
+        if fn = recv.MethodByName(sel.Sel.Name); !fn.IsValid() {
+            return reflect.Value{}, e.errorf(sel.Sel, "%s has no method %s", recv.Type(), sel.Sel.Name)
+        }
+    } else {
+        return reflect.Value{}, e.errorf(call, "unsupported call")
+    }
+
+    t := fn.Type()
+    if len(call.Args) < t.NumIn()-1 || !t.IsVariadic() && len(call.Args) != t.NumIn() {
+        return reflect.Value{}, e.errorf(call, "wrong number of arguments, want %d", t.NumIn())
+    }
+    args := make([]reflect.Value, len(call.Args))
+    for i, arg := range call.Args {
+        var param reflect.Type
+        if t.IsVariadic() && i >= t.NumIn()-1 {
+            param = t.In(t.NumIn() - 1).Elem()
+        } else {
+            param = t.In(i)
+        }
+        v, err := e.eval(arg, param)
+        if err != nil {
+            return reflect.Value{}, err
+        }
+        args[i] = v
+    }
+
+    out := fn.Call(args)
+    if t.NumOut() != 1 {
+        return reflect.Value{}, e.errorf(call, "%s should return a single value", exprName(call.Fun))
+    }
+    return e.convert(call, out[0], want)
+}
+
+// exprName returns the name of a function or method
+func exprName(expr ast.Expr) string {
+    if sel, ok := expr.(*ast.SelectorExpr); ok {
+        return sel.Sel.Name
+    }
+    return fmt.Sprintf("%T", expr)
+}
@@ -0,0 +1,249 @@
+// Command rejexgen bakes rejex chains into constant patterns at generate time, so they
+// are neither built at init time nor print errors at runtime.
+//
+// Usage:
+//
+//     rejexgen [-dir directory] [-output file]
+//
+// It scans the Go files of a package for var declarations annotated with a
+// //rejex:generate comment, whose value is a rejex chain with constant arguments:
+//
+//     //go:generate go run github.com/tyagdit/rejex/cmd/rejexgen
+//
+//     //rejex:generate
+//     var Date = rejex.NewRejex().Starting().AnyDigit().NOf("", 4).Ending()
+//
+// and writes a file declaring the pattern of each as a constant, DatePattern, along with
+// a DateRegexp variable compiled with regexp.MustCompile for the chains of the Go
+// flavor. Generation fails if building any of the chains reports an error or a warning.
+// The annotated declarations can be kept in a file excluded from builds with a build
+// constraint, the files are scanned regardless of their constraints
+package main
+
+import (
+    "bytes"
+    "flag"
+    "fmt"
+    "go/ast"
+    "go/format"
+    "go/parser"
+    "go/token"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/tyagdit/rejex"
+)
+
+const directive = "//rejex:generate"
+
+// pattern is a chain evaluated at generate time
+type pattern struct {
+    name string
+    pattern string
+    flavor rejex.RejexFlavor
+}
+
+// annotated reports whether a comment group holds the directive
+func annotated(doc *ast.CommentGroup) bool {
+    if doc == nil {
+        return false
+    }
+    for _, c := range doc.List {
+        if strings.TrimSpace(c.Text) == directive {
+            return true
+        }
+    }
+    return false
+}
+
+// builder holds the methods of the builders of every flavor used to build the patterns
+type builder interface {
+    Build() (string, []rejex.RejexError)
+}
+
+// scan parses the Go files of a directory and evaluates the annotated declarations
+func scan(dir, output string) (string, []pattern, []error) {
+    fset := token.NewFileSet()
+    paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+    if err != nil {
+        return "", nil, []error{err}
+    }
+    sort.Strings(paths)
+
+    var files []*ast.File
+    for _, path := range paths {
+        if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == filepath.Base(output) {
+            continue
+        }
+        f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+        if err != nil {
+            return "", nil, []error{err}
+        }
+        // files of other packages such as programs excluded from builds are skipped
+        if len(files) == 0 || f.Name.Name == files[0].Name.Name {
+            files = append(files, f)
+        }
+    }
+    if len(files) == 0 {
+        return "", nil, []error{fmt.Errorf("no Go files in %s", dir)}
+    }
+
+    // constants of the package can be used as arguments
+    consts := map[string]ast.Expr{}
+    for _, f := range files {
+        for _, decl := range f.Decls {
+            if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+                for _, spec := range gen.Specs {
+                    vs := spec.(*ast.ValueSpec)
+                    for i, name := range vs.Names {
+                        if i < len(vs.Values) {
+                            consts[name.Name] = vs.Values[i]
+                        }
+                    }
+                }
+            }
+        }
+    }
+
+    var patterns []pattern
+    var errs []error
+    for _, f := range files {
+        imports := map[string]string{}
+        for _, imp := range f.Imports {
+            path, _ := strconv.Unquote(imp.Path.Value)
+            name := path[strings.LastIndex(path, "/")+1:]
+            if imp.Name != nil {
+                name = imp.Name.Name
+            }
+            imports[name] = path
+        }
+
+        for _, decl := range f.Decls {
+            gen, ok := decl.(*ast.GenDecl)
+            if !ok || gen.Tok != token.VAR {
+                continue
+            }
+            for _, spec := range gen.Specs {
+                vs := spec.(*ast.ValueSpec)
+                if !annotated(gen.Doc) && !annotated(vs.Doc) {
+                    continue
+                }
+                for i, name := range vs.Names {
+                    if i >= len(vs.Values) {
+                        errs = append(errs, fmt.Errorf("%s: %s has no value", fset.Position(name.Pos()), name.Name))
+                        continue
+                    }
+                    e := evaluator{fset: fset, imports: imports, consts: consts}
+                    p, err := e.pattern(name.Name, vs.Values[i])
+                    if err != nil {
+                        errs = append(errs, err)
+                        continue
+                    }
+                    patterns = append(patterns, p)
+                }
+            }
+        }
+    }
+    return files[0].Name.Name, patterns, errs
+}
+
+// pattern evaluates a chain and builds its pattern
+func (e *evaluator) pattern(name string, expr ast.Expr) (pattern, error) {
+    v, err := e.eval(expr, nil)
+    if err != nil {
+        return pattern{}, err
+    }
+    b, ok := v.Interface().(builder)
+    if !ok || v.Kind() == reflect.Ptr && v.IsNil() {
+        return pattern{}, e.errorf(expr, "%s is not a rejex chain", name)
+    }
+
+    built, errs := b.Build()
+    if len(errs) > 0 {
+        var msgs []string
+        for _, err := range errs {
+            msgs = append(msgs, err.Error())
+        }
+        return pattern{}, e.errorf(expr, "%s: %s", name, strings.Join(msgs, "; "))
+    }
+    return pattern{name, built, e.flavor}, nil
+}
+
+// quote returns the Go source of a string, as a raw string if possible
+func quote(s string) string {
+    if strconv.CanBackquote(s) {
+        return "`" + s + "`"
+    }
+    return strconv.Quote(s)
+}
+
+// generate returns the source of the file declaring the patterns
+func generate(pkg string, patterns []pattern) ([]byte, error) {
+    var b bytes.Buffer
+    fmt.Fprintf(&b, "// Code generated by rejexgen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+
+    compiled := false
+    for _, p := range patterns {
+        compiled = compiled || p.flavor.Base() == rejex.GoFlavor
+    }
+    if compiled {
+        fmt.Fprintf(&b, "import \"regexp\"\n\n")
+    }
+
+    fmt.Fprintf(&b, "const (\n")
+    for _, p := range patterns {
+        fmt.Fprintf(&b, "// %sPattern is the pattern built by %s in the %s flavor\n", p.name, p.name, p.flavor.Name())
+        fmt.Fprintf(&b, "%sPattern = %s\n", p.name, quote(p.pattern))
+    }
+    fmt.Fprintf(&b, ")\n\n")
+
+    if compiled {
+        fmt.Fprintf(&b, "var (\n")
+        for _, p := range patterns {
+            if p.flavor.Base() == rejex.GoFlavor {
+                fmt.Fprintf(&b, "%sRegexp = regexp.MustCompile(%sPattern)\n", p.name, p.name)
+            }
+        }
+        fmt.Fprintf(&b, ")\n")
+    }
+    return format.Source(b.Bytes())
+}
+
+func main() {
+    dir := flag.String("dir", ".", "directory of the package to scan")
+    output := flag.String("output", "rejex_gen.go", "file to write, relative to the directory")
+    flag.Usage = func() {
+        fmt.Fprintln(flag.CommandLine.Output(), "usage: rejexgen [-dir directory] [-output file]")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+    if flag.NArg() != 0 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    pkg, patterns, errs := scan(*dir, *output)
+    if len(errs) > 0 {
+        for _, err := range errs {
+            fmt.Fprintln(os.Stderr, err)
+        }
+        os.Exit(1)
+    }
+    if len(patterns) == 0 {
+        fmt.Fprintf(os.Stderr, "no var declarations annotated with %s in %s\n", directive, *dir)
+        os.Exit(1)
+    }
+
+    src, err := generate(pkg, patterns)
+    if err == nil {
+        err = os.WriteFile(filepath.Join(*dir, *output), src, 0644)
+    }
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// writeFiles writes the files of a package in a temporary directory and returns it
+func writeFiles(t *testing.T, files map[string]string) string {
+    t.Helper()
+    dir := t.TempDir()
+    for name, content := range files {
+        if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return dir
+}
+
+const header = `package dates
+
+import (
+    "github.com/tyagdit/rejex"
+    "github.com/tyagdit/rejex/patterns"
+)
+
+var _ = patterns.Strict
+
+`
+
+func TestGenerate(t *testing.T) {
+    dir := writeFiles(t, map[string]string{
+        "dates.go": header + `const digits = 4
+
+//rejex:generate
+var Date = rejex.NewRejex(true).Starting().AnyDigit().NOf("", digits).Ending()
+
+var (
+    //rejex:generate
+    Word = rejex.NewPCRERejex().BeginAtomicGroup().AnyWordChar().OneOrMoreOf("").EndGroup()
+    skipped = rejex.NewRejex().Characters("x")
+)
+
+//rejex:generate
+var Flags = rejex.NewPythonRejex().Characters("a b").AddFlags(rejex.CaseInsensitiveFlag, rejex.VerboseFlag)
+`,
+        "addr.go": header + `//rejex:generate
+var Addr = rejex.NewRejex().Starting().Pattern(patterns.IPv4(patterns.Strict)).Ending()
+`,
+        // files which are not scanned
+        "dates_test.go": header + "//rejex:generate\nvar Test = rejex.NewRejex().Characters(\"t\")\n",
+        "rejex_gen.go": header + "//rejex:generate\nvar Old = rejex.NewRejex().Characters(\"o\")\n",
+        "tool.go": "//go:build ignore\n\npackage main\n\nimport \"github.com/tyagdit/rejex\"\n\n//rejex:generate\nvar Tool = rejex.NewRejex()\n",
+    })
+
+    pkg, patterns, errs := scan(dir, "rejex_gen.go")
+    if pkg != "dates" || len(errs) > 0 {
+        t.Fatalf("package %s is scanned with %v", pkg, errs)
+    }
+    var names []string
+    for _, p := range patterns {
+        names = append(names, p.name)
+    }
+    if got := strings.Join(names, " "); got != "Addr Date Word Flags" {
+        t.Errorf("scanned patterns are %s, want Addr Date Word Flags", got)
+    }
+
+    src, err := generate(pkg, patterns)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{
+        "// Code generated by rejexgen. DO NOT EDIT.\n\npackage dates\n\nimport \"regexp\"\n",
+        "// DatePattern is the pattern built by Date in the GO flavor\n\tDatePattern = `^\\d{4}$`\n",
+        "// WordPattern is the pattern built by Word in the PCRE flavor\n\tWordPattern = `(?>\\w+)`\n",
+        "FlagsPattern = `(?ix)a\\ b`\n",
+        "DateRegexp = regexp.MustCompile(DatePattern)\n",
+        "AddrRegexp = regexp.MustCompile(AddrPattern)\n",
+    } {
+        if !strings.Contains(string(src), want) {
+            t.Errorf("generated file has no %q:\n%s", want, src)
+        }
+    }
+    for _, unwanted := range []string{"WordRegexp", "FlagsRegexp", "skipped", "Test", "Old", "Tool"} {
+        if strings.Contains(string(src), unwanted) {
+            t.Errorf("generated file has %s:\n%s", unwanted, src)
+        }
+    }
+
+    // a package without chains of the Go flavor does not import regexp
+    src, err = generate(pkg, patterns[2:3])
+    if err != nil || strings.Contains(string(src), "regexp") {
+        t.Errorf("generated file imports regexp: %v\n%s", err, src)
+    }
+}
+
+func TestScanErrors(t *testing.T) {
+    tests := []struct {
+        decl string
+        err string
+    }{
+        {`var X = rejex.NewRejex(true).BeginCaptureGroup()`, "X: Error while building regex at position 0: Building without closing group"},
+        {`var X = rejex.CaseInsensitiveFlag`, "X is not a rejex chain"},
+        {`var X = 3`, "unexpected constant"},
+        {`var X *rejex.GoRejex`, "X has no value"},
+        {`var X = rejex.NewRejex().Characters(os.Args[0])`, "unsupported expression"},
+        {`var X = rejex.NewRejex().Characters(name)`, "name is not a constant of the package"},
+        {`var X = rejex.NewRejex().BeginAtomicGroup()`, "has no method BeginAtomicGroup"},
+        {`var X = rejex.NewRejex().NOf("a")`, "wrong number of arguments"},
+        {`var X = rejex.Convert("a", rejex.GoFlavor, rejex.ECMAFlavor)`, "unsupported function"},
+    }
+    for _, test := range tests {
+        dir := writeFiles(t, map[string]string{"x.go": header + "//rejex:generate\n" + test.decl + "\n"})
+        _, _, errs := scan(dir, "rejex_gen.go")
+        if len(errs) != 1 || !strings.Contains(errs[0].Error(), test.err) {
+            t.Errorf("%s is scanned with %v, want %q", test.decl, errs, test.err)
+        }
+    }
+
+    if _, _, errs := scan(t.TempDir(), "rejex_gen.go"); len(errs) != 1 {
+        t.Errorf("directory without Go files is scanned with %v", errs)
+    }
+}
@@ -0,0 +1,60 @@
+package rejex
+
+import (
+    "errors"
+    "fmt"
+    "regexp"
+    "regexp/syntax"
+    "strings"
+)
+
+// Compile builds the regex in the Go flavored syntax and compiles it with the regexp
+// package. It fails with the first error if there were errors while building the regex,
+// which include the errors reported by the regexp package
+func (r *RejexBuilder) Compile() (*regexp.Regexp, error) {
+    pattern, errs := r.BuildFor(GoFlavor)
+    if len(errs) > 0 {
+        return nil, &errs[0]
+    }
+
+    re, err := regexp.Compile(pattern)
+    if err != nil {
+        return nil, r.syntaxError(pattern, err)
+    }
+    return re, nil
+}
+
+// MustCompile is like Compile but panics if the regex cannot be built or compiled
+func (r *RejexBuilder) MustCompile() *regexp.Regexp {
+    re, err := r.Compile()
+    if err != nil {
+        panic("rejex: Compile: " + err.Error())
+    }
+    return re
+}
+
+// syntaxError converts an error reported by the regexp/syntax package for a pattern
+// built by the builder into a RejexError pointing to the segment which caused it
+func (r *RejexBuilder) syntaxError(pattern string, err error) *RejexError {
+    var serr *syntax.Error
+    if !errors.As(err, &serr) {
+        return &RejexError{Err: err.Error()}
+    }
+
+    root, _ := r.renderedTree(GoFlavor)
+    w := renderer{flavor: GoFlavor, flags: r.flags, options: r.options}
+    w.pattern(root)
+
+    pos := strings.Index(pattern, serr.Expr)
+    if pos < 0 || serr.Expr == pattern {
+        pos = w.prefix
+    }
+    msg := serr.Code.String()
+    if serr.Expr != "" {
+        msg = fmt.Sprintf("%s: `%s`", msg, serr.Expr)
+    }
+    if segment := r.segmentAt(GoFlavor, pos); segment != "" {
+        msg = fmt.Sprintf("%s in '%s()'", msg, segment)
+    }
+    return &RejexError{Position: pos - w.prefix, Err: msg}
+}
@@ -0,0 +1,56 @@
+package rejex
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestCompile(t *testing.T) {
+    re, err := NewRejex().Starting().AnyDigit().OneOrMoreOf("").Characters("-").Ending().Compile()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !re.MatchString("123-") || re.MatchString("12-3") {
+        t.Errorf("%s does not match like the built pattern", re)
+    }
+    // patterns of other flavors are compiled in the Go syntax
+    if re := NewPCRERejexFromString(`(?<n>a)b+`).Builder().MustCompile(); !re.MatchString("abb") || re.SubexpNames()[1] != "n" {
+        t.Errorf("%s is compiled from the PCRE flavor", re)
+    }
+    if _, err := NewPCRERejexFromString(`(a)\1`, true).Builder().Compile(); err == nil {
+        t.Errorf("backreference is compiled")
+    }
+}
+
+// TestCompileErrors checks that the errors reported by the regexp package point to the
+// segment of the chain which caused them
+func TestCompileErrors(t *testing.T) {
+    tests := []struct {
+        r *GoRejex
+        pos int
+        err string
+    }{
+        {NewRejex(true).Characters("ab").BeginCaptureGroup().AnyDigit().NOf("", 100).EndGroup().NOf("", 1000),
+            11, "invalid repeat count: `{1000}` in 'NOf()'"},
+        {NewRejex(true).AddFlags(CaseInsensitiveFlag).Characters("x").
+            BeginNonCaptureGroup().AnyWordChar().NToMOf("", 10, 100).EndGroup().NOrMoreOf("", 200),
+            15, "invalid repeat count: `{200,}` in 'NOrMoreOf()'"},
+    }
+    for _, test := range tests {
+        pattern, errs := test.r.Build()
+        if len(errs) != 1 || errs[0].Position != test.pos || errs[0].Err != test.err {
+            t.Errorf("%s is built with %+v, want '%s' at %d", pattern, errs, test.err, test.pos)
+        }
+        if _, err := test.r.Compile(); err == nil || !strings.Contains(err.Error(), test.err) {
+            t.Errorf("%s is compiled with %v, want '%s'", pattern, err, test.err)
+        }
+        func() {
+            defer func() {
+                if recover() == nil {
+                    t.Errorf("%s is compiled by MustCompile", pattern)
+                }
+            }()
+            test.r.MustCompile()
+        }()
+    }
+}
@@ -0,0 +1,143 @@
+package rejex
+
+import "fmt"
+
+// localFlags are the flags which can be changed for a part of a pattern by a flag group
+var localFlags = []RejexFlag{CaseInsensitiveFlag, MultilineFlag, SingleLineFlag, UngreedyFlag}
+
+// captureGroups returns the number of capture groups in the pattern constructed so far,
+// including the groups that are open, along with the names of the named ones
+func (r *RejexBuilder) captureGroups() (int, map[string]bool) {
+    count, names := 0, map[string]bool{}
+    visit := func(n Node) {
+        g, ok := n.(*Group)
+        if !ok || g.Kind != CaptureGroup && g.Kind != NamedCaptureGroup {
+            return
+        }
+        count++
+        if g.Name != "" {
+            names[g.Name] = true
+        }
+    }
+    for _, f := range r.frames {
+        if f.group != nil {
+            visit(f.group)
+        }
+        for _, alt := range f.alternatives {
+            for _, n := range alt {
+                walkNodes(n, visit)
+            }
+        }
+    }
+    return count, names
+}
+
+// Fragment is implemented by the builders of every flavor, whose patterns can be embedded
+// in a regex of any flavor with Pattern()
+type Fragment interface {
+    Builder() *RejexBuilder
+}
+
+// Builder returns the builder itself, so it can be embedded with Pattern()
+func (r *RejexBuilder) Builder() *RejexBuilder {
+    return r
+}
+
+// Pattern matches the pattern constructed by another builder as a single segment,
+// which can be quantified like a group. Its capture groups are numbered after the groups
+// preceding it, its flags only apply to it and its errors are reported at their position
+// in this regex. Named groups which are already defined in this regex and recursions into
+// the whole embedded pattern are reported as errors
+func (r *RejexBuilder) Pattern(fragment Fragment) *RejexBuilder {
+    other := fragment.Builder()
+    if other == r {
+        r.addError("Cannot embed a pattern in itself")
+        return r
+    }
+    if other.selection != nil {
+        r.addError("Cannot embed a pattern with an open selection set")
+    }
+    if len(other.frames) > 1 {
+        r.addError("Cannot embed a pattern with an open group")
+    }
+    if c, ok := other.Tree().(*Concat); ok && len(c.Nodes) == 0 {
+        r.addError("No segments to embed")
+        return r
+    }
+
+    // the copied nodes keep the chain methods which produced them
+    count, names := r.captureGroups()
+    var collisions []*Group
+    var wholeCalls []*Recursion
+    body := cloneNode(other.Tree(), func(orig, c Node) {
+        if name, ok := other.segments[orig]; ok {
+            r.segments[c] = name
+        }
+        switch c := c.(type) {
+        case *Group:
+            if c.Kind == NamedCaptureGroup && names[c.Name] {
+                collisions = append(collisions, c)
+            }
+            if c.Kind == ConditionalGroup && c.Num > 0 {
+                c.Num += count
+            }
+        case *Backref:
+            if c.Num > 0 {
+                c.Num += count
+            }
+        case *Recursion:
+            if c.Num > 0 {
+                c.Num += count
+            } else if c.Num == 0 && c.Name == "" {
+                wholeCalls = append(wholeCalls, c)
+            }
+        }
+    })
+
+    var on, off []RejexFlag
+    for _, f := range localFlags {
+        if other.flags[f] && !r.flags[f] {
+            on = append(on, f)
+        } else if !other.flags[f] && r.flags[f] {
+            off = append(off, f)
+        }
+    }
+    n := body
+    switch body.(type) {
+    case *Concat, *Alternation:
+        n = &Group{Kind: NonCaptureGroup, Body: body}
+    }
+    if len(on) > 0 || len(off) > 0 {
+        if len(off) > 0 {
+            on = append(append(on, '-'), off...)
+        }
+        n = &Group{Kind: FlagGroup, Flags: on, Body: body}
+    }
+
+    // errors are positioned relative to where the embedded pattern is rendered
+    start := len(render(r.flavor, r.Tree()))
+    w := renderer{flavor: r.flavor}
+    w.node(n)
+    offsets := map[Node]int{}
+    for _, s := range w.spans {
+        offsets[s.node] = start + s.start
+    }
+    for _, err := range other.Errors {
+        err.Position += offsets[body]
+        r.Errors = append(r.Errors, err)
+    }
+    for _, g := range collisions {
+        r.Errors = append(r.Errors, RejexError{
+            Position: offsets[g],
+            Err: fmt.Sprintf("Named capture group '%s' is already defined", g.Name),
+        })
+    }
+    for _, c := range wholeCalls {
+        r.Errors = append(r.Errors, RejexError{
+            Position: offsets[c],
+            Err: "Cannot embed a pattern which recurses into itself",
+        })
+    }
+
+    return r.appendNode(n)
+}
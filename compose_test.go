@@ -0,0 +1,109 @@
+package rejex
+
+import "testing"
+
+// TestPatternNumbering checks that the references of an embedded pattern are renumbered
+// after the groups preceding it, including those of open groups
+func TestPatternNumbering(t *testing.T) {
+    inner := fromString(PCREFlavor, `(a)\1(?(1)b|c)(?1)(?-1)(?<n>x)\k<n>(?&n)`)
+    tests := []struct {
+        r *RejexBuilder
+        want string
+    }{
+        {NewPCRERejex().Pattern(inner).Builder(),
+            `(?:(a)\1(?(1)b|c)(?1)(?-1)(?<n>x)\k<n>(?&n))`},
+        {NewPCRERejex().BeginCaptureGroup().Characters("z").EndGroup().Pattern(inner).Builder(),
+            `(z)(?:(a)\2(?(2)b|c)(?2)(?-1)(?<n>x)\k<n>(?&n))`},
+        {NewPCRERejex().BeginCaptureGroup().BeginCaptureGroup().Pattern(inner).EndGroup().EndGroup().Builder(),
+            `(((?:(a)\3(?(3)b|c)(?3)(?-1)(?<n>x)\k<n>(?&n))))`},
+        {NewPCRERejex().Pattern(inner).BeginNamedCaptureGroup("m").EndGroup().Pattern(fromString(PCREFlavor, `(b)\1`)).Builder(),
+            `(?:(a)\1(?(1)b|c)(?1)(?-1)(?<n>x)\k<n>(?&n))(?<m>)(?:(b)\4)`},
+    }
+    for _, test := range tests {
+        if got, errs := test.r.Build(); failed(errs) || got != test.want {
+            t.Errorf("embedded pattern is built as %s %v, want %s", got, errs, test.want)
+        }
+    }
+}
+
+func TestPatternErrors(t *testing.T) {
+    tests := []struct {
+        r *RejexBuilder
+        pos int
+        err string
+    }{
+        {NewPCRERejex(true).BeginNamedCaptureGroup("n").Characters("z").EndGroup().
+            Pattern(fromString(PCREFlavor, `(a)(?<n>x)`)).Builder(),
+            13, "Named capture group 'n' is already defined"},
+        {NewPythonRejex(true).BeginNamedCaptureGroup("n").EndGroup().Characters("z").
+            Pattern(fromString(PythonFlavor, `(?P<n>x)`)).Builder(),
+            8, "Named capture group 'n' is already defined"},
+        {NewPCRERejex(true).Characters("x").Pattern(fromString(PCREFlavor, `a(?R)?b`)).Builder(),
+            5, "Cannot embed a pattern which recurses into itself"},
+        {NewPCRERejex(true).Characters("xy").Pattern(fromString(PCREFlavor, `a{2,1}`)).Builder(),
+            3, "Invalid repeat count '{2,1}'"},
+    }
+    for _, test := range tests {
+        pattern, errs := test.r.Build()
+        if len(errs) != 1 || errs[0].Position != test.pos || errs[0].Err != test.err {
+            t.Errorf("%s is built with %+v, want '%s' at %d", pattern, errs, test.err, test.pos)
+        }
+    }
+
+    r := NewRejex(true).Builder()
+    if r.Pattern(r); len(r.Errors) != 1 {
+        t.Errorf("pattern embedded in itself is not reported")
+    }
+    if r := NewRejex(true).Pattern(NewRejex(true).Characters("a").BeginCaptureGroup()).Builder(); len(r.Errors) != 1 {
+        t.Errorf("pattern with an open group is embedded without errors")
+    }
+    if r := NewRejex(true).Pattern(NewRejex(true)).Builder(); len(r.Errors) != 1 {
+        t.Errorf("empty pattern is embedded without errors")
+    }
+}
+
+// TestPatternFlags checks that the local flags of an embedded pattern only apply to it
+func TestPatternFlags(t *testing.T) {
+    tests := []struct {
+        r *RejexBuilder
+        want string
+    }{
+        {NewRejex().Characters("x").Pattern(fromString(GoFlavor, `(?i)ab`)).Builder(), `x(?i:ab)`},
+        {NewRejex().Characters("x").Pattern(fromString(GoFlavor, `(?is)a|b`)).Builder(), `x(?is:a|b)`},
+        {fromString(GoFlavor, `(?i)x`).Pattern(fromString(GoFlavor, `ab`)), `(?i)x(?-i:ab)`},
+        {fromString(GoFlavor, `(?im)x`).Pattern(fromString(GoFlavor, `(?sm)a`)), `(?im)x(?s-i:a)`},
+        {fromString(GoFlavor, `(?i)x`).Pattern(fromString(GoFlavor, `(?i)ab`)), `(?i)xab`},
+        {fromString(PCREFlavor, `(?U)x`).Pattern(fromString(PCREFlavor, `a+`)), `(?U)x(?-U:a+)`},
+        {fromString(ECMAFlavor, `/x/i`).Pattern(fromString(ECMAFlavor, `/ab/i`)), `/xab/i`},
+    }
+    for _, test := range tests {
+        if got, errs := test.r.Build(); failed(errs) || got != test.want {
+            t.Errorf("embedded pattern is built as %s %v, want %s", got, errs, test.want)
+        }
+    }
+}
+
+// TestPatternECMAFlags checks that embedding a pattern whose local flags differ is an
+// error in the ECMA flavor, which has no inline flags, unless it is built for a flavor
+// having them
+func TestPatternECMAFlags(t *testing.T) {
+    tests := []struct {
+        r *RejexBuilder
+        want string
+    }{
+        {NewECMARejex(true).Characters("x").Pattern(fromString(ECMAFlavor, `/ab/i`)).Builder(), `/x(?i:ab)/`},
+        {fromString(ECMAFlavor, `/x/s`).Pattern(fromString(ECMAFlavor, `/a./`)), `/x(?-s:a.)/s`},
+    }
+    for _, test := range tests {
+        got, errs := test.r.Build()
+        if got != test.want {
+            t.Errorf("embedded pattern is built as %s, want %s", got, test.want)
+        }
+        if len(errs) != 1 || errs[0].Position != 1 || errs[0].Err != "Inline flags are not supported by the ECMA flavor" {
+            t.Errorf("%s is built with %+v", got, errs)
+        }
+        if got, errs := test.r.BuildFor(PCREFlavor); failed(errs) {
+            t.Errorf("%s is built for PCRE with %v", got, errs)
+        }
+    }
+}
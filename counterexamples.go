@@ -0,0 +1,201 @@
+package rejex
+
+import (
+    "fmt"
+    "math/rand"
+    "unicode/utf8"
+)
+
+// counterExampleSeed seeds the random source of CounterExamples so that the same
+// counter examples are returned each time
+const counterExampleSeed = 1
+
+// mutationAttempts is the number of strings generated for a mutation before moving on
+// to the next one, a mutation does not always lead to a string which is not matched
+const mutationAttempts = 5
+
+// CounterExample is a string which the regex does not match anywhere, along with the
+// change to the structure of the regex it was generated from
+type CounterExample struct {
+    Input string
+    Reason string
+}
+
+// mutation is a change to a node of the tree which should make the strings generated
+// from the tree no longer match. apply returns the node to generate instead, or nil to
+// generate the node as it is, along with the reason the strings do not match
+type mutation struct {
+    node Node
+    apply func() (Node, string)
+}
+
+// nearMiss returns a random character which is adjacent to a range of the set but
+// is not in the set itself, preferring printable ASCII characters. It returns -1 if
+// there are no such characters
+func nearMiss(s runeSet, rnd *rand.Rand) rune {
+    var candidates, printable []rune
+    for _, rr := range s {
+        for _, c := range []rune{rr.lo - 1, rr.hi + 1} {
+            if c < 0 || !utf8.ValidRune(c) || s.contains(c) {
+                continue
+            }
+            candidates = append(candidates, c)
+            if printableASCII.contains(c) {
+                printable = append(printable, c)
+            }
+        }
+    }
+    switch {
+    case len(printable) > 0:
+        return printable[rnd.Intn(len(printable))]
+    case len(candidates) > 0:
+        return candidates[rnd.Intn(len(candidates))]
+    }
+    return -1
+}
+
+func repetitions(n int) string {
+    if n == 1 {
+        return "1 repetition"
+    }
+    return fmt.Sprintf("%d repetitions", n)
+}
+
+// mutations returns the changes to a node which can make the regex fail to match
+func (r *RejexBuilder) mutations(n Node, g *generator) []mutation {
+    method := r.methodOf(n)
+    f := g.m.flags
+    var muts []mutation
+    add := func(apply func() (Node, string)) {
+        muts = append(muts, mutation{n, apply})
+    }
+
+    switch n := n.(type) {
+    case *Literal:
+        text := []rune(n.Text)
+        add(func() (Node, string) {
+            i := g.rnd.Intn(len(text))
+            c := text[i] + 1
+            if g.m.equalRunes(text[i], c, f) {
+                c = text[i] - 1
+            }
+            changed := append([]rune{}, text...)
+            changed[i] = c
+            return &Literal{string(changed)}, fmt.Sprintf("%q instead of %q from %s()", c, text[i], method)
+        })
+    case *Class:
+        add(func() (Node, string) {
+            c := nearMiss(g.m.classSet(n, f), g.rnd)
+            if c < 0 {
+                return nil, ""
+            }
+            return &Literal{string(c)}, fmt.Sprintf("%q is not matched by %s()", c, method)
+        })
+    case *Repeat:
+        if n.Min > 0 {
+            add(func() (Node, string) {
+                return &Repeat{Sub: n.Sub, Min: n.Min - 1, Max: n.Min - 1},
+                    fmt.Sprintf("%s, fewer than the %d required by %s()", repetitions(n.Min-1), n.Min, method)
+            })
+        }
+        if n.Max != -1 {
+            add(func() (Node, string) {
+                return &Repeat{Sub: n.Sub, Min: n.Max + 1, Max: n.Max + 1},
+                    fmt.Sprintf("%s, more than the %d allowed by %s()", repetitions(n.Max+1), n.Max, method)
+            })
+        }
+    case *Assertion:
+        switch n.Kind {
+        case AssertLineStart, AssertTextStart:
+            add(func() (Node, string) {
+                return &Literal{"x"}, fmt.Sprintf("text before the anchor of %s()", method)
+            })
+        case AssertLineEnd, AssertTextEnd, AssertTextEndNewline:
+            add(func() (Node, string) {
+                return &Literal{"x"}, fmt.Sprintf("text after the anchor of %s()", method)
+            })
+        }
+    case *Backref:
+        add(func() (Node, string) {
+            return &Literal{string(rune('a' + g.rnd.Intn(26)))},
+                fmt.Sprintf("text other than the group referred to by %s()", method)
+        })
+    case *Group:
+        switch n.Kind {
+        case PosLookahead, PosLookbehind:
+            add(func() (Node, string) {
+                return nil, fmt.Sprintf("no match for the group of %s()", method)
+            })
+        case NegLookahead, NegLookbehind:
+            add(func() (Node, string) {
+                return &Group{Kind: NonCaptureGroup, Body: n.Body},
+                    fmt.Sprintf("a match for the group excluded by %s()", method)
+            })
+        }
+    }
+    return muts
+}
+
+// CounterExamples returns up to n strings which the regex does not match anywhere,
+// each generated by changing a single segment of the regex: a repetition too few or too
+// many for a quantifier, a character just outside a class, text outside an anchor, and
+// so on. The reason of each counter example describes the change and the method of the
+// segment. No counter examples are returned if the regex has syntax errors
+func (r *RejexBuilder) CounterExamples(n int) []CounterExample {
+    root := r.Tree()
+    valid := true
+    walkNodes(root, func(n Node) {
+        if _, ok := n.(*Raw); ok {
+            valid = false
+        }
+    })
+    if !valid {
+        return nil
+    }
+
+    g := &generator{
+        m: newMatcher(r.flavor, root, r.flags),
+        rnd: rand.New(rand.NewSource(counterExampleSeed)),
+    }
+    muts := []mutation{{root, func() (Node, string) {
+        return &Concat{}, "empty input"
+    }}}
+    walkNodes(root, func(n Node) {
+        muts = append(muts, r.mutations(n, g)...)
+    })
+
+    var examples []CounterExample
+    seen := map[string]bool{}
+    // each round adds at most one counter example per mutation, so that every segment
+    // is covered before any of them is repeated
+    for round := 0; len(examples) < n && round < maxAttempts; round++ {
+        found := false
+        for _, mut := range muts {
+            if len(examples) == n {
+                break
+            }
+            for attempt := 0; attempt < mutationAttempts; attempt++ {
+                override, reason := mut.apply()
+                if override == nil && reason == "" {
+                    break
+                }
+                g.overrides = map[Node]Node{}
+                if override != nil {
+                    g.overrides[mut.node] = override
+                }
+                s := g.generate(root)
+                if seen[s] || g.m.find(s) || g.m.gaveUp {
+                    continue
+                }
+                seen[s] = true
+                examples = append(examples, CounterExample{s, reason})
+                found = true
+                break
+            }
+        }
+        if !found {
+            break
+        }
+    }
+    return examples
+}
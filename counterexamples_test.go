@@ -0,0 +1,35 @@
+package rejex
+
+import (
+    "regexp"
+    "testing"
+)
+
+// TestCounterExamplesMiss checks that the counter examples of Go patterns are not matched
+// anywhere by the regexp package and that each of them has a reason. Patterns matching
+// the empty string match every input and have none
+func TestCounterExamplesMiss(t *testing.T) {
+    for _, pattern := range examplePatterns {
+        re := regexp.MustCompile(pattern)
+        examples := fromString(GoFlavor, pattern).CounterExamples(20)
+        if len(examples) == 0 && !re.MatchString("") {
+            t.Errorf("no counter examples are generated for %s", pattern)
+        }
+        for _, c := range examples {
+            if re.MatchString(c.Input) {
+                t.Errorf("counter example %q of %s (%s) matches", c.Input, pattern, c.Reason)
+            }
+            if c.Reason == "" {
+                t.Errorf("counter example %q of %s has no reason", c.Input, pattern)
+            }
+        }
+    }
+}
+
+// TestCounterExamplesInvalid checks that no counter examples are returned for patterns
+// with syntax errors
+func TestCounterExamplesInvalid(t *testing.T) {
+    if examples := fromString(GoFlavor, `a(b`).CounterExamples(5); len(examples) > 0 {
+        t.Errorf("counter examples %v are generated for an invalid pattern", examples)
+    }
+}
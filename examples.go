@@ -0,0 +1,163 @@
+package rejex
+
+import (
+    "math/rand"
+    "strings"
+    "unicode"
+)
+
+// maxAttempts is the number of strings generated for each example before giving up,
+// generated strings which do not match because of anchors or lookarounds are discarded
+const maxAttempts = 100
+
+// maxExtraRepeats limits how many more times than the minimum an unbounded quantifier
+// is repeated in generated strings
+const maxExtraRepeats = 5
+
+// maxRecursionDepth limits how deep recursions are followed in generated strings, the
+// recursions past it generate nothing
+const maxRecursionDepth = 3
+
+// generator writes random strings which follow a syntax tree
+type generator struct {
+    strings.Builder
+    m *matcher
+    rnd *rand.Rand
+    caps map[int]string
+    depth int
+    // overrides replaces the next occurrence of nodes of the tree with other nodes to
+    // generate from instead
+    overrides map[Node]Node
+}
+
+func (g *generator) node(n Node, f matchFlags) {
+    if o, ok := g.overrides[n]; ok {
+        delete(g.overrides, n)
+        n = o
+    }
+    switch n := n.(type) {
+    case *Concat:
+        for _, sub := range n.Nodes {
+            g.node(sub, f)
+        }
+    case *Alternation:
+        g.node(n.Alternatives[g.rnd.Intn(len(n.Alternatives))], f)
+    case *Literal:
+        for _, c := range n.Text {
+            g.char(c, f)
+        }
+    case *Class:
+        if c := g.m.classSet(n, f).pick(g.rnd); c >= 0 {
+            g.WriteRune(c)
+        }
+    case *Repeat:
+        count := n.Min
+        if n.Max == -1 {
+            count += g.rnd.Intn(maxExtraRepeats + 1)
+        } else if n.Max > n.Min {
+            count += g.rnd.Intn(n.Max - n.Min + 1)
+        }
+        for i := 0; i < count; i++ {
+            g.node(n.Sub, f)
+        }
+    case *Group:
+        g.group(n, f)
+    case *Backref:
+        g.WriteString(g.caps[g.m.numbers.refs[n]])
+    case *Recursion:
+        g.recursion(n, f)
+    }
+}
+
+// recursion writes the pattern of a group again, the groups captured within it are
+// restored afterwards like when matching
+func (g *generator) recursion(n *Recursion, f matchFlags) {
+    num := g.m.numbers.calls[n]
+    body := Node(g.m.numbers.bodies[num])
+    switch {
+    case num == 0:
+        body, f = g.m.root, g.m.flags
+    case num < 0:
+        return
+    }
+    if g.depth >= maxRecursionDepth {
+        return
+    }
+    saved := map[int]string{}
+    for num, c := range g.caps {
+        saved[num] = c
+    }
+    g.depth++
+    g.node(body, f)
+    g.depth--
+    g.caps = saved
+}
+
+// generate returns a new random string following the tree
+func (g *generator) generate(root Node) string {
+    g.Reset()
+    g.caps = map[int]string{}
+    g.depth = 0
+    g.node(root, g.m.flags)
+    return g.String()
+}
+
+// char writes a character of a literal, in a random case if the case is ignored
+func (g *generator) char(c rune, f matchFlags) {
+    if f.foldCase && g.rnd.Intn(2) == 0 {
+        c = unicode.SimpleFold(c)
+    }
+    g.WriteRune(c)
+}
+
+func (g *generator) group(n *Group, f matchFlags) {
+    switch n.Kind {
+    case CaptureGroup, NamedCaptureGroup:
+        start := g.Len()
+        g.node(n.Body, f)
+        g.caps[g.m.numbers.groups[n]] = g.String()[start:]
+    case FlagGroup:
+        g.node(n.Body, f.with(n.Flags))
+    case PosLookahead, NegLookahead, PosLookbehind, NegLookbehind:
+        // lookarounds do not consume characters, the generated string is checked against them
+    case ConditionalGroup:
+        yes, no := conditionalBranches(n)
+        if _, ok := g.caps[g.m.numbers.conds[n]]; ok {
+            g.node(yes, f)
+        } else if no != nil {
+            g.node(no, f)
+        }
+    case DefineGroup:
+    default:
+        g.node(n.Body, f)
+    }
+}
+
+// Examples returns n random strings which are matched in their entirety by the regex.
+// The strings are generated from a random source with the provided seed so the same
+// examples are returned each time. Fewer than n strings are returned if the regex has
+// errors or if matching strings could not be generated, such as for lookarounds which
+// contradict the rest of the regex
+func (r *RejexBuilder) Examples(n int, seed int64) []string {
+    root := r.Tree()
+    g := generator{
+        m: newMatcher(r.flavor, root, r.flags),
+        rnd: rand.New(rand.NewSource(seed)),
+    }
+
+    var examples []string
+    seen := map[string]bool{}
+    for attempts := 0; len(examples) < n && attempts < n*maxAttempts; attempts++ {
+        s := g.generate(root)
+        if !g.m.fullMatch(s) {
+            continue
+        }
+        // repeat examples only when new ones cannot be found easily
+        if seen[s] && attempts < n*maxAttempts/2 {
+            continue
+        }
+        seen[s] = true
+        examples = append(examples, s)
+    }
+    return examples
+}
@@ -0,0 +1,54 @@
+package rejex
+
+import (
+    "reflect"
+    "regexp"
+    "testing"
+)
+
+// examplePatterns holds Go patterns which examples and counter examples are generated for
+var examplePatterns = []string{
+    `[a-z]+@[a-z]+\.com`,
+    `\d{3}-\d{4}`,
+    `(?i)hello|bye`,
+    `(a|bc)*d?`,
+    `^\w+\s\S{2,4}$`,
+    `[^a-c\d]x{2}`,
+    `\bword\b`,
+    `(?s)a.b`,
+    `\p{Greek}+`,
+    `(?m)^a$\n^b$`,
+    `(?U)a+b*`,
+    `(?P<n>[0-9a-f]{2}):?`,
+}
+
+// TestExamplesMatch checks that the examples of Go patterns are matched in their entirety
+// by the regexp package, and that the same examples are generated for the same seed
+func TestExamplesMatch(t *testing.T) {
+    for _, pattern := range examplePatterns {
+        r := fromString(GoFlavor, pattern)
+        re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+        examples := r.Examples(20, 1)
+        if len(examples) == 0 {
+            t.Errorf("no examples are generated for %s", pattern)
+        }
+        for _, s := range examples {
+            if !re.MatchString(s) {
+                t.Errorf("example %q of %s does not match", s, pattern)
+            }
+        }
+        if again := r.Examples(20, 1); !reflect.DeepEqual(again, examples) {
+            t.Errorf("examples of %s differ for the same seed: %q and %q", pattern, examples, again)
+        }
+    }
+}
+
+// TestExamplesUnsatisfiable checks that no examples are returned for patterns which
+// cannot match anything
+func TestExamplesUnsatisfiable(t *testing.T) {
+    for _, pattern := range []string{`a\bb`, `[^\s\S]`, `a^b`} {
+        if examples := fromString(GoFlavor, pattern).Examples(5, 1); len(examples) > 0 {
+            t.Errorf("examples %q are generated for %s", examples, pattern)
+        }
+    }
+}
@@ -0,0 +1,416 @@
+package rejex
+
+import (
+    "fmt"
+    "strings"
+)
+
+// noun describes what a segment matches, in singular and plural form
+type noun struct {
+    one, many string
+}
+
+// methodNouns holds the descriptions of the classes constructed by each method
+var methodNouns = map[string]noun{
+    "AnyDigit": {"a digit", "digits"},
+    "AnyWordChar": {"a word character", "word characters"},
+    "AnyWhitespace": {"a whitespace character", "whitespace characters"},
+    "AnyChar": {"any character", "characters"},
+    "AnyUnicodeGrapheme": {"a unicode grapheme", "unicode graphemes"},
+    "AnyLinebreak": {"a linebreak", "linebreaks"},
+    "AnyUnicodeLetter": {"a unicode letter", "unicode letters"},
+    "AnyUnicodeUppercase": {"a unicode uppercase letter", "unicode uppercase letters"},
+    "AnyUnicodeLowercase": {"a unicode lowercase letter", "unicode lowercase letters"},
+    "AnyUnicodeWhitespace": {"a unicode whitespace character", "unicode whitespace characters"},
+    "AnyUnicodeSymbol": {"a unicode symbol", "unicode symbols"},
+    "AnyUnicodeNumber": {"a unicode number", "unicode numbers"},
+    "AnyUnicodePunctuation": {"a unicode punctuation character", "unicode punctuation characters"},
+    "AnyPunctuation": {"a punctuation character", "punctuation characters"},
+    "LineEnding": {"a line ending character", "line ending characters"},
+    "AnyAlNumChar": {"an alphanumeric character", "alphanumeric characters"},
+    "AnyLetter": {"a letter", "letters"},
+    "AnyControlChar": {"a control character", "control characters"},
+    "AnyUppercase": {"an uppercase letter", "uppercase letters"},
+    "AnyLowercase": {"a lowercase letter", "lowercase letters"},
+    "AnyGraphicChar": {"a graphic character", "graphic characters"},
+    "AnyASCIIChar": {"an ASCII character", "ASCII characters"},
+}
+
+// methodPhrases holds the descriptions of the anchors constructed by each method
+var methodPhrases = map[string]string{
+    "AbsoluteStarting": "start of text",
+    "AbsoluteEnding": "end of text",
+    "WordBoundary": "word boundary",
+    "EndOfLastMatch": "end of the last match",
+    "ResetMatchStart": "start of the reported match",
+}
+
+// verbPhrases holds the descriptions of the backtracking control verbs
+var verbPhrases = map[string]string{
+    "ACCEPT": "end the match successfully",
+    "FAIL": "fail and backtrack",
+    "MARK": "mark '%s'",
+    "COMMIT": "no match at all if backtracked past",
+    "PRUNE": "no match from this start position if backtracked past",
+    "SKIP": "no match starting before here if backtracked past",
+    "THEN": "the next alternative if backtracked past",
+}
+
+var flagPhrases = map[RejexFlag]string{
+    CaseInsensitiveFlag: "case insensitive",
+    MultilineFlag: "multiline",
+    SingleLineFlag: "dot matches newline",
+    UngreedyFlag: "ungreedy",
+    StickyFlag: "sticky",
+    UnicodeFlag: "unicode",
+    GlobalFlag: "global",
+    VerboseFlag: "verbose",
+    ASCIIFlag: "ASCII only",
+    LocaleFlag: "locale dependent",
+}
+
+// itemNoun describes a single shorthand class item
+func itemNoun(item ClassItem) noun {
+    n, ok := methodNouns[itemCall(item).method]
+    if !ok {
+        n = noun{
+            fmt.Sprintf("a character in the unicode class '%s'", item.Name),
+            fmt.Sprintf("characters in the unicode class '%s'", item.Name),
+        }
+    }
+    if item.Negated {
+        return noun{"a character other than " + n.many, "characters other than " + n.many}
+    }
+    return n
+}
+
+// listPhrase joins a list of descriptions as "a, b or c"
+func listPhrase(parts []string) string {
+    if len(parts) == 1 {
+        return parts[0]
+    }
+    return strings.Join(parts[:len(parts)-1], ", ") + " or " + parts[len(parts)-1]
+}
+
+// classNoun describes a class with the methods which construct it
+func classNoun(c *Class) noun {
+    if len(c.Items) == 1 && isShorthand(c.Items[0]) {
+        item := c.Items[0]
+        item.Negated = item.Negated != c.Negated
+        return itemNoun(item)
+    }
+
+    var parts []string
+    var helper string
+    rest := c.Items
+    for _, h := range classHelpers {
+        var ok bool
+        if rest, ok = takeItems(rest, h.items); ok {
+            parts = append(parts, methodNouns[h.method].many)
+            helper = h.method
+        }
+    }
+    if len(parts) == 1 && len(rest) == 0 {
+        n := methodNouns[helper]
+        if c.Negated {
+            return noun{"a character other than " + n.many, "characters other than " + n.many}
+        }
+        return n
+    }
+    for _, item := range rest {
+        switch {
+        case isShorthand(item):
+            parts = append(parts, itemNoun(item).many)
+        case item.Lo == item.Hi:
+            parts = append(parts, fmt.Sprintf("%q", string(item.Lo)))
+        default:
+            parts = append(parts, fmt.Sprintf("%q to %q", string(item.Lo), string(item.Hi)))
+        }
+    }
+    if c.Negated {
+        return noun{"a character not from " + listPhrase(parts), "characters not from " + listPhrase(parts)}
+    }
+    return noun{"a character from " + listPhrase(parts), "characters from " + listPhrase(parts)}
+}
+
+// explainer writes an indented description of a syntax tree, one segment per line
+type explainer struct {
+    strings.Builder
+    flags map[RejexFlag]bool
+    ungreedy bool
+    groups int
+    depth int
+}
+
+func (e *explainer) line(lead, text string) {
+    e.WriteString(strings.Repeat("    ", e.depth))
+    e.WriteString(lead)
+    e.WriteString(text)
+    e.WriteString("\n")
+}
+
+// block writes a line followed by the description of a node, indented under it
+func (e *explainer) block(lead, text string, n Node) {
+    e.line(lead, text)
+    e.depth++
+    e.sequence(n)
+    e.depth--
+}
+
+// sequence writes the description of a node, with each of its sequential nodes on a new line
+func (e *explainer) sequence(n Node) {
+    concat, ok := n.(*Concat)
+    if !ok {
+        e.node("", n)
+        return
+    }
+    for i, sub := range concat.Nodes {
+        if i == 0 {
+            e.node("", sub)
+        } else {
+            e.node("then ", sub)
+        }
+    }
+}
+
+func (e *explainer) node(lead string, n Node) {
+    switch n := n.(type) {
+    case *Concat:
+        e.block(lead, "group containing", n)
+    case *Alternation:
+        for i, alt := range n.Alternatives {
+            if i == 0 {
+                e.block(lead, "either", alt)
+            } else {
+                e.block("", "or", alt)
+            }
+        }
+    case *Literal:
+        e.line(lead, fmt.Sprintf("%q", n.Text))
+    case *Raw:
+        e.line(lead, fmt.Sprintf("the pattern `%s`", n.Text))
+    case *Class:
+        e.line(lead, classNoun(n).one)
+    case *Repeat:
+        e.repeat(lead, n)
+    case *Group:
+        e.group(lead, n)
+    case *Assertion:
+        e.line(lead, e.assertion(n))
+    case *Backref:
+        switch {
+        case n.Name != "":
+            e.line(lead, fmt.Sprintf("the text captured by group '%s'", n.Name))
+        case n.Num < 0:
+            e.line(lead, fmt.Sprintf("the text captured by the group %d before", -n.Num))
+        default:
+            e.line(lead, fmt.Sprintf("the text captured by group %d", n.Num))
+        }
+    case *Recursion:
+        if n.Num == 0 && n.Name == "" {
+            e.line(lead, "the whole pattern again, recursively")
+        } else {
+            e.line(lead, "the pattern of " + groupPhrase(n.Num, n.Name) + " again")
+        }
+    case *Verb:
+        text := verbPhrases[n.Name]
+        switch {
+        case n.Name == "MARK":
+            text = fmt.Sprintf(text, n.Arg)
+        case n.Name == "SKIP" && n.Arg != "":
+            text = fmt.Sprintf("no match starting before mark '%s' if backtracked past", n.Arg)
+        case n.Arg != "":
+            text += fmt.Sprintf(" (marked '%s')", n.Arg)
+        }
+        e.line(lead, text)
+    }
+}
+
+// groupPhrase describes the group a reference refers to
+func groupPhrase(num int, name string) string {
+    switch {
+    case name != "":
+        return fmt.Sprintf("group '%s'", name)
+    case num < 0:
+        return fmt.Sprintf("the group %d before", -num)
+    }
+    return fmt.Sprintf("group %d", num)
+}
+
+// quantity describes the bounds of a repeat, with the description of its sub node if it has one
+func quantity(n *Repeat, sub *noun) string {
+    switch {
+    case sub == nil && n.Min == 0 && n.Max == 1:
+        return "optionally"
+    case sub == nil:
+        sub = &noun{"once", "times"}
+    }
+    switch {
+    case n.Min == 0 && n.Max == 1:
+        return "optionally " + sub.one
+    case n.Min == 1 && n.Max == 1:
+        return sub.one
+    case n.Min == 0 && n.Max == -1:
+        return "zero or more " + sub.many
+    case n.Min == 1 && n.Max == -1:
+        return "one or more " + sub.many
+    case n.Max == -1:
+        return fmt.Sprintf("%d or more %s", n.Min, sub.many)
+    case n.Min == n.Max:
+        return fmt.Sprintf("exactly %d %s", n.Min, sub.many)
+    }
+    return fmt.Sprintf("%d to %d %s", n.Min, n.Max, sub.many)
+}
+
+func (e *explainer) repeat(lead string, n *Repeat) {
+    mode := " (as many as possible)"
+    switch {
+    case n.Mode == Possessive:
+        mode = " (as many as possible, without giving any back)"
+    case (n.Mode == Lazy) != e.ungreedy:
+        mode = " (as few as possible)"
+    }
+    if n.Min == n.Max || n.Min == 0 && n.Max == 1 {
+        mode = ""
+    }
+
+    sub := n.Sub
+    if group, ok := sub.(*Group); ok && group.Kind == NonCaptureGroup {
+        sub = group.Body
+    }
+    switch sub := sub.(type) {
+    case *Literal:
+        text := fmt.Sprintf("%q", sub.Text)
+        e.line(lead, quantity(n, &noun{text, "of " + text}) + mode)
+    case *Class:
+        c := classNoun(sub)
+        e.line(lead, quantity(n, &c) + mode)
+    default:
+        e.block(lead, quantity(n, nil) + mode, sub)
+    }
+}
+
+func (e *explainer) group(lead string, n *Group) {
+    switch n.Kind {
+    case CaptureGroup:
+        e.groups++
+        e.block(lead, fmt.Sprintf("capture group %d containing", e.groups), n.Body)
+    case NamedCaptureGroup:
+        e.groups++
+        e.block(lead, fmt.Sprintf("named group '%s' containing", n.Name), n.Body)
+    case NonCaptureGroup:
+        e.block(lead, "group containing", n.Body)
+    case FlagGroup:
+        var on, off []string
+        state := true
+        ungreedy := e.ungreedy
+        for _, f := range n.Flags {
+            if f == '-' {
+                state = false
+                continue
+            }
+            if f == UngreedyFlag {
+                e.ungreedy = state
+            }
+            if state {
+                on = append(on, flagPhrases[f])
+            } else {
+                off = append(off, flagPhrases[f])
+            }
+        }
+        var text []string
+        if len(on) > 0 {
+            text = append(text, strings.Join(on, ", ") + " enabled")
+        }
+        if len(off) > 0 {
+            text = append(text, strings.Join(off, ", ") + " disabled")
+        }
+        e.block(lead, fmt.Sprintf("group with %s containing", strings.Join(text, " and ")), n.Body)
+        e.ungreedy = ungreedy
+    case AtomicGroup:
+        e.block(lead, "atomic group (never backtracked into) containing", n.Body)
+    case BranchResetGroup:
+        start, end := e.groups, e.groups
+        alts := []Node{n.Body}
+        if alt, ok := n.Body.(*Alternation); ok {
+            alts = alt.Alternatives
+        }
+        e.line(lead, "branch reset group (each alternative numbers its groups from the same point) containing")
+        e.depth++
+        for i, alt := range alts {
+            e.groups = start
+            if len(alts) == 1 {
+                e.sequence(alt)
+            } else if i == 0 {
+                e.block("", "either", alt)
+            } else {
+                e.block("", "or", alt)
+            }
+            if e.groups > end {
+                end = e.groups
+            }
+        }
+        e.depth--
+        e.groups = end
+    case PosLookahead:
+        e.block(lead, "followed by", n.Body)
+    case NegLookahead:
+        e.block(lead, "not followed by", n.Body)
+    case PosLookbehind:
+        e.block(lead, "preceded by", n.Body)
+    case NegLookbehind:
+        e.block(lead, "not preceded by", n.Body)
+    case ConditionalGroup:
+        yes, no := conditionalBranches(n)
+        e.block(lead, "if " + groupPhrase(n.Num, n.Name) + " has captured text", yes)
+        if no != nil {
+            e.block("", "otherwise", no)
+        }
+    case DefineGroup:
+        e.block(lead, "definitions (never matched directly) of", n.Body)
+    }
+}
+
+func (e *explainer) assertion(n *Assertion) string {
+    switch n.Kind {
+    case AssertLineStart:
+        if e.flags[MultilineFlag] {
+            return "start of line"
+        }
+        return "start of text"
+    case AssertLineEnd:
+        if e.flags[MultilineFlag] {
+            return "end of line"
+        }
+        return "end of text"
+    case AssertNonWordBoundary:
+        return "not a word boundary"
+    case AssertTextEndNewline:
+        return "end of text, or before a newline at the end of text"
+    }
+    return methodPhrases[assertionMethods[n.Kind].method]
+}
+
+// Explain returns an indented description of every segment of the regex, in the
+// order they are matched. Nested segments are indented under the group containing them
+func (r *RejexBuilder) Explain() string {
+    e := explainer{flags: r.flags, ungreedy: r.flags[UngreedyFlag]}
+    root := r.Tree()
+    if concat, ok := root.(*Concat); ok && len(concat.Nodes) == 0 {
+        e.line("", "empty string")
+    } else {
+        e.sequence(root)
+    }
+
+    if len(r.options) > 0 {
+        e.line("", "with start options: " + strings.Join(r.options, ", "))
+    }
+    var flags []string
+    for _, f := range setFlags(r.flags) {
+        flags = append(flags, flagPhrases[f])
+    }
+    if len(flags) > 0 {
+        e.line("", "with flags: " + strings.Join(flags, ", "))
+    }
+    return strings.TrimSuffix(e.String(), "\n")
+}
@@ -0,0 +1,113 @@
+package rejex
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestExplain(t *testing.T) {
+    tests := []struct {
+        flavor RejexFlavor
+        pattern string
+        want []string
+    }{
+        {GoFlavor, `^(?P<year>\d{4})-[A-Z]{2,5}?$`, []string{
+            `start of text`,
+            `then named group 'year' containing`,
+            `    exactly 4 digits`,
+            `then "-"`,
+            `then 2 to 5 uppercase letters (as few as possible)`,
+            `then end of text`,
+        }},
+        {GoFlavor, `(?m)^a|b*$`, []string{
+            `either`,
+            `    start of line`,
+            `    then "a"`,
+            `or`,
+            `    zero or more of "b" (as many as possible)`,
+            `    then end of line`,
+            `with flags: multiline`,
+        }},
+        {GoFlavor, `(?i:ab)(?-i:c)x+?`, []string{
+            `group with case insensitive enabled containing`,
+            `    "ab"`,
+            `then group with case insensitive disabled containing`,
+            `    "c"`,
+            `then one or more of "x" (as few as possible)`,
+        }},
+        {GoFlavor, `[^a-c\s]\b\B\z`, []string{
+            `a character not from "a" to "c" or whitespace characters`,
+            `then word boundary`,
+            `then not a word boundary`,
+            `then end of text`,
+        }},
+        {GoFlavor, ``, []string{
+            `empty string`,
+        }},
+        {PCREFlavor, `(?>a+)(?|(b)|(c)(d))\1`, []string{
+            `atomic group (never backtracked into) containing`,
+            `    one or more of "a" (as many as possible)`,
+            `then branch reset group (each alternative numbers its groups from the same point) containing`,
+            `    either`,
+            `        capture group 1 containing`,
+            `            "b"`,
+            `    or`,
+            `        capture group 1 containing`,
+            `            "c"`,
+            `        then capture group 2 containing`,
+            `            "d"`,
+            `then the text captured by group 1`,
+        }},
+        {PCREFlavor, `(?<=x)(?!y)(a)?(?(1)b|c)`, []string{
+            `preceded by`,
+            `    "x"`,
+            `then not followed by`,
+            `    "y"`,
+            `then optionally`,
+            `    capture group 1 containing`,
+            `        "a"`,
+            `then if group 1 has captured text`,
+            `    "b"`,
+            `otherwise`,
+            `    "c"`,
+        }},
+        {PCREFlavor, `(?(DEFINE)(?<d>\d))(?&d)(?R)?`, []string{
+            `definitions (never matched directly) of`,
+            `    named group 'd' containing`,
+            `        a digit`,
+            `then the pattern of group 'd' again`,
+            `then optionally`,
+            `    the whole pattern again, recursively`,
+        }},
+        {PCREFlavor, `(*UTF)(?U)a+`, []string{
+            `one or more of "a" (as few as possible)`,
+            `with start options: UTF`,
+            `with flags: ungreedy`,
+        }},
+    }
+    for _, test := range tests {
+        got := fromString(test.flavor, test.pattern).Explain()
+        if want := strings.Join(test.want, "\n"); got != want {
+            t.Errorf("%s is explained as\n%s\nwant\n%s", test.pattern, got, want)
+        }
+    }
+}
+
+// TestExplainChain checks that patterns built by the chain are explained like the
+// patterns parsed from a string
+func TestExplainChain(t *testing.T) {
+    got := NewRejex().
+        Starting().
+        BeginNamedCaptureGroup("year").
+            AnyDigit().
+            NOf("", 4).
+        EndGroup().
+        Characters("-").
+        AnyUppercase().
+        NToMOf("", 2, 5).
+        PreferFewer().
+        Explain()
+    if want := fromString(GoFlavor, `^(?P<year>\d{4})-[A-Z]{2,5}?`).Explain(); got != want {
+        t.Errorf("chain is explained as\n%s\nwant\n%s", got, want)
+    }
+}
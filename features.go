@@ -0,0 +1,207 @@
+package rejex
+
+import (
+    "fmt"
+    "strings"
+)
+
+//go:generate go run ./cmd/rejex features -output FEATURES.md
+
+// Features describes the constructs a flavor supports, as reported by its Supports,
+// MaxRepeat and Flags methods
+type Features struct {
+    Flavor string
+
+    Lookahead bool
+    Lookbehind bool
+    VariableLookbehind bool
+    // LookbehindAlternatives is a lookbehind whose alternatives have different lengths
+    LookbehindAlternatives bool
+    AtomicGroups bool
+    BranchResetGroups bool
+    PossessiveQuantifiers bool
+    Backrefs bool
+    NamedBackrefs bool
+    RelativeBackrefs bool
+    UnicodeClasses bool
+    InlineFlags bool
+    AbsoluteAnchors bool
+    // NewlineEndAnchor is \Z
+    NewlineEndAnchor bool
+    // LastMatchEnd is \G
+    LastMatchEnd bool
+    // Graphemes is \X
+    Graphemes bool
+    Quotes bool
+    ControlChars bool
+    Recursion bool
+    Conditionals bool
+    // MatchStartResets is \K
+    MatchStartResets bool
+    BacktrackingVerbs bool
+    StartOptions bool
+    // Linebreaks is \R
+    Linebreaks bool
+
+    // MaxRepeat is the largest count of a repetition, 0 if there is no limit
+    MaxRepeat int
+    Flags []RejexFlag
+}
+
+// FeaturesOf returns the constructs supported by a flavor
+func FeaturesOf(flavor RejexFlavor) Features {
+    return Features{
+        Flavor: flavor.Name(),
+        Lookahead: flavor.Supports(LookaheadFeature),
+        Lookbehind: flavor.Supports(LookbehindFeature),
+        VariableLookbehind: flavor.Supports(VariableLookbehindFeature),
+        LookbehindAlternatives: flavor.Supports(LookbehindAlternativesFeature),
+        AtomicGroups: flavor.Supports(AtomicGroupFeature),
+        BranchResetGroups: flavor.Supports(BranchResetFeature),
+        PossessiveQuantifiers: flavor.Supports(PossessiveFeature),
+        Backrefs: flavor.Supports(BackrefFeature),
+        NamedBackrefs: flavor.Supports(NamedBackrefFeature),
+        RelativeBackrefs: flavor.Supports(RelativeBackrefFeature),
+        UnicodeClasses: flavor.Supports(UnicodeClassFeature),
+        InlineFlags: flavor.Supports(GroupFlagsFeature),
+        AbsoluteAnchors: flavor.Supports(AbsoluteAnchorFeature),
+        NewlineEndAnchor: flavor.Supports(NewlineEndAnchorFeature),
+        LastMatchEnd: flavor.Supports(LastMatchEndFeature),
+        Graphemes: flavor.Supports(GraphemeFeature),
+        Quotes: flavor.Supports(QuoteFeature),
+        ControlChars: flavor.Supports(ControlCharFeature),
+        Recursion: flavor.Supports(RecursionFeature),
+        Conditionals: flavor.Supports(ConditionalFeature),
+        MatchStartResets: flavor.Supports(MatchStartFeature),
+        BacktrackingVerbs: flavor.Supports(VerbFeature),
+        StartOptions: flavor.Supports(StartOptionFeature),
+        Linebreaks: flavor.Supports(LinebreakFeature),
+        MaxRepeat: flavor.MaxRepeat(),
+        Flags: setFlags(allFlags(flavor)),
+    }
+}
+
+// allFlags returns the flags of a flavor all set
+func allFlags(flavor RejexFlavor) map[RejexFlag]bool {
+    flags := map[RejexFlag]bool{}
+    for f := range flavor.Flags() {
+        flags[f] = true
+    }
+    return flags
+}
+
+// methodFeatures holds the chain methods which construct a feature, they are part of the
+// interface of the flavors supporting it only
+var methodFeatures = map[string]Feature{
+    "Literally": QuoteFeature,
+    "ControlChar": ControlCharFeature,
+    "AbsoluteStarting": AbsoluteAnchorFeature,
+    "AbsoluteEnding": AbsoluteAnchorFeature,
+    "EndOfLastMatch": LastMatchEndFeature,
+    "PossessiveQuantifier": PossessiveFeature,
+    "CapturedPatternByNum": BackrefFeature,
+    "CapturedPatternByName": NamedBackrefFeature,
+    "BeginGroupWithFlags": GroupFlagsFeature,
+    "BeginPosLookahead": LookaheadFeature,
+    "BeginNegLookahead": LookaheadFeature,
+    "BeginPosLookbehind": LookbehindFeature,
+    "BeginNegLookbehind": LookbehindFeature,
+    "BeginAtomicGroup": AtomicGroupFeature,
+    "BeginBranchResetGroup": BranchResetFeature,
+    "AnyUnicodeGrapheme": GraphemeFeature,
+    "AnyUnicodeLetter": UnicodeClassFeature,
+    "AnyUnicodeUppercase": UnicodeClassFeature,
+    "AnyUnicodeLowercase": UnicodeClassFeature,
+    "AnyUnicodeWhitespace": UnicodeClassFeature,
+    "AnyUnicodeSymbol": UnicodeClassFeature,
+    "AnyUnicodeNumber": UnicodeClassFeature,
+    "AnyUnicodePunctuation": UnicodeClassFeature,
+    "UnicodeClass": UnicodeClassFeature,
+    "Recurse": RecursionFeature,
+    "SubroutineByNum": RecursionFeature,
+    "SubroutineByName": RecursionFeature,
+    "BeginConditionalGroupByNum": ConditionalFeature,
+    "BeginConditionalGroupByName": ConditionalFeature,
+    "BeginDefineGroup": RecursionFeature,
+    "ResetMatchStart": MatchStartFeature,
+    "BacktrackingVerb": VerbFeature,
+    "AddStartOptions": StartOptionFeature,
+    "AnyLinebreak": LinebreakFeature,
+}
+
+// featureRows holds the features in the order of the compatibility table along with
+// their syntax
+var featureRows = []struct {
+    feature Feature
+    syntax string
+}{
+    {LookaheadFeature, "`(?=...)` `(?!...)`"},
+    {LookbehindFeature, "`(?<=...)` `(?<!...)`"},
+    {VariableLookbehindFeature, "`(?<=a+)`"},
+    {LookbehindAlternativesFeature, "`(?<=ab\\|c)`"},
+    {AtomicGroupFeature, "`(?>...)`"},
+    {BranchResetFeature, "`(?\\|...)`"},
+    {PossessiveFeature, "`a++`"},
+    {BackrefFeature, "`\\1`"},
+    {NamedBackrefFeature, "`\\k<name>`"},
+    {RelativeBackrefFeature, "`\\g{-1}`"},
+    {UnicodeClassFeature, "`\\p{L}`"},
+    {GroupFlagsFeature, "`(?i:...)`"},
+    {AbsoluteAnchorFeature, "`\\A` `\\z`"},
+    {NewlineEndAnchorFeature, "`\\Z`"},
+    {LastMatchEndFeature, "`\\G`"},
+    {GraphemeFeature, "`\\X`"},
+    {QuoteFeature, "`\\Q...\\E`"},
+    {ControlCharFeature, "`\\cA`"},
+    {RecursionFeature, "`(?R)` `(?1)` `(?&name)`"},
+    {ConditionalFeature, "`(?(1)...\\|...)`"},
+    {MatchStartFeature, "`\\K`"},
+    {VerbFeature, "`(*SKIP)(*FAIL)`"},
+    {StartOptionFeature, "`(*UTF)`"},
+    {LinebreakFeature, "`\\R`"},
+}
+
+// FeatureTable returns a markdown table of the constructs supported by each of the
+// flavors, all the registered flavors by default
+func FeatureTable(flavors ...RejexFlavor) string {
+    if len(flavors) == 0 {
+        flavors = Flavors()
+    }
+
+    var b strings.Builder
+    b.WriteString("| Feature | Syntax |")
+    for _, flavor := range flavors {
+        fmt.Fprintf(&b, " %s |", flavor.Name())
+    }
+    b.WriteString("\n| --- | --- |")
+    b.WriteString(strings.Repeat(" :-: |", len(flavors)))
+    b.WriteString("\n")
+
+    row := func(name, syntax string, cell func(RejexFlavor) string) {
+        fmt.Fprintf(&b, "| %s | %s |", name, syntax)
+        for _, flavor := range flavors {
+            fmt.Fprintf(&b, " %s |", cell(flavor))
+        }
+        b.WriteString("\n")
+    }
+    for _, r := range featureRows {
+        f := r.feature
+        name := strings.ToUpper(string(f[:1])) + string(f[1:])
+        row(name, r.syntax, func(flavor RejexFlavor) string {
+            if flavor.Supports(f) {
+                return "yes"
+            }
+            return "no"
+        })
+    }
+    row("Max repetition count", "`a{n}`", func(flavor RejexFlavor) string {
+        if flavor.MaxRepeat() == 0 {
+            return "unlimited"
+        }
+        return fmt.Sprint(flavor.MaxRepeat())
+    })
+    row("Flags", "", func(flavor RejexFlavor) string {
+        return string(FeaturesOf(flavor).Flags)
+    })
+    return b.String()
+}
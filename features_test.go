@@ -0,0 +1,109 @@
+package rejex
+
+import (
+    "os"
+    "reflect"
+    "testing"
+)
+
+// TestFlavorInterfaces checks that the interface of each flavor has the methods which
+// construct a feature exactly when the flavor supports it
+func TestFlavorInterfaces(t *testing.T) {
+    for flavor, methods := range flavorInterfaces {
+        for method, f := range methodFeatures {
+            _, ok := methods.MethodByName(method)
+            if ok != flavor.Supports(f) {
+                t.Errorf("%s() is part of the interface of the %s flavor: %v, %s are supported: %v",
+                    method, flavor.Name(), ok, f, flavor.Supports(f))
+            }
+        }
+    }
+}
+
+func TestFeaturesOf(t *testing.T) {
+    got := FeaturesOf(PerlFlavor)
+    if !got.AtomicGroups || !got.LastMatchEnd || got.VariableLookbehind || got.MaxRepeat != perlMaxRepeat {
+        t.Errorf("unexpected features of the PERL flavor: %+v", got)
+    }
+    if want := []RejexFlag("gims"); !reflect.DeepEqual(got.Flags, want) {
+        t.Errorf("flags of the PERL flavor are %q, want %q", got.Flags, want)
+    }
+    if got := FeaturesOf(GoFlavor); got.Lookahead || got.MaxRepeat != maxRepeat {
+        t.Errorf("unexpected features of the GO flavor: %+v", got)
+    }
+}
+
+// TestFeatureTable checks that the generated compatibility table is up to date
+func TestFeatureTable(t *testing.T) {
+    b, err := os.ReadFile("FEATURES.md")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if string(b) != FeatureTable() {
+        t.Error("FEATURES.md is out of date, run go generate")
+    }
+}
+
+func TestFlavorLimits(t *testing.T) {
+    tests := []struct {
+        flavor RejexFlavor
+        pattern string
+        valid bool
+    }{
+        {GoFlavor, `a{1000}`, true},
+        {GoFlavor, `a{1001}`, false},
+        {ECMAFlavor, `a{100000}`, true},
+        {PerlFlavor, `a{65535}`, false},
+        {ECMAFlavor, `(?<=a+)b`, true},
+        {PerlFlavor, `(?<=a+)b`, false},
+        {PerlFlavor, `(?<=ab|cd)e`, true},
+        {PerlFlavor, `(?<=ab|c)e`, false},
+        {PerlFlavor, `(?<=a{3}(?=b))c`, true},
+        {PCREFlavor, `(?<=ab|c)e`, true},
+        {PCREFlavor, `(?<=a(?:b|cd))e`, false},
+        {PCREFlavor, `(*UTF)(a)(?(1)b|c)(?1)`, true},
+        {PCREFlavor, `(a)(?(1)b|c|d)`, false},
+        {PCREFlavor, `(?2)(a)`, false},
+        {PCREFlavor, `(?=a\K)`, false},
+        {PCREFlavor, `a(*UTF)`, false},
+        {PerlFlavor, `a(*SKIP)(*FAIL)|b`, false},
+        {PythonFlavor, `(?<=ab|cd)e`, true},
+        {PythonFlavor, `(?<=ab|c)e`, false},
+        {PythonFlavor, `(?P<n>a)(?P=n)\Z`, true},
+        {PythonFlavor, `(?<n>a)\k<n>`, false},
+        {PythonFlavor, `(?(1)a|b)`, false},
+        {PythonFlavor, `(?ax)a # comment`, true},
+        {PythonFlavor, `(?aL)a`, false},
+        {PythonFlavor, `(?au)\d`, false},
+        {PythonFlavor, `(?a:\d)(?u:\w)`, true},
+        {PythonFlavor, `(?au:\d)`, false},
+        {PythonFlavor, `(?L)(?u:\w)`, false},
+        {PythonFlavor, `(?L:\w)`, false},
+        {PythonFlavor, `(?L)(?a:\w)(?L:\d)`, true},
+        {PythonFlavor, `a(?i)b`, false},
+    }
+    for _, test := range tests {
+        if _, errs := fromString(test.flavor, test.pattern).Build(); failed(errs) == test.valid {
+            t.Errorf("%s in the %s flavor: %v", test.pattern, test.flavor.Name(), errs)
+        }
+    }
+}
+
+func TestBuildCompile(t *testing.T) {
+    tests := []struct {
+        pattern string
+        want string
+        valid bool
+    }{
+        {`(?im)"a b"\Z`, `re.compile(r"\"a b\"\Z", re.I | re.M)`, true},
+        {`(?L)\w+`, `re.compile(rb"\w+", re.L)`, true},
+        {`(?L)é`, `re.compile(rb"é", re.L)`, false},
+        {`(?L)[a-é]`, `re.compile(rb"[a-é]", re.L)`, false},
+    }
+    for _, test := range tests {
+        got, errs := fromString(PythonFlavor, test.pattern).BuildCompile()
+        if got != test.want || failed(errs) == test.valid {
+            t.Errorf("%s is compiled as %s %v, want %s", test.pattern, got, errs, test.want)
+        }
+    }
+}
@@ -15,8 +15,46 @@ const (
     StickyFlag RejexFlag = 'y'
     UnicodeFlag RejexFlag = 'u'
     GlobalFlag RejexFlag = 'g'
+    VerboseFlag RejexFlag = 'x'
+    ASCIIFlag RejexFlag = 'a'
+    // LocaleFlag makes the classes depend on the locale, Python only allows it for
+    // patterns of bytes so patterns with it can only hold ASCII characters
+    LocaleFlag RejexFlag = 'L'
 )
 
+// flagNames holds the names of the flag constants
+var flagNames = map[RejexFlag]string{
+    CaseInsensitiveFlag: "CaseInsensitiveFlag",
+    MultilineFlag: "MultilineFlag",
+    SingleLineFlag: "SingleLineFlag",
+    UngreedyFlag: "UngreedyFlag",
+    StickyFlag: "StickyFlag",
+    UnicodeFlag: "UnicodeFlag",
+    GlobalFlag: "GlobalFlag",
+    VerboseFlag: "VerboseFlag",
+    ASCIIFlag: "ASCIIFlag",
+    LocaleFlag: "LocaleFlag",
+}
+
+// charsetFlags are the flags selecting the character set the classes are matched in,
+// which are exclusive and cannot be turned off in a group
+var charsetFlags = []RejexFlag{ASCIIFlag, LocaleFlag, UnicodeFlag}
+
+// flagState returns whether a flag is in effect after flags like those of `(?i-m)`, which
+// turn on the flags before the '-' and off those after it
+func flagState(flags []RejexFlag, f RejexFlag, set bool) bool {
+    on := true
+    for _, c := range flags {
+        switch c {
+        case '-':
+            on = false
+        case f:
+            set = on
+        }
+    }
+    return set
+}
+
 func (r *RejexBuilder) changeFlags(f []RejexFlag, state bool) *RejexBuilder {
     if len(f) == 0 {
         r.addError("No flags provided")
@@ -38,5 +76,22 @@ func (r *RejexBuilder) AddFlags(f ...RejexFlag) *RejexBuilder {
 
 // RemoveFlags removes the provided flags from the regex
 func (r *RejexBuilder) RemoveFlags(f ...RejexFlag) *RejexBuilder {
-    return r.changeFlags(f, true)
+    return r.changeFlags(f, false)
+}
+
+// AddStartOptions adds the provided start options to the regex, such as "UTF" which is
+// written as `(*UTF)` at the very start of the regex. Options taking a number are
+// written as "LIMIT_MATCH=1000"
+func (r *RejexBuilder) AddStartOptions(opts ...string) *RejexBuilder {
+    if len(opts) == 0 {
+        r.addError("No start options provided")
+    }
+    for _, opt := range opts {
+        if validStartOption(opt) {
+            r.options = append(r.options, opt)
+        } else {
+            r.addError(fmt.Sprintf("Invalid start option '%s'", opt))
+        }
+    }
+    return r
 }
@@ -1,13 +1,155 @@
 package rejex
 
-type RejexFlavor string
+import (
+    "fmt"
+    "io"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// RejexFlavor is a syntax which patterns are rendered in. Each constructs which differs
+// between the flavors is rendered by the flavor, the rest of the syntax is shared. The
+// built-in flavors are registered with RegisterFlavor like any other, a new dialect can
+// embed one of them and only override what differs
+type RejexFlavor interface {
+    // Name identifies the flavor, such as "GO". Names are unique among registered flavors
+    Name() string
+    // Base returns the built-in flavor whose syntax is used to parse patterns of the
+    // flavor and whose semantics are used to match them. The built-in flavors return
+    // themselves
+    Base() RejexFlavor
+    // Flags returns the flags available in the flavor along with their default state,
+    // the map should not be modified
+    Flags() map[RejexFlag]bool
+    // Supports reports whether the flavor supports a construct
+    Supports(Feature) bool
+    // MaxRepeat returns the largest count of a repetition, or 0 if there is no limit
+    MaxRepeat() int
+
+    // RenderLiteral returns the syntax of a character matched literally, inside of a
+    // selection set if inClass is set, escaping it when needed
+    RenderLiteral(c rune, inClass bool) string
+    // RenderClass returns the syntax of a class item other than a range of characters,
+    // such as `\d` for a DigitItem
+    RenderClass(item ClassItem) string
+    // RenderGroup returns the syntax opening a group, which is closed by ')'
+    RenderGroup(g *Group) string
+    // RenderAssertion returns the syntax of an assertion
+    RenderAssertion(kind AssertionKind) string
+    // RenderBackref returns the syntax of a backreference
+    RenderBackref(ref *Backref) string
+    // RenderFlags returns the syntax written before and after a pattern to set its flags.
+    // unicode is set when the pattern contains unicode classes or escaped characters
+    // outside of the basic multilingual plane
+    RenderFlags(flags []RejexFlag, unicode bool) (prefix, suffix string)
+}
+
+var (
+    GoFlavor RejexFlavor = goFlavor{}
+    ECMAFlavor RejexFlavor = ecmaFlavor{}
+    PerlFlavor RejexFlavor = perlFlavor{}
+    PCREFlavor RejexFlavor = pcreFlavor{}
+    PythonFlavor RejexFlavor = pythonFlavor{}
+)
+
+var registry = struct {
+    sync.RWMutex
+    flavors map[string]RejexFlavor
+}{flavors: map[string]RejexFlavor{}}
+
+func init() {
+    for _, flavor := range []RejexFlavor{GoFlavor, ECMAFlavor, PerlFlavor, PCREFlavor, PythonFlavor} {
+        if err := RegisterFlavor(flavor); err != nil {
+            panic(err)
+        }
+    }
+}
+
+// RegisterFlavor makes a flavor available by its name to LookupFlavor, Load and the
+// commands. Names are case insensitive and cannot be registered twice
+func RegisterFlavor(flavor RejexFlavor) error {
+    name := strings.ToUpper(flavor.Name())
+    if name == "" {
+        return fmt.Errorf("Flavor has no name")
+    }
+    if flavor.Base() == nil {
+        return fmt.Errorf("Flavor '%s' has no base flavor", flavor.Name())
+    }
+
+    registry.Lock()
+    defer registry.Unlock()
+    if _, ok := registry.flavors[name]; ok {
+        return fmt.Errorf("Flavor '%s' is already registered", flavor.Name())
+    }
+    registry.flavors[name] = flavor
+    return nil
+}
+
+// LookupFlavor returns the registered flavor with the provided name, ignoring case
+func LookupFlavor(name string) (RejexFlavor, bool) {
+    registry.RLock()
+    defer registry.RUnlock()
+    flavor, ok := registry.flavors[strings.ToUpper(name)]
+    return flavor, ok
+}
+
+// Flavors returns the registered flavors sorted by name
+func Flavors() []RejexFlavor {
+    registry.RLock()
+    defer registry.RUnlock()
+    var flavors []RejexFlavor
+    for _, flavor := range registry.flavors {
+        flavors = append(flavors, flavor)
+    }
+    sort.Slice(flavors, func(i, j int) bool { return flavors[i].Name() < flavors[j].Name() })
+    return flavors
+}
+
+// Feature is a construct which is not supported by every flavor
+type Feature string
 
 const (
-    GoFlavor RejexFlavor = "GO"
-    ECMAFlavor RejexFlavor = "ECMA"
-    PerlFlavor RejexFlavor = "PERL"
+    LookaheadFeature Feature = "lookaheads"
+    LookbehindFeature Feature = "lookbehinds"
+    VariableLookbehindFeature Feature = "variable length lookbehinds"
+    AtomicGroupFeature Feature = "atomic groups"
+    BranchResetFeature Feature = "branch reset groups"
+    GroupFlagsFeature Feature = "inline flags"
+    PossessiveFeature Feature = "possessive quantifiers"
+    BackrefFeature Feature = "backreferences"
+    NamedBackrefFeature Feature = "named backreferences"
+    RelativeBackrefFeature Feature = "relative backreferences"
+    AbsoluteAnchorFeature Feature = "absolute anchors"
+    NewlineEndAnchorFeature Feature = "end of text before newline anchors"
+    LastMatchEndFeature Feature = "end of last match anchors"
+    GraphemeFeature Feature = "unicode graphemes"
+    UnicodeClassFeature Feature = "unicode classes"
+    QuoteFeature Feature = "literal quotes"
+    ControlCharFeature Feature = "control character escapes"
+    LookbehindAlternativesFeature Feature = "lookbehind alternatives of different lengths"
+    RecursionFeature Feature = "recursion and subroutine calls"
+    ConditionalFeature Feature = "conditional groups"
+    MatchStartFeature Feature = "match start resets"
+    VerbFeature Feature = "backtracking control verbs"
+    StartOptionFeature Feature = "start options"
+    LinebreakFeature Feature = "linebreak escapes"
 )
 
+// perlSyntax reports whether patterns of a flavor are parsed and matched like those of
+// Perl, which PCRE follows
+func perlSyntax(flavor RejexFlavor) bool {
+    base := flavor.Base()
+    return base == PerlFlavor || base == PCREFlavor
+}
+
+// unsupported returns the error message for a feature the flavor does not support
+func unsupported(f Feature, flavor RejexFlavor) string {
+    name := string(f)
+    return fmt.Sprintf("%s are not supported by the %s flavor", strings.ToUpper(name[:1])+name[1:], flavor.Name())
+}
+
 var goFlavorFlags = map[RejexFlag]bool{
     'i': false, // Case Insensitive
     'm': false, // Multiline
@@ -15,163 +157,199 @@ var goFlavorFlags = map[RejexFlag]bool{
     'U': false, // Ungreedy
 }
 
-// GoFlavorInterface represents regex of the Go standard syntax
+// GoFlavorInterface represents regex of the Go standard syntax, implemented by GoRejex
 type GoFlavorInterface interface {
     Build() (string, []RejexError)
+    BuildFor(RejexFlavor) (string, []RejexError)
+    Compile() (*regexp.Regexp, error)
+    MustCompile() *regexp.Regexp
+    Tree() Node
+    Explain() string
+    Examples(int, int64) []string
+    CounterExamples(int) []CounterExample
+    Matches(string) (bool, error)
+    RenderRailroad(io.Writer) error
+    ExportDOT(AutomatonKind) (string, error)
+    Optimize() *GoRejex
+    Marshal() ([]byte, error)
+    Builder() *RejexBuilder
+    Errors() []RejexError
 
     // General
-    Not() *RejexBuilder
-    Characters(string) *RejexBuilder
-    EscapedCharacters(string) *RejexBuilder
-    AnyChar() *RejexBuilder
-    Literally(string) *RejexBuilder
+    Not() *GoRejex
+    Characters(string) *GoRejex
+    EscapedCharacters(string) *GoRejex
+    AnyChar() *GoRejex
+    Literally(string) *GoRejex
+    Pattern(Fragment) *GoRejex
+    NumberRange(int64, int64, ...NumberRangeOptions) *GoRejex
+    AnyOfWords([]string, ...WordsOptions) *GoRejex
 
     // Anchors
-    Starting() *RejexBuilder
-    AbsoluteStarting() *RejexBuilder
-    Ending() *RejexBuilder
-    AbsoluteEnding() *RejexBuilder
-    WordBoundary() *RejexBuilder
+    Starting() *GoRejex
+    AbsoluteStarting() *GoRejex
+    Ending() *GoRejex
+    AbsoluteEnding() *GoRejex
+    WordBoundary() *GoRejex
 
     // Quantifiers
-    ZeroOrOneOf(string) *RejexBuilder
-    ZeroOrMoreOf(string) *RejexBuilder
-    OneOrMoreOf(string) *RejexBuilder
-    NOf(string, int) *RejexBuilder
-    NOrMoreOf(string, int) *RejexBuilder
-    NToMOf(string, int, int) *RejexBuilder
+    ZeroOrOneOf(string) *GoRejex
+    ZeroOrMoreOf(string) *GoRejex
+    OneOrMoreOf(string) *GoRejex
+    NOf(string, int) *GoRejex
+    NOrMoreOf(string, int) *GoRejex
+    NToMOf(string, int, int) *GoRejex
 
     // Meta
-    PreferFewer() *RejexBuilder
-    Or() *RejexBuilder
-    EitherOr(...string) *RejexBuilder
+    PreferFewer() *GoRejex
+    Or() *GoRejex
+    EitherOr(...string) *GoRejex
 
     // Group Constructs
-    BeginCaptureGroup() *RejexBuilder
-    BeginNamedCaptureGroup(string) *RejexBuilder
-    BeginNonCaptureGroup() *RejexBuilder
-    BeginGroupWithFlags([]RejexFlag) *RejexBuilder
-    EndGroup() *RejexBuilder
-    BeginSelectionSet() *RejexBuilder
-    BeginNonSelectionSet() *RejexBuilder
-    EndSelectionSet() *RejexBuilder
+    BeginCaptureGroup() *GoRejex
+    BeginNamedCaptureGroup(string) *GoRejex
+    BeginNonCaptureGroup() *GoRejex
+    BeginGroupWithFlags([]RejexFlag) *GoRejex
+    EndGroup() *GoRejex
+    BeginSelectionSet() *GoRejex
+    BeginNonSelectionSet() *GoRejex
+    EndSelectionSet() *GoRejex
 
     // Char Classes
-    AnyFrom(string) *RejexBuilder
-    AnyFromCharRange(string, string) *RejexBuilder
-    AnyWhitespace() *RejexBuilder
-    AnyWordChar() *RejexBuilder
-    AnyDigit() *RejexBuilder
-    AnyLetter() *RejexBuilder
-    AnyUppercase() *RejexBuilder
-    AnyLowercase() *RejexBuilder
-    AnyAlNumChar() *RejexBuilder
-    AnyPunctuation() *RejexBuilder
-    AnyGraphicChar() *RejexBuilder
-    AnyASCIIChar() *RejexBuilder
-    AnyControlChar() *RejexBuilder
-    AnyUnicodeLetter() *RejexBuilder
-    AnyUnicodeUppercase() *RejexBuilder
-    AnyUnicodeLowercase() *RejexBuilder
-    AnyUnicodeWhitespace() *RejexBuilder
-    AnyUnicodeSymbol() *RejexBuilder
-    AnyUnicodeNumber() *RejexBuilder
-    AnyUnicodePunctuation() *RejexBuilder
-    UnicodeClass(string) *RejexBuilder
-    OctalChar(int) *RejexBuilder
-    HexChar(string) *RejexBuilder
+    AnyFrom(string) *GoRejex
+    AnyFromCharRange(string, string) *GoRejex
+    AnyWhitespace() *GoRejex
+    AnyWordChar() *GoRejex
+    AnyDigit() *GoRejex
+    AnyLetter() *GoRejex
+    AnyUppercase() *GoRejex
+    AnyLowercase() *GoRejex
+    AnyAlNumChar() *GoRejex
+    AnyPunctuation() *GoRejex
+    AnyGraphicChar() *GoRejex
+    AnyASCIIChar() *GoRejex
+    AnyControlChar() *GoRejex
+    AnyUnicodeLetter() *GoRejex
+    AnyUnicodeUppercase() *GoRejex
+    AnyUnicodeLowercase() *GoRejex
+    AnyUnicodeWhitespace() *GoRejex
+    AnyUnicodeSymbol() *GoRejex
+    AnyUnicodeNumber() *GoRejex
+    AnyUnicodePunctuation() *GoRejex
+    UnicodeClass(string) *GoRejex
+    OctalChar(int) *GoRejex
+    HexChar(string) *GoRejex
 
     // Flags
-    AddFlags(...RejexFlag) *RejexBuilder
-    RemoveFlags(...RejexFlag) *RejexBuilder
+    AddFlags(...RejexFlag) *GoRejex
+    RemoveFlags(...RejexFlag) *GoRejex
 
     // Utils
-    LineEnding() *RejexBuilder
+    LineEnding() *GoRejex
 }
 
 var ecmaFlavorFlags = map[RejexFlag]bool{
     'g': false, // Global
     'i': false, // Case Insensitive
     'm': false, // Multiline
+    's': false, // Dot All
     'y': false, // Sticky
     'u': false, // Unicode
 }
 
-// ECMAFlavorInterface represents regex of the ECMAScript standard syntax
+// ECMAFlavorInterface represents regex of the ECMAScript standard syntax, implemented by
+// ECMARejex
 type ECMAFlavorInterface interface {
     Build() (string, []RejexError)
+    BuildFor(RejexFlavor) (string, []RejexError)
+    Tree() Node
+    Explain() string
+    Examples(int, int64) []string
+    CounterExamples(int) []CounterExample
+    Matches(string) (bool, error)
+    RenderRailroad(io.Writer) error
+    ExportDOT(AutomatonKind) (string, error)
+    Optimize() *ECMARejex
+    Marshal() ([]byte, error)
+    Builder() *RejexBuilder
+    Errors() []RejexError
+    AnalyzeReDoS() ReDoSReport
+    WarnReDoS() *ECMARejex
 
     // General
-    Not() *RejexBuilder
-    Characters(string) *RejexBuilder
-    EscapedCharacters(string) *RejexBuilder
-    AnyChar() *RejexBuilder
+    Not() *ECMARejex
+    Characters(string) *ECMARejex
+    EscapedCharacters(string) *ECMARejex
+    AnyChar() *ECMARejex
+    Pattern(Fragment) *ECMARejex
+    NumberRange(int64, int64, ...NumberRangeOptions) *ECMARejex
+    AnyOfWords([]string, ...WordsOptions) *ECMARejex
 
     // Anchors
-    Starting() *RejexBuilder
-    Ending() *RejexBuilder
-    WordBoundary() *RejexBuilder
+    Starting() *ECMARejex
+    Ending() *ECMARejex
+    WordBoundary() *ECMARejex
 
     // Quantifiers
-    ZeroOrOneOf(string) *RejexBuilder
-    ZeroOrMoreOf(string) *RejexBuilder
-    OneOrMoreOf(string) *RejexBuilder
-    NOf(string, int) *RejexBuilder
-    NOrMoreOf(string, int) *RejexBuilder
-    NToMOf(string, int, int) *RejexBuilder
+    ZeroOrOneOf(string) *ECMARejex
+    ZeroOrMoreOf(string) *ECMARejex
+    OneOrMoreOf(string) *ECMARejex
+    NOf(string, int) *ECMARejex
+    NOrMoreOf(string, int) *ECMARejex
+    NToMOf(string, int, int) *ECMARejex
 
     // Meta
-    PreferFewer() *RejexBuilder
-    Or() *RejexBuilder
-    EitherOr(...string) *RejexBuilder
-    CapturedPatternByNum(int) *RejexBuilder
-    CapturedPatternByName(string) *RejexBuilder
+    PreferFewer() *ECMARejex
+    Or() *ECMARejex
+    EitherOr(...string) *ECMARejex
+    CapturedPatternByNum(int) *ECMARejex
+    CapturedPatternByName(string) *ECMARejex
 
     // Group Constructs
-    BeginCaptureGroup() *RejexBuilder
-    BeginNamedCaptureGroup(string) *RejexBuilder
-    BeginNonCaptureGroup() *RejexBuilder
-    BeginPosLookahead() *RejexBuilder
-    BeginNegLookahead() *RejexBuilder
-    BeginPosLookbehind() *RejexBuilder
-    BeginNegLookbehind() *RejexBuilder
-    EndGroup() *RejexBuilder
-    BeginSelectionSet() *RejexBuilder
-    BeginNonSelectionSet() *RejexBuilder
-    EndSelectionSet() *RejexBuilder
+    BeginCaptureGroup() *ECMARejex
+    BeginNamedCaptureGroup(string) *ECMARejex
+    BeginNonCaptureGroup() *ECMARejex
+    BeginPosLookahead() *ECMARejex
+    BeginNegLookahead() *ECMARejex
+    BeginPosLookbehind() *ECMARejex
+    BeginNegLookbehind() *ECMARejex
+    EndGroup() *ECMARejex
+    BeginSelectionSet() *ECMARejex
+    BeginNonSelectionSet() *ECMARejex
+    EndSelectionSet() *ECMARejex
 
     // Char Classes
-    AnyFrom(string) *RejexBuilder
-    AnyFromCharRange(string, string) *RejexBuilder
-    AnyWhitespace() *RejexBuilder
-    AnyWordChar() *RejexBuilder
-    AnyDigit() *RejexBuilder
-    AnyLetter() *RejexBuilder
-    AnyUppercase() *RejexBuilder
-    AnyLowercase() *RejexBuilder
-    AnyAlNumChar() *RejexBuilder
-    AnyPunctuation() *RejexBuilder
-    AnyGraphicChar() *RejexBuilder
-    AnyASCIIChar() *RejexBuilder
-    AnyControlChar() *RejexBuilder
-    AnyUnicodeLetter() *RejexBuilder
-    AnyUnicodeUppercase() *RejexBuilder
-    AnyUnicodeLowercase() *RejexBuilder
-    AnyUnicodeWhitespace() *RejexBuilder
-    AnyUnicodeSymbol() *RejexBuilder
-    AnyUnicodeNumber() *RejexBuilder
-    AnyUnicodePunctuation() *RejexBuilder
-    UnicodeClass(string) *RejexBuilder
-    OctalChar(int) *RejexBuilder
-    HexChar(string) *RejexBuilder
-    ControlChar(string) *RejexBuilder
+    AnyFrom(string) *ECMARejex
+    AnyFromCharRange(string, string) *ECMARejex
+    AnyWhitespace() *ECMARejex
+    AnyWordChar() *ECMARejex
+    AnyDigit() *ECMARejex
+    AnyLetter() *ECMARejex
+    AnyUppercase() *ECMARejex
+    AnyLowercase() *ECMARejex
+    AnyAlNumChar() *ECMARejex
+    AnyPunctuation() *ECMARejex
+    AnyGraphicChar() *ECMARejex
+    AnyASCIIChar() *ECMARejex
+    AnyControlChar() *ECMARejex
+    AnyUnicodeLetter() *ECMARejex
+    AnyUnicodeUppercase() *ECMARejex
+    AnyUnicodeLowercase() *ECMARejex
+    AnyUnicodeWhitespace() *ECMARejex
+    AnyUnicodeSymbol() *ECMARejex
+    AnyUnicodeNumber() *ECMARejex
+    AnyUnicodePunctuation() *ECMARejex
+    UnicodeClass(string) *ECMARejex
+    OctalChar(int) *ECMARejex
+    HexChar(string) *ECMARejex
+    ControlChar(string) *ECMARejex
 
     // Flags
-    AddFlags(...RejexFlag) *RejexBuilder
-    RemoveFlags(...RejexFlag) *RejexBuilder
+    AddFlags(...RejexFlag) *ECMARejex
+    RemoveFlags(...RejexFlag) *ECMARejex
 
     // Utils
-    LineEnding() *RejexBuilder
+    LineEnding() *ECMARejex
 }
 
 var perlFlavorFlags = map[RejexFlag]bool{
@@ -181,93 +359,340 @@ var perlFlavorFlags = map[RejexFlag]bool{
     's': false, // Single Line
 }
 
-// PerlFlavorInterface represents regex of the Perl standard syntax
+// PerlFlavorInterface represents regex of the Perl standard syntax, implemented by PerlRejex
 type PerlFlavorInterface interface {
     // There's a bunch of features missing here like recursion, conditionals,
     // subroutines etc. but like who tf needs these?? and why??? why is there
     // programming logic in regular expressions????
+    // (they are part of PCREFlavorInterface)
+
+    Build() (string, []RejexError)
+    BuildFor(RejexFlavor) (string, []RejexError)
+    Tree() Node
+    Explain() string
+    Examples(int, int64) []string
+    CounterExamples(int) []CounterExample
+    Matches(string) (bool, error)
+    RenderRailroad(io.Writer) error
+    ExportDOT(AutomatonKind) (string, error)
+    Optimize() *PerlRejex
+    Marshal() ([]byte, error)
+    Builder() *RejexBuilder
+    Errors() []RejexError
+    AnalyzeReDoS() ReDoSReport
+    WarnReDoS() *PerlRejex
+
+    // General
+    Not() *PerlRejex
+    Characters(string) *PerlRejex
+    EscapedCharacters(string) *PerlRejex
+    AnyChar() *PerlRejex
+    Literally(string) *PerlRejex
+    Pattern(Fragment) *PerlRejex
+    NumberRange(int64, int64, ...NumberRangeOptions) *PerlRejex
+    AnyOfWords([]string, ...WordsOptions) *PerlRejex
+
+    // Anchors
+    Starting() *PerlRejex
+    AbsoluteStarting() *PerlRejex
+    Ending() *PerlRejex
+    AbsoluteEnding() *PerlRejex
+    WordBoundary() *PerlRejex
+    EndOfLastMatch() *PerlRejex
+    // AbsoluteEndingWithNewline() *PerlRejex // "\Z"
+
+    // Quantifiers
+    ZeroOrOneOf(string) *PerlRejex
+    ZeroOrMoreOf(string) *PerlRejex
+    OneOrMoreOf(string) *PerlRejex
+    NOf(string, int) *PerlRejex
+    NOrMoreOf(string, int) *PerlRejex
+    NToMOf(string, int, int) *PerlRejex
+
+    // Meta
+    PreferFewer() *PerlRejex
+    PossessiveQuantifier() *PerlRejex
+    Or() *PerlRejex
+    EitherOr(...string) *PerlRejex
+    CapturedPatternByNum(int) *PerlRejex
+    CapturedPatternByName(string) *PerlRejex
+
+    // Group Constructs
+    BeginCaptureGroup() *PerlRejex
+    BeginNamedCaptureGroup(string) *PerlRejex
+    BeginNonCaptureGroup() *PerlRejex
+    BeginGroupWithFlags([]RejexFlag) *PerlRejex
+    BeginPosLookahead() *PerlRejex
+    BeginNegLookahead() *PerlRejex
+    BeginPosLookbehind() *PerlRejex
+    BeginNegLookbehind() *PerlRejex
+    BeginAtomicGroup() *PerlRejex
+    BeginBranchResetGroup() *PerlRejex
+    EndGroup() *PerlRejex
+    BeginSelectionSet() *PerlRejex
+    BeginNonSelectionSet() *PerlRejex
+    EndSelectionSet() *PerlRejex
+
+    // Char Classes
+    AnyFrom(string) *PerlRejex
+    AnyFromCharRange(string, string) *PerlRejex
+    AnyWhitespace() *PerlRejex
+    AnyWordChar() *PerlRejex
+    AnyDigit() *PerlRejex
+    AnyLetter() *PerlRejex
+    AnyUppercase() *PerlRejex
+    AnyLowercase() *PerlRejex
+    AnyAlNumChar() *PerlRejex
+    AnyPunctuation() *PerlRejex
+    AnyGraphicChar() *PerlRejex
+    AnyASCIIChar() *PerlRejex
+    AnyControlChar() *PerlRejex
+    AnyUnicodeGrapheme() *PerlRejex
+    AnyUnicodeLetter() *PerlRejex
+    AnyUnicodeUppercase() *PerlRejex
+    AnyUnicodeLowercase() *PerlRejex
+    AnyUnicodeWhitespace() *PerlRejex
+    AnyUnicodeSymbol() *PerlRejex
+    AnyUnicodeNumber() *PerlRejex
+    AnyUnicodePunctuation() *PerlRejex
+    UnicodeClass(string) *PerlRejex
+    OctalChar(int) *PerlRejex
+    HexChar(string) *PerlRejex
+    ControlChar(string) *PerlRejex
+
+    // Flags
+    AddFlags(...RejexFlag) *PerlRejex
+    RemoveFlags(...RejexFlag) *PerlRejex
+
+    // Utils
+    LineEnding() *PerlRejex
+}
+
+var pcreFlavorFlags = map[RejexFlag]bool{
+    'i': false, // Case Insensitive
+    'm': false, // Multiline
+    's': false, // Single Line
+    'U': false, // Ungreedy
+}
+
+// PCREFlavorInterface represents regex of the PCRE2 syntax used by grep -P, nginx and PHP,
+// implemented by PCRERejex
+type PCREFlavorInterface interface {
+    Build() (string, []RejexError)
+    BuildFor(RejexFlavor) (string, []RejexError)
+    Tree() Node
+    Explain() string
+    Examples(int, int64) []string
+    CounterExamples(int) []CounterExample
+    Matches(string) (bool, error)
+    RenderRailroad(io.Writer) error
+    ExportDOT(AutomatonKind) (string, error)
+    Optimize() *PCRERejex
+    Marshal() ([]byte, error)
+    Builder() *RejexBuilder
+    Errors() []RejexError
+    AnalyzeReDoS() ReDoSReport
+    WarnReDoS() *PCRERejex
+
+    // General
+    Not() *PCRERejex
+    Characters(string) *PCRERejex
+    EscapedCharacters(string) *PCRERejex
+    AnyChar() *PCRERejex
+    Literally(string) *PCRERejex
+    Pattern(Fragment) *PCRERejex
+    NumberRange(int64, int64, ...NumberRangeOptions) *PCRERejex
+    AnyOfWords([]string, ...WordsOptions) *PCRERejex
+
+    // Anchors
+    Starting() *PCRERejex
+    AbsoluteStarting() *PCRERejex
+    Ending() *PCRERejex
+    AbsoluteEnding() *PCRERejex
+    WordBoundary() *PCRERejex
+    EndOfLastMatch() *PCRERejex
+    ResetMatchStart() *PCRERejex
+
+    // Quantifiers
+    ZeroOrOneOf(string) *PCRERejex
+    ZeroOrMoreOf(string) *PCRERejex
+    OneOrMoreOf(string) *PCRERejex
+    NOf(string, int) *PCRERejex
+    NOrMoreOf(string, int) *PCRERejex
+    NToMOf(string, int, int) *PCRERejex
+
+    // Meta
+    PreferFewer() *PCRERejex
+    PossessiveQuantifier() *PCRERejex
+    Or() *PCRERejex
+    EitherOr(...string) *PCRERejex
+    CapturedPatternByNum(int) *PCRERejex
+    CapturedPatternByName(string) *PCRERejex
+    Recurse() *PCRERejex
+    SubroutineByNum(int) *PCRERejex
+    SubroutineByName(string) *PCRERejex
+    BacktrackingVerb(string) *PCRERejex
+
+    // Group Constructs
+    BeginCaptureGroup() *PCRERejex
+    BeginNamedCaptureGroup(string) *PCRERejex
+    BeginNonCaptureGroup() *PCRERejex
+    BeginGroupWithFlags([]RejexFlag) *PCRERejex
+    BeginPosLookahead() *PCRERejex
+    BeginNegLookahead() *PCRERejex
+    BeginPosLookbehind() *PCRERejex
+    BeginNegLookbehind() *PCRERejex
+    BeginAtomicGroup() *PCRERejex
+    BeginBranchResetGroup() *PCRERejex
+    BeginConditionalGroupByNum(int) *PCRERejex
+    BeginConditionalGroupByName(string) *PCRERejex
+    BeginDefineGroup() *PCRERejex
+    EndGroup() *PCRERejex
+    BeginSelectionSet() *PCRERejex
+    BeginNonSelectionSet() *PCRERejex
+    EndSelectionSet() *PCRERejex
+
+    // Char Classes
+    AnyFrom(string) *PCRERejex
+    AnyFromCharRange(string, string) *PCRERejex
+    AnyWhitespace() *PCRERejex
+    AnyWordChar() *PCRERejex
+    AnyDigit() *PCRERejex
+    AnyLetter() *PCRERejex
+    AnyUppercase() *PCRERejex
+    AnyLowercase() *PCRERejex
+    AnyAlNumChar() *PCRERejex
+    AnyPunctuation() *PCRERejex
+    AnyGraphicChar() *PCRERejex
+    AnyASCIIChar() *PCRERejex
+    AnyControlChar() *PCRERejex
+    AnyLinebreak() *PCRERejex
+    AnyUnicodeGrapheme() *PCRERejex
+    AnyUnicodeLetter() *PCRERejex
+    AnyUnicodeUppercase() *PCRERejex
+    AnyUnicodeLowercase() *PCRERejex
+    AnyUnicodeWhitespace() *PCRERejex
+    AnyUnicodeSymbol() *PCRERejex
+    AnyUnicodeNumber() *PCRERejex
+    AnyUnicodePunctuation() *PCRERejex
+    UnicodeClass(string) *PCRERejex
+    OctalChar(int) *PCRERejex
+    HexChar(string) *PCRERejex
+    ControlChar(string) *PCRERejex
+
+    // Flags
+    AddFlags(...RejexFlag) *PCRERejex
+    RemoveFlags(...RejexFlag) *PCRERejex
+    AddStartOptions(...string) *PCRERejex
+
+    // Utils
+    LineEnding() *PCRERejex
+}
+
+var pythonFlavorFlags = map[RejexFlag]bool{
+    'i': false, // Case Insensitive
+    'm': false, // Multiline
+    's': false, // Dot All
+    'x': false, // Verbose
+    'a': false, // ASCII
+    'L': false, // Locale
+    'u': false, // Unicode
+}
 
+// PythonFlavorInterface represents regex of the syntax of the re module of Python,
+// implemented by PythonRejex
+type PythonFlavorInterface interface {
     Build() (string, []RejexError)
+    BuildFor(RejexFlavor) (string, []RejexError)
+    BuildCompile() (string, []RejexError)
+    Tree() Node
+    Explain() string
+    Examples(int, int64) []string
+    CounterExamples(int) []CounterExample
+    Matches(string) (bool, error)
+    RenderRailroad(io.Writer) error
+    ExportDOT(AutomatonKind) (string, error)
+    Optimize() *PythonRejex
+    Marshal() ([]byte, error)
+    Builder() *RejexBuilder
+    Errors() []RejexError
+    AnalyzeReDoS() ReDoSReport
+    WarnReDoS() *PythonRejex
 
     // General
-    Not() *RejexBuilder
-    Characters(string) *RejexBuilder
-    EscapedCharacters(string) *RejexBuilder
-    AnyChar() *RejexBuilder
+    Not() *PythonRejex
+    Characters(string) *PythonRejex
+    EscapedCharacters(string) *PythonRejex
+    AnyChar() *PythonRejex
+    Pattern(Fragment) *PythonRejex
+    NumberRange(int64, int64, ...NumberRangeOptions) *PythonRejex
+    AnyOfWords([]string, ...WordsOptions) *PythonRejex
 
     // Anchors
-    Starting() *RejexBuilder
-    AbsoluteStarting() *RejexBuilder
-    Ending() *RejexBuilder
-    AbsoluteEnding() *RejexBuilder
-    WordBoundary() *RejexBuilder
-    EndOfLastMatch() *RejexBuilder
-    // AbsoluteEndingWithNewline() *RejexBuilder // "\Z"
+    Starting() *PythonRejex
+    AbsoluteStarting() *PythonRejex
+    Ending() *PythonRejex
+    AbsoluteEnding() *PythonRejex
+    WordBoundary() *PythonRejex
 
     // Quantifiers
-    ZeroOrOneOf(string) *RejexBuilder
-    ZeroOrMoreOf(string) *RejexBuilder
-    OneOrMoreOf(string) *RejexBuilder
-    NOf(string, int) *RejexBuilder
-    NOrMoreOf(string, int) *RejexBuilder
-    NToMOf(string, int, int) *RejexBuilder
+    ZeroOrOneOf(string) *PythonRejex
+    ZeroOrMoreOf(string) *PythonRejex
+    OneOrMoreOf(string) *PythonRejex
+    NOf(string, int) *PythonRejex
+    NOrMoreOf(string, int) *PythonRejex
+    NToMOf(string, int, int) *PythonRejex
 
     // Meta
-    PreferFewer() *RejexBuilder
-    PossessiveQuantifier() *RejexBuilder
-    Or() *RejexBuilder
-    EitherOr(...string) *RejexBuilder
-    CapturedPatternByNum(int) *RejexBuilder
-    CapturedPatternByName(string) *RejexBuilder
+    PreferFewer() *PythonRejex
+    PossessiveQuantifier() *PythonRejex
+    Or() *PythonRejex
+    EitherOr(...string) *PythonRejex
+    CapturedPatternByNum(int) *PythonRejex
+    CapturedPatternByName(string) *PythonRejex
 
     // Group Constructs
-    BeginCaptureGroup() *RejexBuilder
-    BeginNamedCaptureGroup(string) *RejexBuilder
-    BeginNonCaptureGroup() *RejexBuilder
-    BeginGroupWithFlags([]RejexFlag) *RejexBuilder
-    BeginPosLookahead() *RejexBuilder
-    BeginNegLookahead() *RejexBuilder
-    BeginPosLookbehind() *RejexBuilder
-    BeginNegLookbehind() *RejexBuilder
-    BeginAtomicGroup() *RejexBuilder
-    BeginBranchResetGroup() *RejexBuilder
-    EndGroup() *RejexBuilder
-    BeginSelectionSet() *RejexBuilder
-    BeginNonSelectionSet() *RejexBuilder
-    EndSelectionSet() *RejexBuilder
+    BeginCaptureGroup() *PythonRejex
+    BeginNamedCaptureGroup(string) *PythonRejex
+    BeginNonCaptureGroup() *PythonRejex
+    BeginGroupWithFlags([]RejexFlag) *PythonRejex
+    BeginPosLookahead() *PythonRejex
+    BeginNegLookahead() *PythonRejex
+    BeginPosLookbehind() *PythonRejex
+    BeginNegLookbehind() *PythonRejex
+    BeginAtomicGroup() *PythonRejex
+    BeginConditionalGroupByNum(int) *PythonRejex
+    BeginConditionalGroupByName(string) *PythonRejex
+    EndGroup() *PythonRejex
+    BeginSelectionSet() *PythonRejex
+    BeginNonSelectionSet() *PythonRejex
+    EndSelectionSet() *PythonRejex
 
     // Char Classes
-    AnyFrom(string) *RejexBuilder
-    AnyFromCharRange(string, string) *RejexBuilder
-    AnyWhitespace() *RejexBuilder
-    AnyWordChar() *RejexBuilder
-    AnyDigit() *RejexBuilder
-    AnyLetter() *RejexBuilder
-    AnyUppercase() *RejexBuilder
-    AnyLowercase() *RejexBuilder
-    AnyAlNumChar() *RejexBuilder
-    AnyPunctuation() *RejexBuilder
-    AnyGraphicChar() *RejexBuilder
-    AnyASCIIChar() *RejexBuilder
-    AnyControlChar() *RejexBuilder
-    AnyUnicodeGrapheme() *RejexBuilder
-    AnyUnicodeLetter() *RejexBuilder
-    AnyUnicodeUppercase() *RejexBuilder
-    AnyUnicodeLowercase() *RejexBuilder
-    AnyUnicodeWhitespace() *RejexBuilder
-    AnyUnicodeSymbol() *RejexBuilder
-    AnyUnicodeNumber() *RejexBuilder
-    AnyUnicodePunctuation() *RejexBuilder
-    UnicodeClass(string) *RejexBuilder
-    OctalChar(int) *RejexBuilder
-    HexChar(string) *RejexBuilder
+    // The re module has no unicode classes, \w, \d and \s match unicode characters
+    // unless the ASCII flag is set
+    AnyFrom(string) *PythonRejex
+    AnyFromCharRange(string, string) *PythonRejex
+    AnyWhitespace() *PythonRejex
+    AnyWordChar() *PythonRejex
+    AnyDigit() *PythonRejex
+    AnyLetter() *PythonRejex
+    AnyUppercase() *PythonRejex
+    AnyLowercase() *PythonRejex
+    AnyAlNumChar() *PythonRejex
+    AnyPunctuation() *PythonRejex
+    AnyGraphicChar() *PythonRejex
+    AnyASCIIChar() *PythonRejex
+    AnyControlChar() *PythonRejex
+    OctalChar(int) *PythonRejex
+    HexChar(string) *PythonRejex
 
     // Flags
-    AddFlags(...RejexFlag) *RejexBuilder
-    RemoveFlags(...RejexFlag) *RejexBuilder
+    AddFlags(...RejexFlag) *PythonRejex
+    RemoveFlags(...RejexFlag) *PythonRejex
 
     // Utils
-    LineEnding() *RejexBuilder
+    LineEnding() *PythonRejex
 }
 
 // EgrepFlavorInterface represents regex of ERE syntax used by GNU egrep (or with grep -E)
@@ -0,0 +1,103 @@
+package rejex
+
+import (
+    "strings"
+    "testing"
+)
+
+// goDialect is a flavor registered outside of the builtin ones which is based on Go
+type goDialect struct {
+    goFlavor
+}
+
+func (goDialect) Name() string { return "GODIALECT" }
+
+// unnamed is a flavor which cannot be registered
+type unnamed struct {
+    goFlavor
+}
+
+func (unnamed) Name() string { return "" }
+
+var dialect RejexFlavor = goDialect{}
+
+func TestRegisterFlavor(t *testing.T) {
+    if err := RegisterFlavor(dialect); err != nil {
+        t.Fatal(err)
+    }
+    // the registry is shared by the other tests, such as of the feature table
+    t.Cleanup(func() {
+        registry.Lock()
+        delete(registry.flavors, dialect.Name())
+        registry.Unlock()
+    })
+    if found, ok := LookupFlavor("GoDialect"); !ok || found != dialect {
+        t.Errorf("registered flavor is not found")
+    }
+
+    for _, flavor := range []RejexFlavor{GoFlavor, PCREFlavor, dialect} {
+        if err := RegisterFlavor(flavor); err == nil {
+            t.Errorf("%s is registered twice", flavor.Name())
+        }
+    }
+    if err := RegisterFlavor(unnamed{}); err == nil {
+        t.Errorf("flavor without a name is registered")
+    }
+    if _, ok := LookupFlavor(""); ok {
+        t.Errorf("flavor without a name is found")
+    }
+}
+
+func TestLookupFlavor(t *testing.T) {
+    for _, flavor := range Flavors() {
+        for _, name := range []string{flavor.Name(), strings.ToLower(flavor.Name())} {
+            if found, ok := LookupFlavor(name); !ok || found != flavor {
+                t.Errorf("%s finds %v, want %s", name, found, flavor.Name())
+            }
+        }
+    }
+    if _, ok := LookupFlavor("unknown"); ok {
+        t.Errorf("unregistered flavor is found")
+    }
+
+    flavors := Flavors()
+    for i := 1; i < len(flavors); i++ {
+        if flavors[i-1].Name() >= flavors[i].Name() {
+            t.Errorf("flavors are not sorted: %s before %s", flavors[i-1].Name(), flavors[i].Name())
+        }
+    }
+    if len(flavors) != 5 {
+        t.Errorf("%d flavors are registered, want the 5 builtin ones", len(flavors))
+    }
+}
+
+// TestGoDialect checks that flavors based on Go are validated and matched like Go
+func TestGoDialect(t *testing.T) {
+    tests := []struct {
+        pattern string
+        match string
+        noMatch string
+    }{
+        {`^(a|b)+c$`, "abac", "abc!"},
+        {`(?i)x\d{2,3}`, "-X123-", "x1"},
+        {`(a+)+$`, "baaa", "aab"},
+    }
+    for _, test := range tests {
+        r := NewFromString(dialect, test.pattern).WarnReDoS()
+        pattern, errs := r.Build()
+        want, _ := NewFromString(GoFlavor, test.pattern).Build()
+        if len(errs) > 0 || pattern != want {
+            t.Errorf("%s is built as %s with %v, want %s", test.pattern, pattern, errs, want)
+        }
+        if ok, err := r.Matches(test.match); !ok || err != nil {
+            t.Errorf("%s does not match %q: %v", test.pattern, test.match, err)
+        }
+        if ok, err := r.Matches(test.noMatch); ok || err != nil {
+            t.Errorf("%s matches %q: %v", test.pattern, test.noMatch, err)
+        }
+    }
+
+    if _, errs := NewFromString(dialect, `a{1001}`, true).Build(); !failed(errs) {
+        t.Errorf("repetition rejected by the regexp package is built without errors")
+    }
+}
@@ -0,0 +1,390 @@
+package rejex
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// chainCall is a call to a method of the chain along with the Go source of its arguments
+type chainCall struct {
+    method string
+    args []string
+}
+
+func (c chainCall) String() string {
+    return fmt.Sprintf("%s(%s)", c.method, strings.Join(c.args, ", "))
+}
+
+func call(method string, args ...string) chainCall {
+    return chainCall{method, args}
+}
+
+// classHelpers holds the methods which construct a class out of a fixed set of ranges,
+// the methods with more ranges are listed first
+var classHelpers = []struct {
+    method string
+    items []ClassItem
+}{
+    {"AnyPunctuation", punctuationItems},
+    {"LineEnding", lineEndingItems},
+    {"AnyAlNumChar", alNumItems},
+    {"AnyLetter", letterItems},
+    {"AnyControlChar", controlItems},
+    {"AnyUppercase", uppercaseItems},
+    {"AnyLowercase", lowercaseItems},
+    {"AnyGraphicChar", graphicItems},
+    {"AnyASCIIChar", asciiItems},
+}
+
+// shorthandMethods holds the methods which construct a class out of a single item
+var shorthandMethods = map[ClassItem]string{
+    {Kind: DigitItem}: "AnyDigit",
+    {Kind: WordItem}: "AnyWordChar",
+    {Kind: WhitespaceItem}: "AnyWhitespace",
+    {Kind: AnyCharItem}: "AnyChar",
+    {Kind: GraphemeItem}: "AnyUnicodeGrapheme",
+    {Kind: LinebreakItem}: "AnyLinebreak",
+    {Kind: UnicodeItem, Name: "L"}: "AnyUnicodeLetter",
+    {Kind: UnicodeItem, Name: "Lu"}: "AnyUnicodeUppercase",
+    {Kind: UnicodeItem, Name: "Ll"}: "AnyUnicodeLowercase",
+    {Kind: UnicodeItem, Name: "Z"}: "AnyUnicodeWhitespace",
+    {Kind: UnicodeItem, Name: "S"}: "AnyUnicodeSymbol",
+    {Kind: UnicodeItem, Name: "N"}: "AnyUnicodeNumber",
+    {Kind: UnicodeItem, Name: "P"}: "AnyUnicodePunctuation",
+}
+
+// itemCall returns the call constructing a single shorthand class item
+func itemCall(item ClassItem) chainCall {
+    item.Negated = false
+    if method, ok := shorthandMethods[item]; ok {
+        return call(method)
+    }
+    return call("UnicodeClass", strconv.Quote(item.Name))
+}
+
+// takeItems reports whether a list of items starts with the provided items, and returns
+// the items following them
+func takeItems(items, take []ClassItem) ([]ClassItem, bool) {
+    if len(items) < len(take) {
+        return items, false
+    }
+    for i, t := range take {
+        if items[i] != t {
+            return items, false
+        }
+    }
+    return items[len(take):], true
+}
+
+// classCalls returns the calls constructing a class with the most specific methods available
+func classCalls(flavor RejexFlavor, c *Class) []chainCall {
+    var calls []chainCall
+    not := func(negated bool) {
+        if negated {
+            calls = append(calls, call("Not"))
+        }
+    }
+
+    if len(c.Items) == 1 && isShorthand(c.Items[0]) {
+        not(c.Items[0].Negated != c.Negated)
+        return append(calls, itemCall(c.Items[0]))
+    }
+
+    // the calls follow the order of the items, so the class is rendered the same
+    var inner []chainCall
+    var chars []ClassItem
+    flush := func() {
+        if len(chars) == 0 {
+            return
+        }
+        w := renderer{flavor: flavor}
+        for _, item := range chars {
+            w.classItem(item)
+        }
+        if len(chars) == 1 && chars[0].Kind == RangeItem && chars[0].Lo != chars[0].Hi {
+            lo, hi := render(flavor, &Literal{string(chars[0].Lo)}), render(flavor, &Literal{string(chars[0].Hi)})
+            inner = append(inner, call("AnyFromCharRange", strconv.Quote(lo), strconv.Quote(hi)))
+        } else {
+            inner = append(inner, call("AnyFrom", strconv.Quote(w.String())))
+        }
+        chars = nil
+    }
+    rest := c.Items
+next:
+    for len(rest) > 0 {
+        for _, helper := range classHelpers {
+            var ok bool
+            if rest, ok = takeItems(rest, helper.items); ok {
+                flush()
+                inner = append(inner, call(helper.method))
+                continue next
+            }
+        }
+        if item := rest[0]; isShorthand(item) && !item.Negated {
+            flush()
+            inner = append(inner, itemCall(item))
+        } else {
+            chars = append(chars, item)
+        }
+        rest = rest[1:]
+    }
+    flush()
+
+    if len(inner) == 1 {
+        not(c.Negated)
+        return append(calls, inner...)
+    }
+    if c.Negated {
+        calls = append(calls, call("BeginNonSelectionSet"))
+    } else {
+        calls = append(calls, call("BeginSelectionSet"))
+    }
+    calls = append(calls, inner...)
+    return append(calls, call("EndSelectionSet"))
+}
+
+// quantifierCall returns the call of the quantifier method for a repeat of the provided input
+func quantifierCall(n *Repeat, s string) chainCall {
+    s = strconv.Quote(s)
+    switch {
+    case n.Min == 0 && n.Max == 1:
+        return call("ZeroOrOneOf", s)
+    case n.Min == 0 && n.Max == -1:
+        return call("ZeroOrMoreOf", s)
+    case n.Min == 1 && n.Max == -1:
+        return call("OneOrMoreOf", s)
+    case n.Max == -1:
+        return call("NOrMoreOf", s, strconv.Itoa(n.Min))
+    case n.Min == n.Max:
+        return call("NOf", s, strconv.Itoa(n.Min))
+    }
+    return call("NToMOf", s, strconv.Itoa(n.Min), strconv.Itoa(n.Max))
+}
+
+var assertionMethods = map[AssertionKind]chainCall{
+    AssertLineStart: call("Starting"),
+    AssertTextStart: call("AbsoluteStarting"),
+    AssertLineEnd: call("Ending"),
+    AssertTextEnd: call("AbsoluteEnding"),
+    AssertWordBoundary: call("WordBoundary"),
+    AssertLastMatchEnd: call("EndOfLastMatch"),
+    AssertMatchStart: call("ResetMatchStart"),
+}
+
+var groupMethods = map[GroupKind]string{
+    CaptureGroup: "BeginCaptureGroup",
+    NamedCaptureGroup: "BeginNamedCaptureGroup",
+    NonCaptureGroup: "BeginNonCaptureGroup",
+    FlagGroup: "BeginGroupWithFlags",
+    AtomicGroup: "BeginAtomicGroup",
+    BranchResetGroup: "BeginBranchResetGroup",
+    PosLookahead: "BeginPosLookahead",
+    NegLookahead: "BeginNegLookahead",
+    PosLookbehind: "BeginPosLookbehind",
+    NegLookbehind: "BeginNegLookbehind",
+    ConditionalGroup: "BeginConditionalGroupByNum",
+    DefineGroup: "BeginDefineGroup",
+}
+
+var constructors = map[RejexFlavor]string{
+    GoFlavor: "NewRejex",
+    ECMAFlavor: "NewECMARejex",
+    PerlFlavor: "NewPerlRejex",
+    PCREFlavor: "NewPCRERejex",
+    PythonFlavor: "NewPythonRejex",
+}
+
+// flagsSource returns the Go source of a list of flags
+func flagsSource(flags []RejexFlag) []string {
+    var args []string
+    for _, f := range flags {
+        if name, ok := flagNames[f]; ok {
+            args = append(args, "rejex."+name)
+        } else {
+            args = append(args, fmt.Sprintf("rejex.RejexFlag(%q)", f))
+        }
+    }
+    return args
+}
+
+// chainWriter writes the Go source of the chain of methods which constructs a syntax tree
+type chainWriter struct {
+    strings.Builder
+    flavor RejexFlavor
+    depth int
+}
+
+func (g *chainWriter) write(calls ...chainCall) {
+    g.WriteString(strings.Repeat("\t", g.depth+1))
+    for _, c := range calls {
+        g.WriteString(c.String())
+        g.WriteString(".")
+    }
+    g.WriteString("\n")
+}
+
+// sequence writes the calls for a node whose alternatives or sequence of nodes can be
+// written directly into the enclosing group
+func (g *chainWriter) sequence(n Node) {
+    switch n := n.(type) {
+    case *Alternation:
+        for i, alt := range n.Alternatives {
+            if i > 0 {
+                g.write(call("Or"))
+            }
+            g.sequence(alt)
+        }
+    case *Concat:
+        for _, sub := range n.Nodes {
+            if _, ok := sub.(*Alternation); ok {
+                g.group(&Group{Kind: NonCaptureGroup, Body: sub})
+            } else {
+                g.node(sub)
+            }
+        }
+    default:
+        g.node(n)
+    }
+}
+
+func (g *chainWriter) node(n Node) {
+    switch n := n.(type) {
+    case *Concat, *Alternation:
+        g.group(&Group{Kind: NonCaptureGroup, Body: n})
+    case *Literal:
+        if text := render(g.flavor, n); text == n.Text {
+            g.write(call("Characters", strconv.Quote(n.Text)))
+        } else {
+            g.write(call("EscapedCharacters", strconv.Quote(n.Text)))
+        }
+    case *Raw:
+        g.write(call("Characters", strconv.Quote(n.Text)))
+    case *Class:
+        g.write(classCalls(g.flavor, n)...)
+    case *Repeat:
+        g.repeat(n)
+    case *Group:
+        g.group(n)
+    case *Assertion:
+        if c, ok := assertionMethods[n.Kind]; ok {
+            g.write(c)
+        } else if n.Kind == AssertNonWordBoundary {
+            g.write(call("Not"), call("WordBoundary"))
+        } else {
+            g.write(call("Characters", strconv.Quote(render(g.flavor, n))))
+        }
+    case *Backref:
+        if n.Name != "" {
+            g.write(call("CapturedPatternByName", strconv.Quote(n.Name)))
+        } else {
+            g.write(call("CapturedPatternByNum", strconv.Itoa(n.Num)))
+        }
+    case *Recursion:
+        g.write(recursionCall(n))
+    case *Verb:
+        arg := n.Name
+        if n.Arg != "" {
+            arg += ":" + n.Arg
+        }
+        g.write(call("BacktrackingVerb", strconv.Quote(arg)))
+    }
+}
+
+// recursionCall returns the call constructing a recursion
+func recursionCall(n *Recursion) chainCall {
+    switch {
+    case n.Name != "":
+        return call("SubroutineByName", strconv.Quote(n.Name))
+    case n.Num != 0:
+        return call("SubroutineByNum", strconv.Itoa(n.Num))
+    }
+    return call("Recurse")
+}
+
+func (g *chainWriter) repeat(n *Repeat) {
+    sub := n.Sub
+    if group, ok := sub.(*Group); ok && group.Kind == NonCaptureGroup {
+        if _, ok := group.Body.(*Literal); ok {
+            sub = group.Body
+        }
+    }
+    calls := []chainCall{}
+    if lit, ok := sub.(*Literal); ok {
+        calls = append(calls, quantifierCall(n, render(g.flavor, lit)))
+    } else {
+        g.node(n.Sub)
+        calls = append(calls, quantifierCall(n, ""))
+    }
+    switch n.Mode {
+    case Lazy:
+        calls = append(calls, call("PreferFewer"))
+    case Possessive:
+        calls = append(calls, call("PossessiveQuantifier"))
+    }
+    g.write(calls...)
+}
+
+func (g *chainWriter) group(n *Group) {
+    if alt, ok := n.Body.(*Alternation); ok && n.Kind == NonCaptureGroup {
+        var options []string
+        for _, a := range alt.Alternatives {
+            if _, ok := a.(*Literal); !ok {
+                options = nil
+                break
+            }
+            options = append(options, strconv.Quote(render(g.flavor, a)))
+        }
+        if len(options) > 1 {
+            g.write(call("EitherOr", options...))
+            return
+        }
+    }
+
+    switch n.Kind {
+    case NamedCaptureGroup:
+        g.write(call(groupMethods[n.Kind], strconv.Quote(n.Name)))
+    case FlagGroup:
+        flags := fmt.Sprintf("[]rejex.RejexFlag{%s}", strings.Join(flagsSource(n.Flags), ", "))
+        g.write(call(groupMethods[n.Kind], flags))
+    case ConditionalGroup:
+        if n.Name != "" {
+            g.write(call("BeginConditionalGroupByName", strconv.Quote(n.Name)))
+        } else {
+            g.write(call(groupMethods[n.Kind], strconv.Itoa(n.Num)))
+        }
+    default:
+        g.write(call(groupMethods[n.Kind]))
+    }
+    g.depth++
+    g.sequence(n.Body)
+    g.depth--
+    g.write(call("EndGroup"))
+}
+
+// GenerateChain parses a regex string of the provided flavor and returns the Go source of
+// the chain of methods which constructs the same regex, using the most specific methods
+// available. The source is not generated if there are syntax errors in the string
+func GenerateChain(pattern string, flavor RejexFlavor) (string, []RejexError) {
+    r := createRejexBuilder(flavor, []bool{true})
+    r.appendPattern(pattern)
+    if len(r.Errors) > 0 {
+        return "", r.Errors
+    }
+
+    g := chainWriter{flavor: flavor}
+    fmt.Fprintf(&g, "rejex.%s().\n", constructors[flavor.Base()])
+    g.sequence(r.Tree())
+    if len(r.options) > 0 {
+        var options []string
+        for _, opt := range r.options {
+            options = append(options, strconv.Quote(opt))
+        }
+        g.write(call("AddStartOptions", options...))
+    }
+    if flags := setFlags(r.flags); len(flags) > 0 {
+        g.write(call("AddFlags", flagsSource(flags)...))
+    }
+    g.WriteString("\tBuild()")
+    return g.String(), nil
+}
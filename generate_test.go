@@ -0,0 +1,189 @@
+package rejex
+
+import (
+    "fmt"
+    "go/ast"
+    goparser "go/parser"
+    "go/token"
+    "reflect"
+    "strconv"
+    "testing"
+)
+
+// generated holds patterns whose chains use the more specific methods
+var generated = map[RejexFlavor][]string{
+    GoFlavor: {
+        `^(?P<y>\d{4})-[a-f0-9]+?$`,
+        `[[:alpha:]]\pL\p{Lu}[^\n]`,
+        `(?U)a+b*?`,
+        `(?i)(?:ab){2,}|c{0,3}`,
+        `\x{1F600}\t.`,
+    },
+    ECMAFlavor: {
+        `/(?<=\$)\d+(?:\.\d\d)?/gm`,
+        `/\cA[\b]\0/`,
+    },
+    PerlFlavor: {
+        `/(a)(?|(b)|(c))\g{-2}/s`,
+    },
+    PCREFlavor: {
+        `(?<n>a)\k<n>(?&n)(*SKIP)(*F)|x++`,
+        `(a)(?1)(?-1)(?R)?\K\R`,
+        `(?(1)a|b)(?(<n>)c)(?<n>d)`,
+        `(*UCP)\w`,
+    },
+    PythonFlavor: {
+        `(?P<n>a)(?P=n)(?(n)b|c)`,
+        `(?L)\w+`,
+    },
+}
+
+// evalChain evaluates the source of a chain generated by GenerateChain
+func evalChain(src string) (string, []RejexError, error) {
+    expr, err := goparser.ParseExpr(src)
+    if err != nil {
+        return "", nil, err
+    }
+    v, err := evalExpr(expr, nil)
+    if err != nil {
+        return "", nil, err
+    }
+    results := v.Interface().([]reflect.Value)
+    return results[0].String(), results[1].Interface().([]RejexError), nil
+}
+
+// evalExpr evaluates an expression of a generated chain as a value of the wanted type,
+// the results of calls returning more than one value are returned as a slice
+func evalExpr(expr ast.Expr, want reflect.Type) (reflect.Value, error) {
+    constructors := map[string]interface{}{
+        "NewRejex": NewRejex,
+        "NewECMARejex": NewECMARejex,
+        "NewPerlRejex": NewPerlRejex,
+        "NewPCRERejex": NewPCRERejex,
+        "NewPythonRejex": NewPythonRejex,
+    }
+    var v reflect.Value
+    switch expr := expr.(type) {
+    case *ast.BasicLit:
+        switch expr.Kind {
+        case token.STRING:
+            s, err := strconv.Unquote(expr.Value)
+            if err != nil {
+                return v, err
+            }
+            v = reflect.ValueOf(s)
+        case token.CHAR:
+            r, _, _, err := strconv.UnquoteChar(expr.Value[1:len(expr.Value)-1], '\'')
+            if err != nil {
+                return v, err
+            }
+            v = reflect.ValueOf(r)
+        case token.INT:
+            n, err := strconv.Atoi(expr.Value)
+            if err != nil {
+                return v, err
+            }
+            v = reflect.ValueOf(n)
+        }
+    case *ast.UnaryExpr:
+        n, err := evalExpr(expr.X, reflect.TypeOf(0))
+        if err != nil || expr.Op != token.SUB {
+            return v, fmt.Errorf("unsupported expression %v: %v", expr.Op, err)
+        }
+        v = reflect.ValueOf(-int(n.Int()))
+    case *ast.SelectorExpr:
+        if x, ok := expr.X.(*ast.Ident); ok && x.Name == "rejex" {
+            for f, name := range flagNames {
+                if name == expr.Sel.Name {
+                    v = reflect.ValueOf(f)
+                }
+            }
+        }
+    case *ast.CompositeLit:
+        v = reflect.MakeSlice(want, 0, len(expr.Elts))
+        for _, elt := range expr.Elts {
+            e, err := evalExpr(elt, want.Elem())
+            if err != nil {
+                return v, err
+            }
+            v = reflect.Append(v, e)
+        }
+    case *ast.CallExpr:
+        fun, ok := expr.Fun.(*ast.SelectorExpr)
+        if !ok {
+            return v, fmt.Errorf("unsupported call of %T", expr.Fun)
+        }
+        var fn reflect.Value
+        args := []reflect.Value{}
+        if x, ok := fun.X.(*ast.Ident); ok && x.Name == "rejex" {
+            if fun.Sel.Name == "RejexFlag" {
+                return evalExpr(expr.Args[0], reflect.TypeOf(RejexFlag(0)))
+            }
+            fn = reflect.ValueOf(constructors[fun.Sel.Name])
+            args = append(args, reflect.ValueOf(true))
+        } else {
+            recv, err := evalExpr(fun.X, nil)
+            if err != nil {
+                return v, err
+            }
+            fn = recv.MethodByName(fun.Sel.Name)
+        }
+        if !fn.IsValid() {
+            return v, fmt.Errorf("unknown function %s", fun.Sel.Name)
+        }
+        for i, arg := range expr.Args {
+            var t reflect.Type
+            if in := fn.Type().NumIn(); fn.Type().IsVariadic() && i >= in-1 {
+                t = fn.Type().In(in - 1).Elem()
+            } else {
+                t = fn.Type().In(i)
+            }
+            a, err := evalExpr(arg, t)
+            if err != nil {
+                return v, err
+            }
+            args = append(args, a)
+        }
+        results := fn.Call(args)
+        if len(results) == 1 {
+            return results[0], nil
+        }
+        return reflect.ValueOf(results), nil
+    }
+    if !v.IsValid() {
+        return v, fmt.Errorf("unsupported expression %T", expr)
+    }
+    if want != nil {
+        v = v.Convert(want)
+    }
+    return v, nil
+}
+
+// TestGenerateChain checks that the generated chains build the patterns they were
+// generated from
+func TestGenerateChain(t *testing.T) {
+    for _, tables := range []map[RejexFlavor][]string{roundTrips, generated} {
+        for flavor, patterns := range tables {
+            for _, pattern := range patterns {
+                src, errs := GenerateChain(pattern, flavor)
+                if len(errs) > 0 {
+                    t.Errorf("%s in the %s flavor is not generated: %v", pattern, flavor.Name(), errs)
+                    continue
+                }
+                got, errs, err := evalChain(src)
+                if err != nil {
+                    t.Errorf("chain generated from %s does not evaluate: %v\n%s", pattern, err, src)
+                    continue
+                }
+                want, _ := fromString(flavor, pattern).Build()
+                if failed(errs) || got != want {
+                    t.Errorf("chain generated from %s builds %s %v, want %s\n%s", pattern, got, errs, want, src)
+                }
+            }
+        }
+    }
+
+    if _, errs := GenerateChain(`a(b`, GoFlavor); len(errs) == 0 {
+        t.Errorf("chain is generated from an invalid pattern")
+    }
+}
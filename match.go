@@ -0,0 +1,660 @@
+package rejex
+
+import (
+    "fmt"
+    "unicode"
+)
+
+// maxSteps limits the work done by a matcher for a single match attempt, the matcher
+// gives up on the input after it
+const maxSteps = 1000000
+
+// matchFlags are the flags which change how a pattern matches
+type matchFlags struct {
+    foldCase, multiline, dotAll, ungreedy bool
+    // unicode is set by the unicode flag, which ECMA patterns get when they use unicode
+    // classes, and ascii by the ASCII or locale flags
+    unicode, ascii bool
+}
+
+func newMatchFlags(root Node, flags map[RejexFlag]bool) matchFlags {
+    return matchFlags{
+        foldCase: flags[CaseInsensitiveFlag],
+        multiline: flags[MultilineFlag],
+        dotAll: flags[SingleLineFlag],
+        ungreedy: flags[UngreedyFlag],
+        unicode: flags[UnicodeFlag] || usesUnicode(root),
+        ascii: flags[ASCIIFlag] || flags[LocaleFlag],
+    }
+}
+
+// with returns the flags after applying the flags of a FlagGroup
+func (f matchFlags) with(flags []RejexFlag) matchFlags {
+    state := true
+    for _, flag := range flags {
+        switch flag {
+        case '-':
+            state = false
+        case CaseInsensitiveFlag:
+            f.foldCase = state
+        case MultilineFlag:
+            f.multiline = state
+        case SingleLineFlag:
+            f.dotAll = state
+        case UngreedyFlag:
+            f.ungreedy = state
+        case ASCIIFlag, LocaleFlag:
+            f.ascii = state
+        case UnicodeFlag:
+            f.unicode, f.ascii = state, false
+        }
+    }
+    return f
+}
+
+// groupNumbers holds the numbers of the capture groups of a syntax tree and the
+// group numbers referred to by its backreferences, recursions and conditional groups.
+// Recursions into the whole pattern refer to 0 and references to groups which do not
+// exist to -1
+type groupNumbers struct {
+    groups map[*Group]int
+    names map[string]int
+    refs map[*Backref]int
+    calls map[*Recursion]int
+    conds map[*Group]int
+    // bodies holds the first group of each number, which recursions match again
+    bodies map[int]*Group
+    count int
+}
+
+func numberGroups(n Node) *groupNumbers {
+    g := &groupNumbers{
+        groups: map[*Group]int{},
+        names: map[string]int{},
+        refs: map[*Backref]int{},
+        calls: map[*Recursion]int{},
+        conds: map[*Group]int{},
+        bodies: map[int]*Group{},
+    }
+    g.walk(n)
+    for ref, num := range g.refs {
+        if ref.Name != "" {
+            g.refs[ref] = g.names[ref.Name]
+        } else if num == 0 {
+            g.refs[ref] = ref.Num
+        }
+    }
+    for call, num := range g.calls {
+        g.calls[call] = g.resolve(num, call.Num, call.Name)
+    }
+    for cond, num := range g.conds {
+        g.conds[cond] = g.resolve(num, cond.Num, cond.Name)
+    }
+    return g
+}
+
+// resolve returns the number of the group referenced by a number or a name, relative
+// numbers are resolved while walking the tree
+func (g *groupNumbers) resolve(relative, num int, name string) int {
+    switch {
+    case name != "":
+        if n, ok := g.names[name]; ok {
+            return n
+        }
+        return -1
+    case num < 0:
+        num = relative
+    }
+    if num < 0 || num > g.count {
+        return -1
+    }
+    return num
+}
+
+func (g *groupNumbers) walk(n Node) {
+    switch n := n.(type) {
+    case *Concat:
+        for _, sub := range n.Nodes {
+            g.walk(sub)
+        }
+    case *Alternation:
+        for _, alt := range n.Alternatives {
+            g.walk(alt)
+        }
+    case *Repeat:
+        g.walk(n.Sub)
+    case *Backref:
+        g.refs[n] = 0
+        if n.Num < 0 {
+            g.refs[n] = g.count + 1 + n.Num
+        }
+    case *Recursion:
+        g.calls[n] = g.count + 1 + n.Num
+    case *Group:
+        switch n.Kind {
+        case CaptureGroup, NamedCaptureGroup:
+            g.count++
+            g.groups[n] = g.count
+            if _, ok := g.bodies[g.count]; !ok {
+                g.bodies[g.count] = n
+            }
+            if _, ok := g.names[n.Name]; n.Name != "" && !ok {
+                g.names[n.Name] = g.count
+            }
+            g.walk(n.Body)
+        case BranchResetGroup:
+            alts := []Node{n.Body}
+            if alt, ok := n.Body.(*Alternation); ok {
+                alts = alt.Alternatives
+            }
+            start, end := g.count, g.count
+            for _, alt := range alts {
+                g.count = start
+                g.walk(alt)
+                if g.count > end {
+                    end = g.count
+                }
+            }
+            g.count = end
+        case ConditionalGroup:
+            g.conds[n] = g.count + 1 + n.Num
+            g.walk(n.Body)
+        default:
+            g.walk(n.Body)
+        }
+    }
+}
+
+// matcher matches a syntax tree against an input by backtracking. It follows the
+// semantics of the flavor of the tree and is used where no regex engine of the
+// flavor is available
+type matcher struct {
+    flavor RejexFlavor
+    root Node
+    flags matchFlags
+    numbers *groupNumbers
+    sets map[classKey]runeSet
+
+    // thenAlts holds the alternation whose next alternative is tried when backtracking
+    // over a (*THEN), it acts like (*PRUNE) outside of alternations
+    thenAlts map[*Verb]*Alternation
+
+    input []rune
+    caps map[int][2]int
+    steps int
+    gaveUp bool
+    // searchSteps is the work done by all the match attempts of the last search
+    searchSteps int
+    // done is the continuation of the whole match, which (*ACCEPT) ends the match with
+    done func(int) bool
+    // cut is the verb backtracked over which ends the current match attempt, the next
+    // attempt starts at skip after a (*SKIP)
+    cut *Verb
+    skip int
+    marks map[string]int
+}
+
+type classKey struct {
+    class *Class
+    flags matchFlags
+}
+
+// wordClass is the class of the characters deciding word boundaries
+var wordClass = &Class{Items: []ClassItem{{Kind: WordItem}}}
+
+func newMatcher(flavor RejexFlavor, root Node, flags map[RejexFlag]bool) *matcher {
+    return &matcher{
+        flavor: flavor,
+        root: root,
+        flags: newMatchFlags(root, flags),
+        numbers: numberGroups(root),
+        sets: map[classKey]runeSet{},
+        thenAlts: thenAlternations(root),
+    }
+}
+
+// thenAlternations returns the innermost alternation enclosing each (*THEN) of a tree.
+// The branches of a conditional group are not alternatives as only one of them is used
+func thenAlternations(root Node) map[*Verb]*Alternation {
+    alts := map[*Verb]*Alternation{}
+    var walk func(n Node, enclosing *Alternation)
+    walk = func(n Node, enclosing *Alternation) {
+        switch n := n.(type) {
+        case *Verb:
+            if n.Name == "THEN" {
+                alts[n] = enclosing
+            }
+        case *Concat:
+            for _, sub := range n.Nodes {
+                walk(sub, enclosing)
+            }
+        case *Alternation:
+            for _, alt := range n.Alternatives {
+                walk(alt, n)
+            }
+        case *Repeat:
+            walk(n.Sub, enclosing)
+        case *Group:
+            if n.Kind != ConditionalGroup {
+                walk(n.Body, enclosing)
+                return
+            }
+            yes, no := conditionalBranches(n)
+            walk(yes, enclosing)
+            if no != nil {
+                walk(no, enclosing)
+            }
+        }
+    }
+    walk(root, nil)
+    return alts
+}
+
+// reset prepares the matcher for a new match attempt against an input, ended by done
+func (m *matcher) reset(input []rune, done func(int) bool) {
+    m.input = input
+    m.caps = map[int][2]int{}
+    m.steps = 0
+    m.gaveUp = false
+    m.done = done
+    m.cut = nil
+    m.marks = map[string]int{}
+}
+
+// matchAt reports whether the tree matches the input starting at a position, and
+// where the match ends. The reported match is the one the flavor would find
+func (m *matcher) matchAt(input []rune, start int) (int, bool) {
+    end := -1
+    m.reset(input, func(i int) bool {
+        end = i
+        return true
+    })
+    ok := m.match(m.root, m.flags, start, m.done)
+    return end, ok
+}
+
+// fullMatch reports whether the tree matches the whole input
+func (m *matcher) fullMatch(s string) bool {
+    m.reset([]rune(s), func(i int) bool {
+        return i == len(m.input)
+    })
+    return m.match(m.root, m.flags, 0, m.done)
+}
+
+// find reports whether the tree matches anywhere in the input. The result is not
+// reliable if the matcher gave up on the input
+func (m *matcher) find(s string) bool {
+    input := []rune(s)
+    m.searchSteps = 0
+    for start := 0; start <= len(input); start++ {
+        _, ok := m.matchAt(input, start)
+        m.searchSteps += m.steps
+        if ok {
+            return true
+        }
+        if m.gaveUp {
+            return false
+        }
+        switch {
+        case m.cut == nil:
+        case m.cut.Name == "COMMIT":
+            return false
+        case m.cut.Name == "SKIP" && m.skip > start:
+            start = m.skip - 1
+        }
+    }
+    return false
+}
+
+// Matches reports whether the regex matches anywhere in the input, following the
+// semantics of the flavor of the builder. Regexes of the Go flavor are matched by the
+// regexp package, the others by a backtracking matcher. It fails if the regex has
+// errors, or if matching takes too many steps such as because of catastrophic
+// backtracking
+func (r *RejexBuilder) Matches(s string) (bool, error) {
+    if len(r.Errors) > 0 {
+        return false, &r.Errors[0]
+    }
+    if r.flavor.Base() == GoFlavor {
+        re, err := r.Compile()
+        if err != nil {
+            return false, err
+        }
+        return re.MatchString(s), nil
+    }
+    m := newMatcher(r.flavor, r.Tree(), r.flags)
+    found := m.find(s)
+    if m.gaveUp {
+        return false, fmt.Errorf("Matching %q took too many steps", s)
+    }
+    return found, nil
+}
+
+func (m *matcher) classSet(c *Class, f matchFlags) runeSet {
+    key := classKey{c, f}
+    s, ok := m.sets[key]
+    if !ok {
+        s = classSet(m.flavor, c, f)
+        m.sets[key] = s
+    }
+    return s
+}
+
+func (m *matcher) equalRunes(a, b rune, f matchFlags) bool {
+    if a == b {
+        return true
+    }
+    if !f.foldCase {
+        return false
+    }
+    for c := unicode.SimpleFold(a); c != a; c = unicode.SimpleFold(c) {
+        if c == b {
+            return true
+        }
+    }
+    return false
+}
+
+func (m *matcher) match(n Node, f matchFlags, i int, k func(int) bool) bool {
+    m.steps++
+    if m.steps > maxSteps {
+        m.gaveUp = true
+        return false
+    }
+    if m.cut != nil {
+        return false
+    }
+
+    switch n := n.(type) {
+    case *Concat:
+        return m.sequence(n.Nodes, f, i, k)
+    case *Alternation:
+        for _, alt := range n.Alternatives {
+            if m.match(alt, f, i, k) {
+                return true
+            }
+            if m.cut != nil && m.cut.Name == "THEN" && m.thenAlts[m.cut] == n {
+                m.cut = nil
+            }
+        }
+        return false
+    case *Literal:
+        for _, c := range n.Text {
+            if i >= len(m.input) || !m.equalRunes(c, m.input[i], f) {
+                return false
+            }
+            i++
+        }
+        return k(i)
+    case *Class:
+        if i >= len(m.input) || !m.classSet(n, f).contains(m.input[i]) {
+            return false
+        }
+        if len(n.Items) == 1 && n.Items[0].Kind == GraphemeItem && !n.Negated {
+            return k(m.graphemeEnd(i))
+        }
+        if len(n.Items) == 1 && n.Items[0].Kind == LinebreakItem && m.input[i] == '\r' &&
+            i+1 < len(m.input) && m.input[i+1] == '\n' {
+            return k(i + 2)
+        }
+        return k(i + 1)
+    case *Repeat:
+        if n.Mode == Possessive {
+            return m.possessive(n, f, i, k)
+        }
+        return m.repeat(n, f, i, 0, k)
+    case *Group:
+        return m.group(n, f, i, k)
+    case *Assertion:
+        return m.assertion(n, f, i) && k(i)
+    case *Backref:
+        return m.backref(n, f, i, k)
+    case *Recursion:
+        return m.recursion(n, f, i, k)
+    case *Verb:
+        return m.verb(n, i, k)
+    }
+    // Raw nodes are left by syntax errors and never match
+    return false
+}
+
+func (m *matcher) sequence(nodes []Node, f matchFlags, i int, k func(int) bool) bool {
+    if len(nodes) == 0 {
+        return k(i)
+    }
+    return m.match(nodes[0], f, i, func(j int) bool {
+        return m.sequence(nodes[1:], f, j, k)
+    })
+}
+
+// graphemeEnd returns the end of the grapheme cluster starting at a position
+func (m *matcher) graphemeEnd(i int) int {
+    if m.input[i] == '\r' && i+1 < len(m.input) && m.input[i+1] == '\n' {
+        return i + 2
+    }
+    for i++; i < len(m.input) && unicode.Is(unicode.M, m.input[i]); i++ {
+    }
+    return i
+}
+
+func (m *matcher) repeat(n *Repeat, f matchFlags, i, count int, k func(int) bool) bool {
+    more := func() bool {
+        if n.Max != -1 && count >= n.Max {
+            return false
+        }
+        return m.match(n.Sub, f, i, func(j int) bool {
+            // an iteration which matches nothing cannot lead to a different match
+            if j == i && count >= n.Min {
+                return false
+            }
+            return m.repeat(n, f, j, count+1, k)
+        })
+    }
+    if count < n.Min {
+        return more()
+    }
+    if (n.Mode == Lazy) != f.ungreedy {
+        return k(i) || more()
+    }
+    return more() || k(i)
+}
+
+func (m *matcher) possessive(n *Repeat, f matchFlags, i int, k func(int) bool) bool {
+    count := 0
+    for n.Max == -1 || count < n.Max {
+        next := -1
+        m.match(n.Sub, f, i, func(j int) bool {
+            next = j
+            return true
+        })
+        if next < 0 || next == i && count >= n.Min {
+            break
+        }
+        i = next
+        count++
+    }
+    return count >= n.Min && k(i)
+}
+
+// saveCaps returns a copy of the captured groups, to be restored when backtracking
+func (m *matcher) saveCaps() map[int][2]int {
+    caps := make(map[int][2]int, len(m.caps))
+    for num, c := range m.caps {
+        caps[num] = c
+    }
+    return caps
+}
+
+func (m *matcher) group(n *Group, f matchFlags, i int, k func(int) bool) bool {
+    switch n.Kind {
+    case CaptureGroup, NamedCaptureGroup:
+        num := m.numbers.groups[n]
+        return m.match(n.Body, f, i, func(j int) bool {
+            old, set := m.caps[num]
+            m.caps[num] = [2]int{i, j}
+            if k(j) {
+                return true
+            }
+            if set {
+                m.caps[num] = old
+            } else {
+                delete(m.caps, num)
+            }
+            return false
+        })
+    case FlagGroup:
+        return m.match(n.Body, f.with(n.Flags), i, k)
+    case AtomicGroup:
+        saved := m.saveCaps()
+        end := -1
+        if m.match(n.Body, f, i, func(j int) bool {
+            end = j
+            return true
+        }) && k(end) {
+            return true
+        }
+        m.caps = saved
+        return false
+    case PosLookahead, NegLookahead:
+        saved := m.saveCaps()
+        found := m.match(n.Body, f, i, func(int) bool {
+            return true
+        })
+        // verbs backtracked over in a lookaround only make it fail
+        m.cut = nil
+        if found == (n.Kind == PosLookahead) && k(i) {
+            return true
+        }
+        m.caps = saved
+        return false
+    case PosLookbehind, NegLookbehind:
+        saved := m.saveCaps()
+        found := false
+        for start := i; start >= 0 && !found; start-- {
+            found = m.match(n.Body, f, start, func(j int) bool {
+                return j == i
+            })
+            m.cut = nil
+        }
+        if found == (n.Kind == PosLookbehind) && k(i) {
+            return true
+        }
+        m.caps = saved
+        return false
+    case ConditionalGroup:
+        yes, no := conditionalBranches(n)
+        if _, set := m.caps[m.numbers.conds[n]]; set {
+            return m.match(yes, f, i, k)
+        }
+        if no == nil {
+            return k(i)
+        }
+        return m.match(no, f, i, k)
+    case DefineGroup:
+        return k(i)
+    }
+    return m.match(n.Body, f, i, k)
+}
+
+func (m *matcher) isWordAt(i int, f matchFlags) bool {
+    return i >= 0 && i < len(m.input) && m.classSet(wordClass, f).contains(m.input[i])
+}
+
+func (m *matcher) assertion(n *Assertion, f matchFlags, i int) bool {
+    end := len(m.input)
+    switch n.Kind {
+    case AssertLineStart:
+        return i == 0 || f.multiline && m.input[i-1] == '\n'
+    case AssertTextStart:
+        return i == 0
+    case AssertLineEnd:
+        if f.multiline {
+            return i == end || m.input[i] == '\n'
+        }
+        // Perl and Python match before a newline at the end of the text as well
+        return i == end || (perlSyntax(m.flavor) || m.flavor.Base() == PythonFlavor) && i == end-1 && m.input[i] == '\n'
+    case AssertTextEnd:
+        return i == end
+    case AssertTextEndNewline:
+        return i == end || i == end-1 && m.input[i] == '\n'
+    case AssertWordBoundary:
+        return m.isWordAt(i-1, f) != m.isWordAt(i, f)
+    case AssertNonWordBoundary:
+        return m.isWordAt(i-1, f) == m.isWordAt(i, f)
+    case AssertLastMatchEnd:
+        return i == 0
+    case AssertMatchStart:
+        return true
+    }
+    return false
+}
+
+func (m *matcher) backref(n *Backref, f matchFlags, i int, k func(int) bool) bool {
+    c, ok := m.caps[m.numbers.refs[n]]
+    if !ok {
+        // ECMA treats references to groups which did not participate as empty
+        return m.flavor.Base() == ECMAFlavor && k(i)
+    }
+    for _, r := range m.input[c[0]:c[1]] {
+        if i >= len(m.input) || !m.equalRunes(r, m.input[i], f) {
+            return false
+        }
+        i++
+    }
+    return k(i)
+}
+
+// recursion matches the pattern of a group again, the groups captured within it are
+// restored once it has matched
+func (m *matcher) recursion(n *Recursion, f matchFlags, i int, k func(int) bool) bool {
+    num := m.numbers.calls[n]
+    body, ok := Node(m.numbers.bodies[num]), num > 0
+    if num == 0 {
+        body, ok, f = m.root, true, m.flags
+    }
+    if !ok {
+        return false
+    }
+    saved := m.saveCaps()
+    return m.match(body, f, i, func(j int) bool {
+        inner := m.caps
+        m.caps = saved
+        if k(j) {
+            return true
+        }
+        m.caps = inner
+        return false
+    })
+}
+
+// verb applies a backtracking control verb. The verbs which act when backtracked over
+// end the match attempt, which find then resumes from the position they call for
+func (m *matcher) verb(n *Verb, i int, k func(int) bool) bool {
+    if n.Arg != "" && n.Name != "SKIP" {
+        m.marks[n.Arg] = i
+    }
+    switch n.Name {
+    case "FAIL":
+        return false
+    case "ACCEPT":
+        return m.done(i)
+    case "MARK":
+        return k(i)
+    }
+    if k(i) {
+        return true
+    }
+    if m.cut != nil || m.gaveUp {
+        return false
+    }
+    m.cut, m.skip = n, i
+    if n.Name == "SKIP" && n.Arg != "" {
+        // a skip to a mark which was not set is ignored
+        pos, ok := m.marks[n.Arg]
+        if !ok {
+            m.cut = nil
+        }
+        m.skip = pos
+    }
+    return false
+}
@@ -0,0 +1,217 @@
+package rejex
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// NumberRangeOptions changes how the numbers matched by NumberRange are written
+type NumberRangeOptions struct {
+    // LeadingZeros allows any number of zeros before the digits of a number
+    LeadingZeros bool
+    // Width pads numbers with fewer digits with zeros up to the width
+    Width int
+    // Fraction allows a decimal fraction after the integer part of a number, as long
+    // as the number stays within the range
+    Fraction bool
+}
+
+func decimalDigit() Node {
+    return &Class{Items: []ClassItem{charRange('0', '9')}}
+}
+
+// fraction returns the optional decimal fraction following the integer part of a number,
+// made of the provided digits
+func fraction(digit Node) Node {
+    return &Repeat{Sub: &Group{Kind: NonCaptureGroup, Body: fractionDigits(digit)}, Min: 0, Max: 1}
+}
+
+// fractionDigits returns a decimal fraction made of the provided digits
+func fractionDigits(digit Node) Node {
+    return &Concat{[]Node{&Literal{"."}, &Repeat{Sub: digit, Min: 1, Max: -1}}}
+}
+
+func pow10(k int) uint64 {
+    p := uint64(1)
+    for i := 0; i < k; i++ {
+        p *= 10
+    }
+    return p
+}
+
+// splitNumberRange splits a range of numbers into as few ranges as possible whose bounds
+// have the same number of digits, and differ in a single digit followed by only zeros
+// in the lower bound and only nines in the upper bound
+func splitNumberRange(lo, hi uint64) [][2]uint64 {
+    var ranges [][2]uint64
+    // magnitudes of int64 values have at most 19 digits
+    for digits := 1; digits <= 19; digits++ {
+        if lo > pow10(digits)-1 {
+            continue
+        }
+        end := hi
+        if end > pow10(digits)-1 {
+            end = pow10(digits) - 1
+        }
+
+        // the range is covered by the largest blocks of numbers sharing a prefix, and
+        // consecutive blocks of the same size differing in their last prefix digit are merged
+        var last, size uint64
+        for n := lo; n <= end; {
+            p := uint64(1)
+            for p <= n/10 && n%(p*10) == 0 && p*10-1 <= end-n {
+                p *= 10
+            }
+            if len(ranges) > 0 && size == p && last+1 == n && n/p%10 != 0 {
+                ranges[len(ranges)-1][1] = n + p - 1
+            } else {
+                ranges = append(ranges, [2]uint64{n, n + p - 1})
+            }
+            last, size = n+p-1, p
+            if last == end {
+                break
+            }
+            n = last + 1
+        }
+        if end == hi {
+            break
+        }
+        lo = end + 1
+    }
+    return ranges
+}
+
+// digitNodes returns the nodes matching the numbers of a range split by splitNumberRange,
+// padded with zeros up to the width
+func digitNodes(lo, hi uint64, width int) []Node {
+    from, to := strconv.FormatUint(lo, 10), strconv.FormatUint(hi, 10)
+    var nodes []Node
+    var literal string
+    if width > len(from) {
+        literal = strings.Repeat("0", width-len(from))
+    }
+    any := 0
+    for i := range from {
+        switch {
+        case from[i] == to[i]:
+            literal += from[i : i+1]
+        case from[i] == '0' && to[i] == '9':
+            any++
+        default:
+            if literal != "" {
+                nodes = append(nodes, &Literal{literal})
+                literal = ""
+            }
+            nodes = append(nodes, &Class{Items: []ClassItem{charRange(rune(from[i]), rune(to[i]))}})
+        }
+    }
+    if literal != "" {
+        nodes = append(nodes, &Literal{literal})
+    }
+    switch {
+    case any == 1:
+        nodes = append(nodes, decimalDigit())
+    case any > 1:
+        nodes = append(nodes, &Repeat{Sub: decimalDigit(), Min: any, Max: any})
+    }
+    return nodes
+}
+
+// numbers returns the alternatives matching the numbers with a magnitude in a range,
+// longest first so the whole number is matched
+func (o NumberRangeOptions) numbers(sign string, lo, hi uint64) []Node {
+    var alts []Node
+    // the bound furthest from zero can only be followed by a fraction of zeros
+    if o.Fraction {
+        alts = append(alts, o.number(sign, joinNodes(digitNodes(hi, hi, o.Width)), fraction(&Literal{"0"})))
+        if lo == hi {
+            return alts
+        }
+        hi--
+    }
+
+    ranges := splitNumberRange(lo, hi)
+    var pieces []Node
+    for i := len(ranges) - 1; i >= 0; i-- {
+        pieces = append(pieces, joinNodes(digitNodes(ranges[i][0], ranges[i][1], o.Width)))
+    }
+    if sign == "" && !o.LeadingZeros && !o.Fraction {
+        return append(alts, pieces...)
+    }
+
+    digits := pieces[0]
+    if len(pieces) > 1 {
+        digits = &Group{Kind: NonCaptureGroup, Body: &Alternation{pieces}}
+    }
+    var frac Node
+    if o.Fraction {
+        frac = fraction(decimalDigit())
+    }
+    return append(alts, o.number(sign, digits, frac))
+}
+
+// number joins the sign, leading zeros, digits and fraction of a number
+func (o NumberRangeOptions) number(sign string, digits, frac Node) Node {
+    var nodes []Node
+    if sign != "" {
+        nodes = append(nodes, &Literal{sign})
+    }
+    if o.LeadingZeros {
+        nodes = append(nodes, &Repeat{Sub: &Literal{"0"}, Min: 0, Max: -1})
+    }
+    nodes = append(nodes, digits)
+    if frac != nil {
+        nodes = append(nodes, frac)
+    }
+    return joinNodes(nodes)
+}
+
+// NumberRange matches any integer between min and max, both included, with an
+// alternation of digit classes. Negative numbers are written with a leading '-'.
+// The options allow leading zeros, pad numbers to a fixed width and allow decimal fractions
+func (r *RejexBuilder) NumberRange(min, max int64, opts ...NumberRangeOptions) *RejexBuilder {
+    var o NumberRangeOptions
+    if len(opts) > 0 {
+        o = opts[0]
+    }
+    if min > max {
+        r.addError(fmt.Sprintf("Invalid number range %d-%d", min, max))
+        return r
+    }
+    if o.Width < 0 {
+        r.addError("Number width cannot be negative")
+        return r
+    }
+
+    var alts []Node
+    if min < 0 {
+        // magnitudes are computed without overflowing for the smallest int64
+        lo, hi := uint64(1), uint64(-(min+1))+1
+        if max < 0 {
+            lo = uint64(-(max+1)) + 1
+        }
+        alts = append(alts, o.numbers("-", lo, hi)...)
+        // the negative numbers above -1 have an integer part of zero
+        if o.Fraction && max >= 0 {
+            alts = append(alts, o.number("-", joinNodes(digitNodes(0, 0, o.Width)), fractionDigits(decimalDigit())))
+        }
+    }
+    if max >= 0 {
+        lo := uint64(0)
+        if min > 0 {
+            lo = uint64(min)
+        }
+        alts = append(alts, o.numbers("", lo, uint64(max))...)
+    }
+
+    n := alts[0]
+    if len(alts) > 1 {
+        n = &Alternation{alts}
+    }
+    switch n.(type) {
+    case *Concat, *Alternation:
+        n = &Group{Kind: NonCaptureGroup, Body: n}
+    }
+    return r.appendNode(n)
+}
@@ -0,0 +1,123 @@
+package rejex
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// formatQuarter writes a number of quarters as a decimal number with the options
+func formatQuarter(q int64, o NumberRangeOptions) string {
+    sign := ""
+    if q < 0 {
+        sign, q = "-", -q
+    }
+    s := strconv.FormatInt(q/4, 10)
+    if len(s) < o.Width {
+        s = strings.Repeat("0", o.Width-len(s)) + s
+    }
+    if o.LeadingZeros {
+        s = "00" + s
+    }
+    if o.Fraction {
+        s += []string{".00", ".25", ".50", ".75"}[q%4]
+    }
+    return sign + s
+}
+
+// TestNumberRange checks every number around small ranges against the range, for each
+// of the options
+func TestNumberRange(t *testing.T) {
+    bounds := []int64{-123, -100, -99, -25, -10, -9, -1, 0, 1, 9, 10, 19, 99, 100, 123}
+    options := []NumberRangeOptions{
+        {},
+        {LeadingZeros: true},
+        {Width: 3},
+        {Fraction: true},
+        {Fraction: true, Width: 2},
+        {Fraction: true, LeadingZeros: true},
+    }
+    for _, o := range options {
+        step := int64(4)
+        if o.Fraction {
+            step = 1
+        }
+        for i, min := range bounds {
+            for _, max := range bounds[i:] {
+                pattern, errs := NewRejex().NumberRange(min, max, o).Build()
+                if len(errs) > 0 {
+                    t.Fatalf("%d-%d %+v is built with errors %v", min, max, o, errs)
+                }
+                re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+                for q := int64(-150 * 4); q <= 150*4; q += step {
+                    s := formatQuarter(q, o)
+                    if want := min*4 <= q && q <= max*4; re.MatchString(s) != want {
+                        t.Errorf("%s for %d-%d %+v matches %q: %v, want %v", pattern, min, max, o, s, !want, want)
+                    }
+                }
+            }
+        }
+    }
+}
+
+// TestNumberRangeForms checks that numbers written differently from the options are not
+// matched
+func TestNumberRangeForms(t *testing.T) {
+    tests := []struct {
+        min, max int64
+        o NumberRangeOptions
+        input string
+    }{
+        {0, 100, NumberRangeOptions{}, "007"},
+        {-5, 5, NumberRangeOptions{}, "-0"},
+        {-5, 5, NumberRangeOptions{}, "+5"},
+        {0, 100, NumberRangeOptions{Width: 3}, "7"},
+        {-5, 5, NumberRangeOptions{Fraction: true}, "-0"},
+        {-5, 5, NumberRangeOptions{Fraction: true}, "5."},
+        {-5, -1, NumberRangeOptions{Fraction: true}, "-0.5"},
+        {0, 5, NumberRangeOptions{Fraction: true}, "-0.5"},
+        {0, 5, NumberRangeOptions{}, "2.5"},
+    }
+    for _, test := range tests {
+        pattern, _ := NewRejex().NumberRange(test.min, test.max, test.o).Build()
+        if regexp.MustCompile(`^(?:` + pattern + `)$`).MatchString(test.input) {
+            t.Errorf("%s for %d-%d %+v matches %q", pattern, test.min, test.max, test.o, test.input)
+        }
+    }
+}
+
+func TestNumberRangeErrors(t *testing.T) {
+    tests := []struct {
+        min, max int64
+        o NumberRangeOptions
+    }{
+        {5, 1, NumberRangeOptions{}},
+        {0, 1, NumberRangeOptions{Width: -1}},
+    }
+    for _, test := range tests {
+        if _, errs := NewRejex(true).NumberRange(test.min, test.max, test.o).Build(); !failed(errs) {
+            t.Errorf("%d-%d %+v is built without errors", test.min, test.max, test.o)
+        }
+    }
+}
+
+// TestNumberRangeLimits checks the ranges at the limits of int64
+func TestNumberRangeLimits(t *testing.T) {
+    pattern, errs := NewRejex().NumberRange(-1<<63, 1<<63-1).Build()
+    if len(errs) > 0 {
+        t.Fatal(errs)
+    }
+    re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+    for _, n := range []string{"-9223372036854775808", "9223372036854775807", "0", "-1"} {
+        if !re.MatchString(n) {
+            t.Errorf("%s does not match %s", pattern, n)
+        }
+    }
+    for _, n := range []string{"-9223372036854775809", "9223372036854775808", fmt.Sprint(uint64(1) << 63)} {
+        if re.MatchString(n) {
+            t.Errorf("%s matches %s", pattern, n)
+        }
+    }
+}
@@ -0,0 +1,415 @@
+package rejex
+
+import "unicode/utf8"
+
+// maxRepeat is the largest repetition count accepted by the Go flavor, repetitions are
+// never merged past it in any flavor
+const maxRepeat = 1000
+
+// optimizer rewrites a syntax tree into an equivalent one which renders to a shorter
+// pattern in a flavor. The nodes of the original tree are never modified, and the
+// nodes produced in their place remember the node they were produced from
+type optimizer struct {
+    flavor RejexFlavor
+    origins map[Node]Node
+}
+
+// optimize returns the optimized syntax tree of the pattern rendered for a flavor, along
+// with the nodes of the original tree each new node was produced from. Trees with syntax
+// written verbatim are left as is
+func optimize(flavor RejexFlavor, root Node) (Node, map[Node]Node) {
+    o := optimizer{flavor: flavor, origins: map[Node]Node{}}
+    valid := true
+    walkNodes(root, func(n Node) {
+        if _, ok := n.(*Raw); ok {
+            valid = false
+        }
+    })
+    if !valid {
+        return root, o.origins
+    }
+    return o.node(root), o.origins
+}
+
+func (o *optimizer) length(n Node) int {
+    return len(render(o.flavor, n))
+}
+
+// derive records the node of the original tree a new node was produced from
+func (o *optimizer) derive(n, from Node) Node {
+    if origin, ok := o.origins[from]; ok {
+        from = origin
+    }
+    if n != from {
+        o.origins[n] = from
+    }
+    return n
+}
+
+func (o *optimizer) node(n Node) Node {
+    switch n := n.(type) {
+    case *Concat:
+        return o.concat(n)
+    case *Alternation:
+        return o.alternation(n)
+    case *Repeat:
+        return o.repeat(n)
+    case *Group:
+        if n.Kind == ConditionalGroup || n.Kind == DefineGroup {
+            return o.conditional(n)
+        }
+        body := o.node(n.Body)
+        if n.Kind == NonCaptureGroup {
+            // the renderer groups the nodes which need it
+            return body
+        }
+        return o.derive(&Group{Kind: n.Kind, Name: n.Name, Flags: n.Flags, Body: body}, n)
+    case *Class:
+        // a class of a single character is written as the character
+        if len(n.Items) == 1 && !n.Negated && n.Items[0].Kind == RangeItem && n.Items[0].Lo == n.Items[0].Hi {
+            l := &Literal{string(n.Items[0].Lo)}
+            if o.length(l) < o.length(n) {
+                return o.derive(l, n)
+            }
+        }
+    }
+    return n
+}
+
+// conditional optimizes the branches of a conditional group separately so that they
+// are not merged, a branch turning into alternatives is grouped
+func (o *optimizer) conditional(n *Group) Node {
+    branch := func(b Node) Node {
+        b = o.node(b)
+        if _, ok := b.(*Alternation); ok {
+            return &Group{Kind: NonCaptureGroup, Body: b}
+        }
+        return b
+    }
+    yes, no := conditionalBranches(n)
+    body := branch(yes)
+    if no != nil {
+        body = &Alternation{[]Node{body, branch(no)}}
+    }
+    return o.derive(&Group{Kind: n.Kind, Name: n.Name, Num: n.Num, Body: body}, n)
+}
+
+// concat flattens nested sequences, joins adjacent literals and merges adjacent
+// repetitions of the same character
+func (o *optimizer) concat(n *Concat) Node {
+    var nodes []Node
+    for _, sub := range n.Nodes {
+        sub = o.node(sub)
+        if len(n.Nodes) > 1 {
+            sub = o.grouped(sub)
+        }
+        if c, ok := sub.(*Concat); ok {
+            nodes = append(nodes, c.Nodes...)
+        } else {
+            nodes = append(nodes, sub)
+        }
+    }
+
+    var merged []Node
+    for _, sub := range nodes {
+        if len(merged) == 0 {
+            merged = append(merged, sub)
+            continue
+        }
+        last := merged[len(merged)-1]
+        if a, ok := last.(*Literal); ok {
+            if b, ok := sub.(*Literal); ok {
+                merged[len(merged)-1] = o.derive(&Literal{a.Text + b.Text}, a)
+                continue
+            }
+        }
+        if rep := o.mergeRepeats(last, sub); rep != nil {
+            merged[len(merged)-1] = rep
+            continue
+        }
+        merged = append(merged, sub)
+    }
+
+    if len(merged) == 1 {
+        return merged[0]
+    }
+    return o.derive(&Concat{merged}, n)
+}
+
+// singleChar reports whether a node matches exactly one character
+func singleChar(n Node) bool {
+    switch n := n.(type) {
+    case *Class:
+        return true
+    case *Literal:
+        return utf8.RuneCountInString(n.Text) == 1
+    }
+    return false
+}
+
+// repetition returns a node as a repetition of a single character, if it is one
+func repetition(n Node) (*Repeat, bool) {
+    if rep, ok := n.(*Repeat); ok && singleChar(rep.Sub) {
+        return rep, true
+    }
+    if singleChar(n) {
+        return &Repeat{Sub: n, Min: 1, Max: 1}, true
+    }
+    return nil, false
+}
+
+// mergeRepeats merges 2 adjacent repetitions of the same character into one, such as
+// `a{2}a*` into `a{2,}`, when it is shorter
+func (o *optimizer) mergeRepeats(a, b Node) Node {
+    ra, ok := repetition(a)
+    if !ok {
+        return nil
+    }
+    rb, ok := repetition(b)
+    if !ok || render(o.flavor, ra.Sub) != render(o.flavor, rb.Sub) {
+        return nil
+    }
+
+    _, repeatA := a.(*Repeat)
+    _, repeatB := b.(*Repeat)
+    mode := ra.Mode
+    switch {
+    case ra.Mode == Possessive || rb.Mode == Possessive:
+        return nil
+    case !repeatA:
+        mode = rb.Mode
+    case repeatB && ra.Mode != rb.Mode:
+        return nil
+    }
+
+    rep := &Repeat{Sub: ra.Sub, Min: ra.Min + rb.Min, Max: ra.Max + rb.Max, Mode: mode}
+    if ra.Max == -1 || rb.Max == -1 {
+        rep.Max = -1
+    }
+    if rep.Min > maxRepeat || rep.Max > maxRepeat {
+        return nil
+    }
+    if o.length(rep) >= o.length(&Concat{[]Node{a, b}}) {
+        return nil
+    }
+    return o.derive(rep, a)
+}
+
+// alternation flattens nested alternations, merges consecutive alternatives of single
+// characters into classes and factors out the prefixes shared by consecutive alternatives
+func (o *optimizer) alternation(n *Alternation) Node {
+    var alts []Node
+    for _, alt := range n.Alternatives {
+        alt = o.node(alt)
+        if a, ok := alt.(*Alternation); ok {
+            alts = append(alts, a.Alternatives...)
+        } else {
+            alts = append(alts, alt)
+        }
+    }
+
+    alts = o.mergeChars(alts)
+    alts = o.factorPrefixes(alts)
+    if len(alts) == 1 {
+        return alts[0]
+    }
+    return o.derive(&Alternation{alts}, n)
+}
+
+// classItems returns the items of a node matching a single character as a class
+func classItems(n Node) ([]ClassItem, bool) {
+    switch n := n.(type) {
+    case *Literal:
+        if c, size := utf8.DecodeRuneInString(n.Text); size == len(n.Text) && size > 0 {
+            return []ClassItem{charRange(c, c)}, true
+        }
+    case *Class:
+        if n.Negated {
+            return nil, false
+        }
+        for _, item := range n.Items {
+            if item.Kind == AnyCharItem || item.Kind == GraphemeItem || item.Kind == LinebreakItem {
+                return nil, false
+            }
+        }
+        return n.Items, true
+    }
+    return nil, false
+}
+
+// charClass returns a class matching any of the alternatives, if they all match a single
+// character. They cannot both match at the same position so their order does not matter
+func (o *optimizer) charClass(alts []Node) (Node, bool) {
+    var items []ClassItem
+    for _, alt := range alts {
+        more, ok := classItems(alt)
+        if !ok {
+            return nil, false
+        }
+        items = append(items, more...)
+    }
+    return o.derive(&Class{Items: items}, alts[0]), true
+}
+
+// grouped returns the node of an alternation which is written in a group, an alternation
+// of single characters is always shorter as a class
+func (o *optimizer) grouped(n Node) Node {
+    if a, ok := n.(*Alternation); ok {
+        if class, ok := o.charClass(a.Alternatives); ok {
+            return o.derive(class, a)
+        }
+    }
+    return n
+}
+
+// mergeChars merges runs of consecutive alternatives matching a single character into
+// a class, when it is not longer
+func (o *optimizer) mergeChars(alts []Node) []Node {
+    var result []Node
+    for i := 0; i < len(alts); {
+        j := i
+        for j < len(alts) {
+            if _, ok := classItems(alts[j]); !ok {
+                break
+            }
+            j++
+        }
+        if j-i < 2 {
+            result = append(result, alts[i])
+            i++
+            continue
+        }
+
+        class, _ := o.charClass(alts[i:j])
+        if o.length(class) > o.length(&Alternation{alts[i:j]}) {
+            result = append(result, alts[i:j]...)
+        } else {
+            result = append(result, class)
+        }
+        i = j
+    }
+    return result
+}
+
+// head returns the literal text an alternative starts with and the nodes following it
+func head(n Node) (string, []Node) {
+    switch n := n.(type) {
+    case *Literal:
+        return n.Text, nil
+    case *Concat:
+        if len(n.Nodes) == 0 {
+            break
+        }
+        if l, ok := n.Nodes[0].(*Literal); ok {
+            return l.Text, n.Nodes[1:]
+        }
+    }
+    return "", nil
+}
+
+// commonPrefix returns the length in bytes of the longest common prefix of 2 strings,
+// without splitting a character
+func commonPrefix(a, b string) int {
+    n := 0
+    for n < len(a) && n < len(b) {
+        c, size := utf8.DecodeRuneInString(a[n:])
+        if d, _ := utf8.DecodeRuneInString(b[n:]); c != d {
+            break
+        }
+        n += size
+    }
+    return n
+}
+
+// factorPrefixes factors the literal prefix shared by runs of consecutive alternatives,
+// such as `abc|abd` into `ab(?:c|d)`, when the result is shorter. The alternatives are
+// still tried in the same order
+func (o *optimizer) factorPrefixes(alts []Node) []Node {
+    var result []Node
+    for i := 0; i < len(alts); {
+        text, _ := head(alts[i])
+        prefix := len(text)
+        j := i + 1
+        for ; j < len(alts) && prefix > 0; j++ {
+            other, _ := head(alts[j])
+            p := commonPrefix(text[:prefix], other)
+            if p == 0 {
+                break
+            }
+            prefix = p
+        }
+        if j-i < 2 {
+            result = append(result, alts[i])
+            i++
+            continue
+        }
+
+        var rests []Node
+        empty := false
+        for _, alt := range alts[i:j] {
+            text, nodes := head(alt)
+            var rest []Node
+            if prefix < len(text) {
+                rest = append(rest, &Literal{text[prefix:]})
+            }
+            rest = append(rest, nodes...)
+            empty = empty || len(rest) == 0
+            rests = append(rests, joinNodes(rest))
+        }
+        factored := o.derive(&Concat{[]Node{
+            &Literal{text[:prefix]},
+            o.grouped(o.node(&Alternation{rests})),
+        }}, alts[i])
+        // every alternative has to match more than the prefix
+        if empty || o.length(factored) >= o.length(&Alternation{alts[i:j]}) {
+            result = append(result, alts[i])
+            i++
+            continue
+        }
+        result = append(result, factored)
+        i = j
+    }
+    return result
+}
+
+// repeat removes single repetitions and merges nested repetitions of a single character
+func (o *optimizer) repeat(n *Repeat) Node {
+    sub := o.grouped(o.node(n.Sub))
+    if n.Min == 1 && n.Max == 1 && n.Mode != Possessive {
+        return sub
+    }
+
+    inner, ok := sub.(*Repeat)
+    if ok && singleChar(inner.Sub) && inner.Mode == Greedy && n.Mode == Greedy && n.Max != 0 {
+        switch {
+        case inner.Max == -1 && inner.Min <= 1:
+            // (?:a+){2,3} is a{2,}
+            return o.derive(&Repeat{Sub: inner.Sub, Min: inner.Min * n.Min, Max: -1}, n)
+        case inner.Min == 0 && inner.Max == 1:
+            // (?:a?){2,3} is a{0,3}
+            return o.derive(&Repeat{Sub: inner.Sub, Min: 0, Max: n.Max}, n)
+        case n.Min == 0 && n.Max == 1 && inner.Min <= 1:
+            // (?:a{1,3})? is a{0,3}
+            return o.derive(&Repeat{Sub: inner.Sub, Min: 0, Max: inner.Max}, n)
+        }
+    }
+    return o.derive(&Repeat{Sub: sub, Min: n.Min, Max: n.Max, Mode: n.Mode}, n)
+}
+
+// Optimize makes Build() and BuildFor() render an equivalent but shorter pattern, with
+// redundant groups removed, repetitions of the same character merged, alternatives of
+// single characters written as classes and prefixes shared by alternatives factored out.
+// Patterns with errors are not optimized
+func (r *RejexBuilder) Optimize() *RejexBuilder {
+    r.optimize = true
+    return r
+}
+
+// renderedTree returns the syntax tree rendered by BuildFor for a flavor, along with the
+// nodes of the builder each node of an optimized tree was produced from
+func (r *RejexBuilder) renderedTree(flavor RejexFlavor) (Node, map[Node]Node) {
+    if !r.optimize || len(r.Errors) > 0 {
+        return r.Tree(), nil
+    }
+    return optimize(flavor, r.Tree())
+}
@@ -0,0 +1,157 @@
+package rejex
+
+import (
+    "math/rand"
+    "reflect"
+    "regexp"
+    "testing"
+)
+
+// optimizable holds patterns which the optimizer rewrites
+var optimizable = []string{
+    `(?:a)`,
+    `(?:(?:ab)c)d`,
+    `[a][b][c]`,
+    `[a-z][a-z]`,
+    `aa*`,
+    `a*a`,
+    `a{2}a{3}`,
+    `a+?a*?`,
+    `x{0,}`,
+    `(?:a+)+b`,
+    `(?:a?){2,3}`,
+    `(?:a{1,3})?`,
+    `(?:a{1})`,
+    `a|b|c`,
+    `a|[b-d]|e|fg|h`,
+    `abc|abd`,
+    `abc|abd|aef|x`,
+    `foo|foobar|bar`,
+    `(?:ab|ac)+`,
+    `(a|b)|(c)`,
+    `(?:a|(?:b|c))d`,
+    `(?P<word>ab|ac)\w`,
+    `(a)\1(?:0)`,
+    `(?i)ab|AC`,
+    `(?i:a|B)(?-i:c|d)`,
+    `^(?:ab)+$`,
+    `\d\d|\d[a-f]`,
+    `[^a]|b|c`,
+    `.|a`,
+    `a{50}a{50}a`,
+}
+
+// inputs returns strings to match a pattern against: its examples, counterexamples and
+// random strings made of the characters it contains
+func inputs(r *RejexBuilder, pattern string, rnd *rand.Rand) []string {
+    inputs := append([]string{""}, r.Examples(20, 1)...)
+    for _, c := range r.CounterExamples(20) {
+        inputs = append(inputs, c.Input)
+    }
+    alphabet := append([]rune(pattern), 'z', 'A', '0', ' ')
+    for i := 0; i < 100; i++ {
+        s := make([]rune, rnd.Intn(8))
+        for j := range s {
+            s[j] = alphabet[rnd.Intn(len(alphabet))]
+        }
+        inputs = append(inputs, string(s))
+    }
+    return inputs
+}
+
+// TestOptimizeEquivalence checks that optimized patterns are never longer than the
+// original ones, and match the same inputs with the same captures in every flavor
+func TestOptimizeEquivalence(t *testing.T) {
+    rnd := rand.New(rand.NewSource(1))
+
+    for _, pattern := range optimizable {
+        for _, flavor := range Flavors() {
+            plain := fromString(flavor, pattern)
+            optimized := fromString(flavor, pattern).Optimize()
+
+            // patterns using features the flavor does not support are skipped
+            want, errs := plain.Build()
+            if failed(errs) {
+                continue
+            }
+            got, errs := optimized.Build()
+            if failed(errs) {
+                t.Fatalf("%s optimized in the %s flavor: %v", pattern, flavor, errs)
+            }
+            if len(got) > len(want) {
+                t.Errorf("%s in the %s flavor: %s is longer than %s", pattern, flavor, got, want)
+            }
+
+            root, _ := optimized.renderedTree(flavor)
+            a := newMatcher(flavor, plain.Tree(), plain.flags)
+            b := newMatcher(flavor, root, optimized.flags)
+            for _, s := range inputs(plain, pattern, rnd) {
+                if a.fullMatch(s) != b.fullMatch(s) {
+                    t.Errorf("%s and %s in the %s flavor differ on full match of %q", want, got, flavor, s)
+                }
+                input := []rune(s)
+                for start := 0; start <= len(input); start++ {
+                    endA, okA := a.matchAt(input, start)
+                    endB, okB := b.matchAt(input, start)
+                    if endA != endB || okA != okB || okA && !reflect.DeepEqual(a.caps, b.caps) {
+                        t.Errorf("%s and %s in the %s flavor differ on %q at %d", want, got, flavor, s, start)
+                    }
+                }
+            }
+        }
+    }
+}
+
+// TestOptimizeRegexp checks optimized Go patterns against the regexp package
+func TestOptimizeRegexp(t *testing.T) {
+    rnd := rand.New(rand.NewSource(1))
+    for _, pattern := range optimizable {
+        plain := fromString(GoFlavor, pattern)
+        want, err := plain.Compile()
+        if err != nil {
+            continue
+        }
+        got, err := fromString(GoFlavor, pattern).Optimize().Compile()
+        if err != nil {
+            t.Fatal(err)
+        }
+        for _, s := range inputs(plain, pattern, rnd) {
+            if !reflect.DeepEqual(want.FindAllStringSubmatchIndex(s, -1), got.FindAllStringSubmatchIndex(s, -1)) {
+                t.Errorf("%s and %s differ on %q", want, got, s)
+            }
+        }
+    }
+}
+
+// TestOptimizeRewrites checks the patterns the optimizer produces
+func TestOptimizeRewrites(t *testing.T) {
+    rewrites := map[string]string{
+        `(?:a)`: `a`,
+        `[a][b][c]`: `abc`,
+        `aa*`: `a+`,
+        `a{2}a{3}`: `a{5}`,
+        `x{0,}`: `x*`,
+        `(?:a+)+b`: `a+b`,
+        `a|b|c`: `[abc]`,
+        `abc|abd`: `ab[cd]`,
+        `(?:a{1})`: `a`,
+    }
+    for pattern, want := range rewrites {
+        got, errs := fromString(GoFlavor, pattern).Optimize().Build()
+        if len(errs) > 0 {
+            t.Fatalf("%s: %v", pattern, errs)
+        }
+        if got != want {
+            t.Errorf("%s is optimized to %s, want %s", pattern, got, want)
+        }
+        if _, err := regexp.Compile(got); err != nil {
+            t.Error(err)
+        }
+    }
+
+    // a backreference is kept apart from the digits following it
+    got, _ := fromString(PerlFlavor, `(a)\1(?:0)`).Optimize().Build()
+    if want := `/(a)\1(?:)0/`; got != want {
+        t.Errorf("%s is optimized to %s, want %s", `(a)\1(?:0)`, got, want)
+    }
+}
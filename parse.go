@@ -0,0 +1,887 @@
+package rejex
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// parser reads regex syntax into nodes of the syntax tree
+type parser struct {
+    flavor RejexFlavor
+    src string
+    pos int
+    // verbose is set while the verbose flag is in effect, whitespace and comments
+    // outside of selection sets are skipped
+    verbose bool
+    // groups is the number of capture groups opened before the position
+    groups int
+}
+
+// parseError is raised while parsing and recovered at the parser entry points
+type parseError struct {
+    pos int
+    msg string
+}
+
+func (p *parser) fail(pos int, format string, a ...interface{}) {
+    panic(parseError{pos, fmt.Sprintf(format, a...)})
+}
+
+// recover converts a raised parseError into a RejexError
+func (p *parser) recover(err **RejexError) {
+    if e := recover(); e != nil {
+        pe, ok := e.(parseError)
+        if !ok {
+            panic(e)
+        }
+        *err = &RejexError{Position: pe.pos, Err: pe.msg}
+    }
+}
+
+func (p *parser) eof() bool {
+    return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+    if p.eof() {
+        return 0
+    }
+    c, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+    return c
+}
+
+func (p *parser) next() rune {
+    if p.eof() {
+        p.fail(p.pos, "Unexpected end of pattern")
+    }
+    c, size := utf8.DecodeRuneInString(p.src[p.pos:])
+    p.pos += size
+    return c
+}
+
+func (p *parser) accept(s string) bool {
+    if strings.HasPrefix(p.src[p.pos:], s) {
+        p.pos += len(s)
+        return true
+    }
+    return false
+}
+
+// skipVerbose skips the whitespace and `#` comments ignored by the verbose flag
+func (p *parser) skipVerbose() {
+    for p.verbose && !p.eof() {
+        switch p.peek() {
+        case ' ', '\t', '\n', '\r', '\v', '\f':
+            p.next()
+        case '#':
+            if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+                p.pos += i + 1
+            } else {
+                p.pos = len(p.src)
+            }
+        default:
+            return
+        }
+    }
+}
+
+// until consumes and returns everything up to the provided delimiter, which is skipped
+func (p *parser) until(delim string) string {
+    start := p.pos
+    i := strings.Index(p.src[p.pos:], delim)
+    if i < 0 {
+        p.fail(start, "Missing '%s'", delim)
+    }
+    p.pos += i + len(delim)
+    return p.src[start : start+i]
+}
+
+// parseClassItems parses the contents of a selection set such as `a-z\d_`
+func parseClassItems(flavor RejexFlavor, s string) (items []ClassItem, err *RejexError) {
+    p := parser{flavor: flavor, src: s}
+    defer p.recover(&err)
+    for !p.eof() {
+        items = append(items, p.classItem())
+    }
+    return items, nil
+}
+
+// parseClassChar parses a single character such as `a` or `\x41` used as the bound
+// of a range
+func parseClassChar(flavor RejexFlavor, s string) (c rune, err *RejexError) {
+    p := parser{flavor: flavor, src: s}
+    defer p.recover(&err)
+    item := p.classAtom()
+    if item.Kind != RangeItem || !p.eof() {
+        p.fail(0, "Expected a single character but got '%s'", s)
+    }
+    return item.Lo, nil
+}
+
+// classItem parses a single character, range or escaped class in a selection set
+func (p *parser) classItem() ClassItem {
+    start := p.pos
+    item := p.classAtom()
+    if item.Kind != RangeItem || p.peek() != '-' ||
+        p.pos+1 >= len(p.src) || p.src[p.pos+1] == ']' {
+        return item
+    }
+    p.next()
+    hi := p.classAtom()
+    if hi.Kind != RangeItem || hi.Lo < item.Lo {
+        p.fail(start, "Invalid range '%s' in selection set", p.src[start:p.pos])
+    }
+    item.Hi = hi.Lo
+    return item
+}
+
+// classAtom parses a single character or escaped class in a selection set
+func (p *parser) classAtom() ClassItem {
+    c := p.next()
+    if c != '\\' {
+        return ClassItem{Kind: RangeItem, Lo: c, Hi: c}
+    }
+    if p.peek() == 'b' {
+        p.next()
+        return ClassItem{Kind: RangeItem, Lo: '\b', Hi: '\b'}
+    }
+    return p.escape()
+}
+
+// escape parses the escape sequence following a '\' which represents either a single
+// character or a shorthand class such as `\d`
+func (p *parser) escape() ClassItem {
+    start := p.pos - 1
+    c := p.next()
+    switch c {
+    case 'd', 'w', 's', 'D', 'W', 'S':
+        kinds := map[rune]ClassItemKind{'d': DigitItem, 'w': WordItem, 's': WhitespaceItem}
+        lower := c | 0x20
+        return ClassItem{Kind: kinds[lower], Negated: c != lower}
+    case 'p', 'P':
+        var name string
+        if p.accept("{") {
+            name = p.until("}")
+        } else {
+            name = string(p.next())
+        }
+        for _, prefix := range []string{"Script=", "sc=", "General_Category=", "gc="} {
+            name = strings.TrimPrefix(name, prefix)
+        }
+        if name == "" {
+            p.fail(start, "Missing unicode class name")
+        }
+        return ClassItem{Kind: UnicodeItem, Name: name, Negated: c == 'P'}
+    case 'n':
+        return charItem('\n')
+    case 't':
+        return charItem('\t')
+    case 'r':
+        return charItem('\r')
+    case 'f':
+        return charItem('\f')
+    case 'v':
+        return charItem('\v')
+    case 'a':
+        return charItem('\a')
+    case 'e':
+        if p.flavor.Base() != PythonFlavor {
+            return charItem(0x1B)
+        }
+    case 'x':
+        if base := p.flavor.Base(); base != ECMAFlavor && base != PythonFlavor && p.accept("{") {
+            return charItem(p.hex(start, p.until("}")))
+        }
+        return charItem(p.hex(start, p.take(2)))
+    case 'u':
+        if p.flavor.Base() == PythonFlavor {
+            return charItem(p.hex(start, p.take(4)))
+        }
+        if p.flavor.Base() != ECMAFlavor {
+            break
+        }
+        if p.accept("{") {
+            return charItem(p.hex(start, p.until("}")))
+        }
+        return charItem(p.hex(start, p.take(4)))
+    case 'U':
+        if p.flavor.Base() == PythonFlavor {
+            return charItem(p.hex(start, p.take(8)))
+        }
+    case 'c':
+        p.require(ControlCharFeature, start)
+        l := p.next()
+        if l < '@' || l > '_' && (l < 'a' || l > 'z') {
+            p.fail(start, "Invalid control character '%c'", l)
+        }
+        return charItem(l & 0x1F)
+    case '0', '1', '2', '3', '4', '5', '6', '7':
+        digits := string(c)
+        for len(digits) < 3 && p.peek() >= '0' && p.peek() <= '7' {
+            digits += string(p.next())
+        }
+        n, _ := strconv.ParseInt(digits, 8, 32)
+        return charItem(rune(n))
+    }
+    if c < utf8.RuneSelf && !isWordChar(c) {
+        return charItem(c)
+    }
+    p.fail(start, "Invalid escape sequence '\\%c'", c)
+    return ClassItem{}
+}
+
+// take consumes and returns the next n bytes
+func (p *parser) take(n int) string {
+    if p.pos+n > len(p.src) {
+        p.fail(p.pos, "Unexpected end of pattern")
+    }
+    p.pos += n
+    return p.src[p.pos-n : p.pos]
+}
+
+func (p *parser) hex(start int, s string) rune {
+    n, err := strconv.ParseUint(s, 16, 32)
+    if err != nil || n > 0x10FFFF {
+        p.fail(start, "Invalid hex character code '%s'", s)
+    }
+    return rune(n)
+}
+
+func charItem(c rune) ClassItem {
+    return ClassItem{Kind: RangeItem, Lo: c, Hi: c}
+}
+
+func isWordChar(c rune) bool {
+    return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// posixClasses holds the ASCII ranges of the POSIX character classes usable in a
+// selection set such as `[[:alpha:]]`
+var posixClasses = map[string][]ClassItem{
+    "alnum": {charRange('0', '9'), charRange('A', 'Z'), charRange('a', 'z')},
+    "alpha": {charRange('A', 'Z'), charRange('a', 'z')},
+    "ascii": {charRange(0x00, 0x7F)},
+    "blank": {charItem('\t'), charItem(' ')},
+    "cntrl": {charRange(0x00, 0x1F), charItem(0x7F)},
+    "digit": {charRange('0', '9')},
+    "graph": {charRange('!', '~')},
+    "lower": {charRange('a', 'z')},
+    "print": {charRange(' ', '~')},
+    "punct": {charRange('!', '/'), charRange(':', '@'), charRange('[', '`'), charRange('{', '~')},
+    "space": {charRange('\t', '\r'), charItem(' ')},
+    "upper": {charRange('A', 'Z')},
+    "word": {charRange('0', '9'), charRange('A', 'Z'), charRange('a', 'z'), charItem('_')},
+    "xdigit": {charRange('0', '9'), charRange('A', 'F'), charRange('a', 'f')},
+}
+
+// parsePattern parses a complete regex string of the provided flavor. Flags set at
+// the very start of the pattern, either inline as `(?i)` or as the trailing flags of
+// a `/.../i` delimited pattern, are returned separately along with the start options
+// such as `(*UTF)` preceding them, given the number of capture groups preceding the
+// pattern in the builder
+func parsePattern(flavor RejexFlavor, s string, groups int) (n Node, flags []RejexFlag, options []string, err *RejexError) {
+    p := parser{flavor: flavor, src: s, groups: groups}
+    defer p.recover(&err)
+
+    if delimited(flavor) && strings.HasPrefix(s, "/") {
+        if end := strings.LastIndex(s, "/"); end > 0 {
+            for _, f := range s[end+1:] {
+                if _, ok := flavor.Flags()[RejexFlag(f)]; !ok {
+                    p.fail(end+1, "Invalid flag '%c'", f)
+                }
+                flags = append(flags, RejexFlag(f))
+            }
+            p.src = s[:end]
+            p.pos = 1
+        }
+    }
+
+    for flavor.Supports(StartOptionFeature) && strings.HasPrefix(p.src[p.pos:], "(*") {
+        end := strings.IndexByte(p.src[p.pos:], ')')
+        if end < 0 || !validStartOption(p.src[p.pos+2:p.pos+end]) {
+            break
+        }
+        options = append(options, p.src[p.pos+2:p.pos+end])
+        p.pos += end + 1
+    }
+
+    if start := p.pos; flavor.Supports(GroupFlagsFeature) && p.accept("(?") {
+        leading, ok := p.flagsUntil(")")
+        if ok && !strings.ContainsRune(string(leading), '-') {
+            flags = append(flags, leading...)
+        } else {
+            p.pos = start
+        }
+    }
+    p.verbose = flagState(flags, VerboseFlag, false)
+
+    n = p.alternation()
+    if !p.eof() {
+        p.fail(p.pos, "Unexpected ')'")
+    }
+    return n, flags, options, nil
+}
+
+// delimited reports whether patterns of a flavor are written between '/' delimiters
+// followed by their flags
+func delimited(flavor RejexFlavor) bool {
+    prefix, _ := flavor.RenderFlags(nil, false)
+    return prefix == "/"
+}
+
+// parseFragment parses a regex string of the provided flavor which is a part of a pattern,
+// preceded by the provided number of capture groups
+func parseFragment(flavor RejexFlavor, s string, groups int) (n Node, err *RejexError) {
+    p := parser{flavor: flavor, src: s, groups: groups}
+    defer p.recover(&err)
+
+    n = p.alternation()
+    if !p.eof() {
+        p.fail(p.pos, "Unexpected ')'")
+    }
+    return n, nil
+}
+
+func (p *parser) require(f Feature, pos int) {
+    if !p.flavor.Supports(f) {
+        p.fail(pos, unsupported(f, p.flavor))
+    }
+}
+
+// alternation parses alternatives separated by '|' up to the end of the enclosing group
+func (p *parser) alternation() Node {
+    var alts [][]Node
+    var inline []RejexFlag
+    for {
+        nodes := p.concat(&inline)
+        alts = append(alts, nodes)
+        if !p.accept("|") {
+            break
+        }
+        if len(inline) > 0 {
+            // inline flags stay in effect for the following alternatives
+            rest := p.alternation()
+            alts = append(alts, []Node{&Group{Kind: FlagGroup, Flags: inline, Body: rest}})
+            break
+        }
+    }
+    return joinAlternatives(alts)
+}
+
+// concat parses a sequence of quantified atoms up to the end of the alternative.
+// Inline flags like `(?i)` apply to the remainder of the enclosing group and are
+// recorded in inline
+func (p *parser) concat(inline *[]RejexFlag) []Node {
+    var nodes []Node
+    for p.skipVerbose(); !p.eof() && p.peek() != '|' && p.peek() != ')'; p.skipVerbose() {
+        start := p.pos
+        if p.accept("(?") {
+            if flags, ok := p.flagsUntil(")"); ok {
+                p.require(GroupFlagsFeature, start)
+                if p.flavor.Base() == PythonFlavor {
+                    p.fail(start, "Inline flags must be at the start of the pattern in the %s flavor", p.flavor.Name())
+                }
+                *inline = append(*inline, flags...)
+                rest := joinNodes(p.concat(inline))
+                nodes = append(nodes, &Group{Kind: FlagGroup, Flags: flags, Body: rest})
+                break
+            }
+            p.pos = start
+        }
+
+        atom := p.atom()
+        if atom == nil {
+            continue
+        }
+        if lit, ok := atom.(*Literal); ok && utf8.RuneCountInString(lit.Text) > 1 {
+            // a quantifier only applies to the last character of quoted text
+            _, size := utf8.DecodeLastRuneInString(lit.Text)
+            nodes = appendMerged(nodes, &Literal{lit.Text[:len(lit.Text)-size]})
+            atom = &Literal{lit.Text[len(lit.Text)-size:]}
+        }
+        atom = p.quantifiers(atom, start)
+        nodes = appendMerged(nodes, atom)
+    }
+    return nodes
+}
+
+// appendMerged appends a node to a sequence, merging adjacent literals
+func appendMerged(nodes []Node, n Node) []Node {
+    if lit, ok := n.(*Literal); ok && len(nodes) > 0 {
+        if prev, ok := nodes[len(nodes)-1].(*Literal); ok {
+            nodes[len(nodes)-1] = &Literal{prev.Text + lit.Text}
+            return nodes
+        }
+    }
+    return append(nodes, n)
+}
+
+// flagsUntil parses flag letters up to the provided delimiter, the position is
+// only advanced if the flags are valid
+func (p *parser) flagsUntil(delim string) ([]RejexFlag, bool) {
+    start := p.pos
+    var flags []RejexFlag
+    valid := p.flavor.Flags()
+    for !p.eof() {
+        c := p.peek()
+        if strings.HasPrefix(p.src[p.pos:], delim) {
+            if len(flags) == 0 {
+                break
+            }
+            p.pos += len(delim)
+            return flags, true
+        }
+        if _, ok := valid[RejexFlag(c)]; !ok && c != '-' || c == 'g' || c == 'y' ||
+            c == 'u' && p.flavor.Base() != PythonFlavor {
+            break
+        }
+        flags = append(flags, RejexFlag(p.next()))
+    }
+    p.pos = start
+    return nil, false
+}
+
+// atom parses a single character, class, group, anchor or escape sequence. It returns
+// nil for constructs which do not produce a node
+func (p *parser) atom() Node {
+    start := p.pos
+    c := p.next()
+    switch c {
+    case '(':
+        if p.peek() == '*' && p.flavor.Supports(VerbFeature) {
+            p.next()
+            v, err := parseVerb(p.until(")"))
+            if err != nil {
+                p.fail(start, "%s", err)
+            }
+            return v
+        }
+        return p.group(start)
+    case '[':
+        return p.class(start)
+    case '.':
+        return &Class{Items: []ClassItem{{Kind: AnyCharItem}}}
+    case '^':
+        return &Assertion{AssertLineStart}
+    case '$':
+        return &Assertion{AssertLineEnd}
+    case '*', '+', '?':
+        p.fail(start, "Missing argument to repetition operator '%c'", c)
+    case '{':
+        if _, _, ok := p.repeatBounds(); ok {
+            p.fail(start, "Missing argument to repetition operator")
+        }
+        return &Literal{"{"}
+    case '\\':
+        return p.atomEscape(start)
+    }
+    return &Literal{string(c)}
+}
+
+// atomEscape parses an escape sequence outside of a selection set
+func (p *parser) atomEscape(start int) Node {
+    switch c := p.peek(); {
+    case c == 'b':
+        p.next()
+        return &Assertion{AssertWordBoundary}
+    case c == 'B':
+        p.next()
+        return &Assertion{AssertNonWordBoundary}
+    case c == 'A':
+        p.next()
+        p.require(AbsoluteAnchorFeature, start)
+        return &Assertion{AssertTextStart}
+    case c == 'z' && p.flavor.Base() != PythonFlavor:
+        p.next()
+        p.require(AbsoluteAnchorFeature, start)
+        return &Assertion{AssertTextEnd}
+    case c == 'Z' && p.flavor.Base() == PythonFlavor:
+        // \Z of Python only matches at the very end of the text
+        p.next()
+        return &Assertion{AssertTextEnd}
+    case c == 'Z':
+        p.next()
+        p.require(NewlineEndAnchorFeature, start)
+        return &Assertion{AssertTextEndNewline}
+    case c == 'G':
+        p.next()
+        p.require(LastMatchEndFeature, start)
+        return &Assertion{AssertLastMatchEnd}
+    case c == 'X':
+        p.next()
+        p.require(GraphemeFeature, start)
+        return &Class{Items: []ClassItem{{Kind: GraphemeItem}}}
+    case c == 'R':
+        p.next()
+        p.require(LinebreakFeature, start)
+        return &Class{Items: []ClassItem{{Kind: LinebreakItem}}}
+    case c == 'K':
+        p.next()
+        p.require(MatchStartFeature, start)
+        return &Assertion{AssertMatchStart}
+    case c == 'Q':
+        p.next()
+        p.require(QuoteFeature, start)
+        var text string
+        if i := strings.Index(p.src[p.pos:], `\E`); i >= 0 {
+            text = p.src[p.pos : p.pos+i]
+            p.pos += i + 2
+        } else {
+            text = p.src[p.pos:]
+            p.pos = len(p.src)
+        }
+        if text == "" {
+            return nil
+        }
+        return &Literal{text}
+    case c == 'E':
+        p.next()
+        return nil
+    case c >= '1' && c <= '9' && !p.octalEscape():
+        p.require(BackrefFeature, start)
+        return &Backref{Num: p.number(start)}
+    case c == 'k' && p.flavor.Base() != PythonFlavor:
+        p.next()
+        p.require(NamedBackrefFeature, start)
+        switch {
+        case p.accept("<"):
+            return &Backref{Name: p.until(">")}
+        case p.accept("'") && perlSyntax(p.flavor):
+            return &Backref{Name: p.until("'")}
+        case p.accept("{") && perlSyntax(p.flavor):
+            return &Backref{Name: p.until("}")}
+        }
+        p.fail(start, "Invalid named backreference")
+    case c == 'g' && perlSyntax(p.flavor):
+        p.next()
+        switch {
+        case p.accept("<"):
+            return p.recursion(start, p.until(">"))
+        case p.accept("'"):
+            return p.recursion(start, p.until("'"))
+        }
+        p.require(BackrefFeature, start)
+        ref := string(p.next())
+        if ref == "{" {
+            ref = p.until("}")
+        } else if ref == "-" || ref >= "0" && ref <= "9" {
+            for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+                ref += string(p.next())
+            }
+        }
+        if n, err := strconv.Atoi(ref); err == nil && n != 0 {
+            if n < 0 {
+                p.require(RelativeBackrefFeature, start)
+            }
+            return &Backref{Num: n}
+        }
+        if ref == "" || ref[0] >= '0' && ref[0] <= '9' || ref[0] == '-' {
+            p.fail(start, "Invalid backreference")
+        }
+        return &Backref{Name: ref}
+    }
+
+    item := p.escape()
+    if item.Kind != RangeItem {
+        if item.Kind == UnicodeItem {
+            p.require(UnicodeClassFeature, start)
+        }
+        return &Class{Items: []ClassItem{item}}
+    }
+    return &Literal{string(item.Lo)}
+}
+
+// octalEscape reports whether the digits following a backslash are read as an octal escape
+// rather than a backreference. Perl reads numbers of two or more digits as octal when
+// fewer groups precede them, unless they start with 8 or 9
+func (p *parser) octalEscape() bool {
+    if !perlSyntax(p.flavor) {
+        return false
+    }
+    end := p.pos
+    for end < len(p.src) && isDigit(rune(p.src[end])) {
+        end++
+    }
+    n, err := strconv.Atoi(p.src[p.pos:end])
+    return err == nil && n >= 10 && p.src[p.pos] <= '7' && n > p.groups
+}
+
+// number parses a decimal number
+func (p *parser) number(start int) int {
+    digits := ""
+    for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+        digits += string(p.next())
+    }
+    n, err := strconv.Atoi(digits)
+    if err != nil {
+        p.fail(start, "Invalid number '%s'", digits)
+    }
+    return n
+}
+
+// group parses a group construct following its opening '('
+func (p *parser) group(start int) Node {
+    g := &Group{Kind: CaptureGroup}
+    switch {
+    case !p.accept("?"):
+    case p.accept(":"):
+        g.Kind = NonCaptureGroup
+    case p.accept("P<"):
+        if p.flavor.Base() == ECMAFlavor {
+            p.fail(start, "Named groups use the '(?<name>' syntax in the %s flavor", p.flavor.Name())
+        }
+        g.Kind, g.Name = NamedCaptureGroup, p.groupName(start, ">")
+    case p.accept("P="):
+        if p.flavor.Base() == ECMAFlavor {
+            p.fail(start, "Invalid group syntax")
+        }
+        p.require(NamedBackrefFeature, start)
+        return &Backref{Name: p.groupName(start, ")")}
+    case p.accept("="):
+        p.require(LookaheadFeature, start)
+        g.Kind = PosLookahead
+    case p.accept("!"):
+        p.require(LookaheadFeature, start)
+        g.Kind = NegLookahead
+    case p.accept("<="):
+        p.require(LookbehindFeature, start)
+        g.Kind = PosLookbehind
+    case p.accept("<!"):
+        p.require(LookbehindFeature, start)
+        g.Kind = NegLookbehind
+    case p.accept("<"):
+        if p.flavor.Base() == PythonFlavor {
+            p.fail(start, "Named groups use the '(?P<name>' syntax in the %s flavor", p.flavor.Name())
+        }
+        g.Kind, g.Name = NamedCaptureGroup, p.groupName(start, ">")
+    case p.accept("'") && perlSyntax(p.flavor):
+        g.Kind, g.Name = NamedCaptureGroup, p.groupName(start, "'")
+    case p.accept(">"):
+        p.require(AtomicGroupFeature, start)
+        g.Kind = AtomicGroup
+    case p.accept("|"):
+        p.require(BranchResetFeature, start)
+        g.Kind = BranchResetGroup
+    case p.accept("("):
+        p.condition(g, start)
+    case p.accept("R)"):
+        return p.recursion(start, "0")
+    case p.accept("&"), p.accept("P>"):
+        return p.recursion(start, p.until(")"))
+    case isDigit(p.peek()) || (p.peek() == '-' || p.peek() == '+') && p.pos+1 < len(p.src) && isDigit(rune(p.src[p.pos+1])):
+        return p.recursion(start, p.until(")"))
+    default:
+        flags, ok := p.flagsUntil(":")
+        if !ok {
+            p.fail(start, "Invalid group syntax")
+        }
+        p.require(GroupFlagsFeature, start)
+        g.Kind, g.Flags = FlagGroup, flags
+    }
+
+    verbose := p.verbose
+    if g.Kind == FlagGroup {
+        p.verbose = flagState(g.Flags, VerboseFlag, verbose)
+    }
+    if g.Kind == CaptureGroup || g.Kind == NamedCaptureGroup {
+        p.groups++
+    }
+    g.Body = p.alternation()
+    p.verbose = verbose
+    if !p.accept(")") {
+        p.fail(start, "Missing closing ')'")
+    }
+    if alt, ok := g.Body.(*Alternation); ok {
+        switch {
+        case g.Kind == ConditionalGroup && len(alt.Alternatives) > 2:
+            p.fail(start, "Conditional group contains more than two alternatives")
+        case g.Kind == DefineGroup:
+            p.fail(start, "DEFINE group contains more than one alternative")
+        }
+    }
+    return g
+}
+
+// condition parses the condition of a conditional group following its opening `(?(`,
+// which is a group number or name, or DEFINE
+func (p *parser) condition(g *Group, start int) {
+    p.require(ConditionalFeature, start)
+    if c := p.peek(); c == '?' || c == '*' {
+        p.fail(start, "Assertions are not supported as conditions")
+    }
+    cond := p.until(")")
+    g.Kind = ConditionalGroup
+    switch {
+    case cond == "DEFINE":
+        // DEFINE groups only hold groups to be called as subroutines
+        p.require(RecursionFeature, start)
+        g.Kind = DefineGroup
+    case strings.HasPrefix(cond, "<") && strings.HasSuffix(cond, ">"),
+        strings.HasPrefix(cond, "'") && strings.HasSuffix(cond, "'"):
+        if !perlSyntax(p.flavor) {
+            p.fail(start, "Invalid group name '%s'", cond)
+        }
+        g.Name = p.checkName(start, cond[1:len(cond)-1])
+    case cond != "" && (isDigit(rune(cond[0])) || cond[0] == '-' || cond[0] == '+'):
+        g.Num = p.reference(start, cond)
+        if g.Num < 0 {
+            p.require(RelativeBackrefFeature, start)
+        }
+    case cond == "R" || strings.HasPrefix(cond, "R&") || len(cond) > 1 && cond[0] == 'R' && isDigit(rune(cond[1])):
+        p.fail(start, "Recursion conditions are not supported")
+    default:
+        g.Name = p.checkName(start, cond)
+    }
+}
+
+// recursion returns the recursion into the group referenced by a number or name, the
+// whole pattern for the number 0
+func (p *parser) recursion(start int, ref string) Node {
+    p.require(RecursionFeature, start)
+    if ref != "" && (isDigit(rune(ref[0])) || ref[0] == '-' || ref[0] == '+') {
+        return &Recursion{Num: p.reference(start, ref)}
+    }
+    return &Recursion{Name: p.checkName(start, ref)}
+}
+
+// reference parses the number of a referenced group, negative numbers are relative to
+// the reference
+func (p *parser) reference(start int, ref string) int {
+    if strings.HasPrefix(ref, "+") {
+        p.fail(start, "Relative references to following groups are not supported")
+    }
+    n, err := strconv.Atoi(ref)
+    if err != nil || strings.HasPrefix(ref, "-") && n == 0 {
+        p.fail(start, "Invalid group reference '%s'", ref)
+    }
+    return n
+}
+
+func isDigit(c rune) bool {
+    return c >= '0' && c <= '9'
+}
+
+func (p *parser) groupName(start int, delim string) string {
+    return p.checkName(start, p.until(delim))
+}
+
+// checkName fails if a group name is empty or contains anything but word characters
+func (p *parser) checkName(start int, name string) string {
+    if name == "" {
+        p.fail(start, "Empty group name")
+    }
+    for _, c := range name {
+        if !isWordChar(c) {
+            p.fail(start, "Invalid group name '%s'", name)
+        }
+    }
+    return name
+}
+
+// class parses a selection set following its opening '['
+func (p *parser) class(start int) Node {
+    c := &Class{}
+    if p.accept("^") {
+        c.Negated = true
+    }
+    if p.flavor.Base() != ECMAFlavor && p.accept("]") {
+        c.Items = append(c.Items, charItem(']'))
+    }
+    for !p.accept("]") {
+        if p.eof() {
+            p.fail(start, "Missing closing ']'")
+        }
+        if p.flavor.Base() != ECMAFlavor && p.accept("[:") {
+            name := p.until(":]")
+            negated := strings.HasPrefix(name, "^")
+            items, ok := posixClasses[strings.TrimPrefix(name, "^")]
+            if !ok || negated {
+                p.fail(start, "Invalid POSIX class '%s'", name)
+            }
+            c.Items = append(c.Items, items...)
+            continue
+        }
+        item := p.classItem()
+        if item.Kind == UnicodeItem {
+            p.require(UnicodeClassFeature, start)
+        }
+        c.Items = append(c.Items, item)
+    }
+    return c
+}
+
+// repeatBounds parses the bounds of a `{n,m}` quantifier following its opening '{',
+// the position is only advanced if the bounds are valid
+func (p *parser) repeatBounds() (min, max int, ok bool) {
+    start := p.pos
+    end := strings.IndexByte(p.src[p.pos:], '}')
+    if end < 0 {
+        return 0, 0, false
+    }
+    bounds := strings.SplitN(p.src[p.pos:p.pos+end], ",", 2)
+    // Python reads an empty minimum as zero
+    if len(bounds) == 2 && bounds[0] == "" && p.flavor.Base() == PythonFlavor {
+        bounds[0] = "0"
+    }
+    min, err := strconv.Atoi(bounds[0])
+    if err != nil || min < 0 || bounds[0][0] == '+' {
+        return 0, 0, false
+    }
+    max = min
+    if len(bounds) == 2 {
+        if bounds[1] == "" {
+            max = -1
+        } else if max, err = strconv.Atoi(bounds[1]); err != nil || max < 0 || bounds[1][0] == '+' {
+            return 0, 0, false
+        }
+    }
+    p.pos = start + end + 1
+    return min, max, true
+}
+
+// quantifiers parses any quantifiers following an atom
+func (p *parser) quantifiers(atom Node, start int) Node {
+    quantified := false
+    for p.skipVerbose(); !p.eof(); p.skipVerbose() {
+        qstart := p.pos
+        rep := &Repeat{Sub: atom}
+        switch p.peek() {
+        case '*':
+            rep.Min, rep.Max = 0, -1
+        case '+':
+            rep.Min, rep.Max = 1, -1
+        case '?':
+            rep.Min, rep.Max = 0, 1
+        case '{':
+            p.next()
+            min, max, ok := p.repeatBounds()
+            if !ok {
+                p.pos = qstart
+                return atom
+            }
+            limit := p.flavor.MaxRepeat()
+            if max != -1 && max < min || limit > 0 && (min > limit || max > limit) {
+                p.fail(qstart, "Invalid repeat count '%s'", p.src[qstart:p.pos])
+            }
+            rep.Min, rep.Max = min, max
+            p.pos--
+        default:
+            return atom
+        }
+        p.next()
+
+        if quantified {
+            p.fail(qstart, "Invalid nested repetition operator '%s'", p.src[start:p.pos])
+        }
+        if p.accept("?") {
+            rep.Mode = Lazy
+        } else if !p.eof() && p.peek() == '+' && p.flavor.Supports(PossessiveFeature) {
+            p.next()
+            rep.Mode = Possessive
+        }
+        atom, quantified = rep, true
+    }
+    return atom
+}
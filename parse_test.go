@@ -0,0 +1,40 @@
+package rejex
+
+import (
+    "strings"
+    "testing"
+)
+
+// TestParseErrorsOnce checks that the syntax errors of a regex string are reported once,
+// rather than again by the validation of the pattern it is written to verbatim
+func TestParseErrorsOnce(t *testing.T) {
+    for _, pattern := range []string{`a{5000}`, `(a`, `[b`, `a**`} {
+        _, errs := NewRejexFromString(pattern, true).Build()
+        if len(errs) != 1 {
+            t.Errorf("%s is reported with %d errors: %v", pattern, len(errs), errs)
+        }
+    }
+}
+
+// TestPerlOctalEscapes checks that numbers of two or more digits are read as backreferences
+// in Perl syntax only when that many groups precede them
+func TestPerlOctalEscapes(t *testing.T) {
+    groups := strings.Repeat("(a)", 10)
+    tests := []struct {
+        flavor RejexFlavor
+        pattern string
+        want string
+    }{
+        {PerlFlavor, `\10`, `/\x08/`},
+        {PCREFlavor, `a\18`, `a\x018`},
+        {PCREFlavor, `(a)\1`, `(a)\1`},
+        {PCREFlavor, groups + `\10`, groups + `\10`},
+        {PCREFlavor, `(a)(a)(a)(a)(a)(a)(a)(a)(a)(\10)`, `(a)(a)(a)(a)(a)(a)(a)(a)(a)(\10)`},
+    }
+    for _, test := range tests {
+        got, errs := fromString(test.flavor, test.pattern).BuildFor(test.flavor)
+        if got != test.want {
+            t.Errorf("%s in the %s flavor is rendered as %s %v, want %s", test.pattern, test.flavor, got, errs, test.want)
+        }
+    }
+}
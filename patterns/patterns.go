@@ -0,0 +1,530 @@
+// Package patterns provides fragments matching commonly validated formats, to be
+// embedded in a regex of any flavor with Pattern(). Each format comes in a Strict
+// variant which follows its specification and a Lenient variant which also accepts
+// common deviations from it. The fragments are not anchored.
+//
+//     re := rejex.NewRejex().
+//             Starting().
+//             Pattern(patterns.IPv4(patterns.Strict)).
+//             Ending().
+//             MustCompile()
+package patterns
+
+import "github.com/tyagdit/rejex"
+
+// Variant selects how closely a fragment follows the specification of its format
+type Variant int
+
+const (
+    Strict Variant = iota
+    Lenient
+)
+
+const (
+    hexChars = "0-9a-fA-F"
+    base64URLChars = "-A-Za-z0-9_"
+    // atomChars are the characters allowed in the local part of an email address
+    atomChars = "A-Za-z0-9!#$%&'*+/=?^_`{|}~-"
+    // pathChars are the characters allowed in a segment of a URL path
+    pathChars = "-A-Za-z0-9._~!$&'()*+,;=:@%"
+)
+
+// newFragment returns an unrestricted builder, the fragments only use constructs which
+// are available in every flavor
+func newFragment() *rejex.RejexBuilder {
+    return rejex.NewRejex(true).Builder()
+}
+
+// hex matches n hexadecimal digits
+func hex(n int) *rejex.RejexBuilder {
+    return newFragment().AnyFrom(hexChars).NOf("", n)
+}
+
+// octet matches a decimal number between 0 and 255
+func octet(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().AnyFrom("0-9").NToMOf("", 1, 3)
+    }
+    return newFragment().EitherOr("25[0-5]", "2[0-4][0-9]", "1[0-9]{2}", "[1-9]?[0-9]")
+}
+
+// label matches a single label of a hostname
+func label() *rejex.RejexBuilder {
+    return newFragment().
+        AnyFrom("A-Za-z0-9").
+        BeginNonCaptureGroup().
+            AnyFrom("A-Za-z0-9-").
+            NToMOf("", 0, 61).
+            AnyFrom("A-Za-z0-9").
+        EndGroup().
+        ZeroOrOneOf("")
+}
+
+// hostname matches a fully qualified domain name with an alphabetic top level domain
+func hostname() *rejex.RejexBuilder {
+    return newFragment().
+        BeginNonCaptureGroup().
+            Pattern(label()).
+            EscapedCharacters(".").
+        EndGroup().
+        OneOrMoreOf("").
+        AnyFrom("A-Za-z").
+        NToMOf("", 2, 63)
+}
+
+// IPv4 matches an IPv4 address in dotted decimal notation. The lenient variant accepts
+// any number of up to 3 digits in each part
+func IPv4(v Variant) *rejex.RejexBuilder {
+    return newFragment().
+        Pattern(octet(v)).
+        BeginNonCaptureGroup().
+            EscapedCharacters(".").
+            Pattern(octet(v)).
+        EndGroup().
+        NOf("", 3)
+}
+
+// IPv6 matches an IPv6 address, with its zeros optionally compressed or with an IPv4
+// address in its last 32 bits. The lenient variant accepts any groups of up to 4
+// hexadecimal digits separated by at least 2 colons
+func IPv6(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            AnyFrom(hexChars).
+            NToMOf("", 0, 4).
+            BeginNonCaptureGroup().
+                Characters(":").
+                AnyFrom(hexChars).
+                NToMOf("", 0, 4).
+            EndGroup().
+            NToMOf("", 2, 7)
+    }
+
+    h := "[0-9a-fA-F]{1,4}"
+    return newFragment().
+        BeginNonCaptureGroup().
+            Characters("(?:" + h + ":){6}").
+            Pattern(IPv4(Strict)).
+        Or().
+            Characters("::(?:[fF]{4}(?::0{1,4})?:)?").
+            Pattern(IPv4(Strict)).
+        Or().
+            Characters("(?:" + h + ":){1,4}:").
+            Pattern(IPv4(Strict)).
+        Or().
+            Characters(h + "(?::" + h + "){7}").
+        Or().
+            Characters("(?:" + h + ":){1,7}:").
+        Or().
+            Characters("(?:" + h + ":){1,6}:" + h).
+        Or().
+            Characters("(?:" + h + ":){1,5}(?::" + h + "){1,2}").
+        Or().
+            Characters("(?:" + h + ":){1,4}(?::" + h + "){1,3}").
+        Or().
+            Characters("(?:" + h + ":){1,3}(?::" + h + "){1,4}").
+        Or().
+            Characters("(?:" + h + ":){1,2}(?::" + h + "){1,5}").
+        Or().
+            Characters(h + ":(?::" + h + "){1,6}").
+        Or().
+            Characters(":(?:(?::" + h + "){1,7}|:)").
+        EndGroup()
+}
+
+// UUID matches a UUID in its hyphenated form, with a version from 1 to 8 and the
+// variant of RFC 4122. The lenient variant accepts any hexadecimal digits, with the
+// hyphens optional
+func UUID(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            Pattern(hex(8)).
+            ZeroOrOneOf("-").
+            Pattern(hex(4)).
+            ZeroOrOneOf("-").
+            Pattern(hex(4)).
+            ZeroOrOneOf("-").
+            Pattern(hex(4)).
+            ZeroOrOneOf("-").
+            Pattern(hex(12))
+    }
+    return newFragment().
+        Pattern(hex(8)).
+        Characters("-").
+        Pattern(hex(4)).
+        Characters("-").
+        AnyFromCharRange("1", "8").
+        Pattern(hex(3)).
+        Characters("-").
+        AnyFrom("89abAB").
+        Pattern(hex(3)).
+        Characters("-").
+        Pattern(hex(12))
+}
+
+// MAC matches a MAC address of 6 pairs of hexadecimal digits consistently separated by
+// colons or hyphens. The lenient variant also accepts mixed or no separators and 3 dot
+// separated groups of 4 digits
+func MAC(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            BeginNonCaptureGroup().
+                Pattern(hex(2)).
+                BeginNonCaptureGroup().
+                    AnyFrom(":-").
+                    ZeroOrOneOf("").
+                    Pattern(hex(2)).
+                EndGroup().
+                NOf("", 5).
+            Or().
+                Pattern(hex(4)).
+                EscapedCharacters(".").
+                Pattern(hex(4)).
+                EscapedCharacters(".").
+                Pattern(hex(4)).
+            EndGroup()
+    }
+    return newFragment().
+        BeginNonCaptureGroup().
+            BeginNonCaptureGroup().
+                Pattern(hex(2)).
+                Characters(":").
+            EndGroup().
+            NOf("", 5).
+        Or().
+            BeginNonCaptureGroup().
+                Pattern(hex(2)).
+                Characters("-").
+            EndGroup().
+            NOf("", 5).
+        EndGroup().
+        Pattern(hex(2))
+}
+
+// SemVer matches a version following Semantic Versioning 2.0.0, with optional pre-release
+// and build metadata. The lenient variant also accepts a leading 'v', leading zeros and
+// versions with a missing minor or patch number
+func SemVer(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            ZeroOrOneOf("v").
+            AnyFrom("0-9").
+            OneOrMoreOf("").
+            BeginNonCaptureGroup().
+                EscapedCharacters(".").
+                AnyFrom("0-9").
+                OneOrMoreOf("").
+            EndGroup().
+            NToMOf("", 0, 2).
+            BeginNonCaptureGroup().
+                Characters("-").
+                AnyFrom("0-9A-Za-z.-").
+                OneOrMoreOf("").
+            EndGroup().
+            ZeroOrOneOf("").
+            BeginNonCaptureGroup().
+                EscapedCharacters("+").
+                AnyFrom("0-9A-Za-z.-").
+                OneOrMoreOf("").
+            EndGroup().
+            ZeroOrOneOf("")
+    }
+
+    number := newFragment().EitherOr("0", "[1-9][0-9]*")
+    identifier := newFragment().EitherOr("0", "[1-9][0-9]*", "[0-9]*[a-zA-Z-][0-9a-zA-Z-]*")
+    return newFragment().
+        Pattern(number).
+        EscapedCharacters(".").
+        Pattern(number).
+        EscapedCharacters(".").
+        Pattern(number).
+        BeginNonCaptureGroup().
+            Characters("-").
+            Pattern(identifier).
+            BeginNonCaptureGroup().
+                EscapedCharacters(".").
+                Pattern(identifier).
+            EndGroup().
+            ZeroOrMoreOf("").
+        EndGroup().
+        ZeroOrOneOf("").
+        BeginNonCaptureGroup().
+            EscapedCharacters("+").
+            AnyFrom("0-9a-zA-Z-").
+            OneOrMoreOf("").
+            BeginNonCaptureGroup().
+                EscapedCharacters(".").
+                AnyFrom("0-9a-zA-Z-").
+                OneOrMoreOf("").
+            EndGroup().
+            ZeroOrMoreOf("").
+        EndGroup().
+        ZeroOrOneOf("")
+}
+
+// Date matches an ISO 8601 calendar date in the extended YYYY-MM-DD format, with the
+// day checked against the month but not against leap years. The lenient variant also
+// accepts the basic YYYYMMDD format and any month from 00 to 19 and day from 00 to 39
+func Date(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            AnyFrom("0-9").
+            NOf("", 4).
+            ZeroOrOneOf("-").
+            AnyFrom("01").
+            AnyFrom("0-9").
+            ZeroOrOneOf("-").
+            AnyFrom("0-3").
+            AnyFrom("0-9")
+    }
+    return newFragment().
+        AnyFrom("0-9").
+        NOf("", 4).
+        Characters("-").
+        EitherOr(
+            "(?:0[13578]|1[02])-(?:0[1-9]|[12][0-9]|3[01])",
+            "(?:0[469]|11)-(?:0[1-9]|[12][0-9]|30)",
+            "02-(?:0[1-9]|[12][0-9])",
+        )
+}
+
+// timeOfDay matches the hours, minutes, seconds and fraction of a second of a time
+func timeOfDay(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            AnyFrom("0-2").
+            AnyFrom("0-9").
+            ZeroOrOneOf(":").
+            AnyFrom("0-5").
+            AnyFrom("0-9").
+            BeginNonCaptureGroup().
+                ZeroOrOneOf(":").
+                AnyFrom("0-6").
+                AnyFrom("0-9").
+                BeginNonCaptureGroup().
+                    AnyFrom(".,").
+                    AnyFrom("0-9").
+                    OneOrMoreOf("").
+                EndGroup().
+                ZeroOrOneOf("").
+            EndGroup().
+            ZeroOrOneOf("")
+    }
+    return newFragment().
+        EitherOr("[01][0-9]", "2[0-3]").
+        Characters(":").
+        AnyFrom("0-5").
+        AnyFrom("0-9").
+        Characters(":").
+        EitherOr("[0-5][0-9]", "60").
+        BeginNonCaptureGroup().
+            EscapedCharacters(".").
+            AnyFrom("0-9").
+            OneOrMoreOf("").
+        EndGroup().
+        ZeroOrOneOf("")
+}
+
+// zone matches the offset of a time from UTC
+func zone(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            AnyFrom("zZ").
+        Or().
+            AnyFrom("+-").
+            AnyFrom("0-2").
+            AnyFrom("0-9").
+            BeginNonCaptureGroup().
+                ZeroOrOneOf(":").
+                AnyFrom("0-5").
+                AnyFrom("0-9").
+            EndGroup().
+            ZeroOrOneOf("")
+    }
+    return newFragment().
+        Characters("Z").
+    Or().
+        AnyFrom("+-").
+        EitherOr("[01][0-9]", "2[0-3]").
+        Characters(":").
+        AnyFrom("0-5").
+        AnyFrom("0-9")
+}
+
+// Time matches an ISO 8601 time of day in the extended hh:mm:ss format, with an optional
+// fraction of a second and offset from UTC. The lenient variant also accepts the basic
+// format, missing seconds, commas before the fraction and offsets without minutes
+func Time(v Variant) *rejex.RejexBuilder {
+    return newFragment().
+        Pattern(timeOfDay(v)).
+        Pattern(zone(v)).
+        ZeroOrOneOf("")
+}
+
+// DateTime matches an ISO 8601 date and time separated by a 'T', with a required offset
+// from UTC as in RFC 3339. The lenient variant combines the lenient date and time, with
+// the offset optional and a space or a lowercase 't' also accepted as the separator
+func DateTime(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            Pattern(Date(Lenient)).
+            AnyFrom("Tt ").
+            Pattern(Time(Lenient))
+    }
+    return newFragment().
+        Pattern(Date(Strict)).
+        Characters("T").
+        Pattern(timeOfDay(Strict)).
+        Pattern(zone(Strict))
+}
+
+// HexColor matches a CSS hexadecimal color of 3 or 6 digits following a '#'. The lenient
+// variant also accepts 4 and 8 digits with an alpha channel and a missing '#'
+func HexColor(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            ZeroOrOneOf("#").
+            BeginNonCaptureGroup().
+                Pattern(hex(8)).
+            Or().
+                Pattern(hex(6)).
+            Or().
+                Pattern(hex(4)).
+            Or().
+                Pattern(hex(3)).
+            EndGroup()
+    }
+    return newFragment().
+        Characters("#").
+        BeginNonCaptureGroup().
+            Pattern(hex(6)).
+        Or().
+            Pattern(hex(3)).
+        EndGroup()
+}
+
+// JWT matches a JSON Web Token in the compact serialization, made of a header and payload
+// which are base64url encoded JSON objects followed by a signature. The lenient variant
+// accepts any base64url segments with padding and an empty signature
+func JWT(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        segment := newFragment().
+            AnyFrom(base64URLChars).
+            OneOrMoreOf("").
+            ZeroOrMoreOf("=")
+        return newFragment().
+            Pattern(segment).
+            EscapedCharacters(".").
+            Pattern(segment).
+            EscapedCharacters(".").
+            AnyFrom(base64URLChars + "=").
+            ZeroOrMoreOf("")
+    }
+
+    // encoded JSON objects start with '{"'
+    object := newFragment().
+        Characters("eyJ").
+        AnyFrom(base64URLChars).
+        OneOrMoreOf("")
+    return newFragment().
+        Pattern(object).
+        EscapedCharacters(".").
+        Pattern(object).
+        EscapedCharacters(".").
+        AnyFrom(base64URLChars).
+        OneOrMoreOf("")
+}
+
+// Email matches an email address with a dot-atom local part and a fully qualified domain
+// name. The lenient variant accepts anything without whitespace around a single '@',
+// followed by at least 2 domain labels
+func Email(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            Not().AnyFrom(`\s@`).
+            OneOrMoreOf("").
+            Characters("@").
+            Not().AnyFrom(`\s@.`).
+            OneOrMoreOf("").
+            BeginNonCaptureGroup().
+                EscapedCharacters(".").
+                Not().AnyFrom(`\s@.`).
+                OneOrMoreOf("").
+            EndGroup().
+            OneOrMoreOf("")
+    }
+    return newFragment().
+        AnyFrom(atomChars).
+        OneOrMoreOf("").
+        BeginNonCaptureGroup().
+            EscapedCharacters(".").
+            AnyFrom(atomChars).
+            OneOrMoreOf("").
+        EndGroup().
+        ZeroOrMoreOf("").
+        Characters("@").
+        Pattern(hostname())
+}
+
+// URL matches an absolute http, https or ftp URL whose host is a domain name, localhost,
+// an IPv4 address or a bracketed IPv6 address, with an optional port, path, query and
+// fragment. The lenient variant accepts any scheme followed by '://' and a host, and
+// anything without whitespace after the host
+func URL(v Variant) *rejex.RejexBuilder {
+    if v == Lenient {
+        return newFragment().
+            AnyFrom("A-Za-z").
+            AnyFrom("A-Za-z0-9+.-").
+            ZeroOrMoreOf("").
+            Characters("://").
+            Not().AnyFrom(`\s/?#`).
+            OneOrMoreOf("").
+            BeginNonCaptureGroup().
+                AnyFrom("/?#").
+                Not().AnyWhitespace().
+                ZeroOrMoreOf("").
+            EndGroup().
+            ZeroOrOneOf("")
+    }
+
+    port := newFragment().EitherOr(
+        "6553[0-5]", "655[0-2][0-9]", "65[0-4][0-9]{2}", "6[0-4][0-9]{3}", "[1-5][0-9]{4}", "[1-9][0-9]{0,3}", "0",
+    )
+    return newFragment().
+        EitherOr("https?", "ftp").
+        Characters("://").
+        BeginNonCaptureGroup().
+            Pattern(hostname()).
+        Or().
+            Characters("localhost").
+        Or().
+            Pattern(IPv4(Strict)).
+        Or().
+            EscapedCharacters("[").
+            Pattern(IPv6(Strict)).
+            EscapedCharacters("]").
+        EndGroup().
+        BeginNonCaptureGroup().
+            Characters(":").
+            Pattern(port).
+        EndGroup().
+        ZeroOrOneOf("").
+        BeginNonCaptureGroup().
+            Characters("/").
+            AnyFrom(pathChars).
+            ZeroOrMoreOf("").
+        EndGroup().
+        ZeroOrMoreOf("").
+        BeginNonCaptureGroup().
+            EscapedCharacters("?").
+            AnyFrom(pathChars + "/?").
+            ZeroOrMoreOf("").
+        EndGroup().
+        ZeroOrOneOf("").
+        BeginNonCaptureGroup().
+            Characters("#").
+            AnyFrom(pathChars + "/?").
+            ZeroOrMoreOf("").
+        EndGroup().
+        ZeroOrOneOf("")
+}
@@ -0,0 +1,51 @@
+package rejex
+
+import (
+    "fmt"
+    "strings"
+)
+
+// pythonFlagNames holds the names of the flag constants of the re module
+var pythonFlagNames = map[RejexFlag]string{
+    CaseInsensitiveFlag: "I",
+    MultilineFlag: "M",
+    SingleLineFlag: "S",
+    VerboseFlag: "X",
+    ASCIIFlag: "A",
+    LocaleFlag: "L",
+    UnicodeFlag: "U",
+}
+
+// pythonCompile is the Python flavor written as a call to re.compile with a raw string
+// literal, the flags are passed as the constants of the re module
+type pythonCompile struct{ pythonFlavor }
+
+func (f pythonCompile) RenderLiteral(c rune, inClass bool) string {
+    if c == '"' {
+        return `\"`
+    }
+    return f.pythonFlavor.RenderLiteral(c, inClass)
+}
+
+// RenderFlags writes the pattern as bytes when the locale flag is set, which Python
+// only allows for patterns of bytes
+func (pythonCompile) RenderFlags(flags []RejexFlag, unicode bool) (string, string) {
+    prefix := `re.compile(r"`
+    var names []string
+    for _, f := range flags {
+        if f == LocaleFlag {
+            prefix = `re.compile(rb"`
+        }
+        names = append(names, "re."+pythonFlagNames[f])
+    }
+    if len(names) == 0 {
+        return prefix, `")`
+    }
+    return prefix, fmt.Sprintf(`", %s)`, strings.Join(names, " | "))
+}
+
+// BuildCompile constructs the final regex as a Python expression compiling it, such as
+// `re.compile(r"a+", re.I | re.M)`, and returns it along with a list of errors
+func (r *RejexBuilder) BuildCompile() (string, []RejexError) {
+    return r.BuildFor(pythonCompile{})
+}
@@ -0,0 +1,98 @@
+package rejex
+
+import "testing"
+
+// TestPythonSyntax checks the parsing of the syntax which is specific to the re module,
+// rendered in the Python and Go flavors
+func TestPythonSyntax(t *testing.T) {
+    tests := []struct {
+        pattern string
+        python string
+        golang string
+    }{
+        {`(?P<n>a)(?P=n)`, `(?P<n>a)(?P=n)`, ``},
+        {`é\U0001F600`, `é😀`, `é😀`},
+        {`a\Z`, `a\Z`, `a\z`},
+        {`(?x) a b  # comment
+            [ ]c`, `(?x)ab[ ]c`, ``},
+        {`(?x:a b)c d`, `(?x:ab)c d`, ``},
+        {`(?a)\w`, `(?a)\w`, ``},
+        {`(?i)a|b`, `(?i)a|b`, `(?i)a|b`},
+        {`a{,3}`, `a{0,3}`, `a{0,3}`},
+        {`a{,}b`, `a*b`, `a*b`},
+    }
+    for _, test := range tests {
+        r := fromString(PythonFlavor, test.pattern)
+        if got, errs := r.Build(); failed(errs) || got != test.python {
+            t.Errorf("%s is rendered as %s %v, want %s", test.pattern, got, errs, test.python)
+        }
+        if test.golang == "" {
+            continue
+        }
+        if got, errs := r.BuildFor(GoFlavor); failed(errs) || got != test.golang {
+            t.Errorf("%s is rendered in the GO flavor as %s %v, want %s", test.pattern, got, errs, test.golang)
+        }
+    }
+}
+
+// TestPythonSyntaxErrors checks that the syntax which the re module rejects is reported
+func TestPythonSyntaxErrors(t *testing.T) {
+    for _, pattern := range []string{
+        `(?<n>a)`,
+        `a(?i)b`,
+        `\z`,
+        `\e`,
+        `\x{41}`,
+        `(?P<n>a)\k<n>`,
+        `\p{L}`,
+        `(?au)a`,
+        `(?L)(?u:a)`,
+        `(?L)é`,
+        `(?L)[a-é]`,
+    } {
+        if _, errs := fromString(PythonFlavor, pattern).Build(); !failed(errs) {
+            t.Errorf("%s is accepted in the PYTHON flavor", pattern)
+        }
+    }
+}
+
+// TestPythonMatches checks the matching of patterns with the flags of the re module
+func TestPythonMatches(t *testing.T) {
+    tests := []struct {
+        pattern string
+        input string
+        want bool
+    }{
+        {`(?x)^a b$`, "ab", true},
+        {`(?x)^a\ b$`, "a b", true},
+        {`(?P<n>a|b)(?P=n)`, "ab", false},
+        {`(?P<n>a|b)(?P=n)`, "bb", true},
+        {`^a\Z`, "a\n", false},
+        {`(?i)straße`, "STRASSE", false},
+        {`(?s)a.b`, "a\nb", true},
+        {`^a{,2}$`, "", true},
+        {`^a{,2}$`, "aaa", false},
+    }
+    for _, test := range tests {
+        got, err := fromString(PythonFlavor, test.pattern).Matches(test.input)
+        if err != nil || got != test.want {
+            t.Errorf("%s matches %q: %v %v, want %v", test.pattern, test.input, got, err, test.want)
+        }
+    }
+}
+
+// TestPythonLocaleBuild checks that the locale flag limits the characters of a pattern to
+// ASCII whether it is written as a string or as bytes
+func TestPythonLocaleBuild(t *testing.T) {
+    r := NewPythonRejex(true).AddFlags(LocaleFlag).Characters("é").Builder()
+    if got, errs := r.Build(); !failed(errs) {
+        t.Errorf("%s is built without errors", got)
+    }
+    if got, errs := r.BuildCompile(); !failed(errs) {
+        t.Errorf("%s is built without errors", got)
+    }
+    r = NewPythonRejex().AddFlags(LocaleFlag).Characters("e").Builder()
+    if got, errs := r.Build(); len(errs) > 0 || got != `(?L)e` {
+        t.Errorf("built %s %v, want (?L)e", got, errs)
+    }
+}
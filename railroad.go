@@ -0,0 +1,413 @@
+package rejex
+
+import (
+    "fmt"
+    "html"
+    "io"
+    "strings"
+)
+
+// Dimensions of the elements of a railroad diagram, in pixels
+const (
+    railCharWidth = 7.5
+    railBoxHeight = 24.0
+    railPadding = 10.0
+    railGap = 16.0
+    railArc = 10.0
+    railSeparation = 12.0
+    railLabelHeight = 16.0
+    railMargin = 20.0
+)
+
+const railStyle = `<style>
+path { fill: none; stroke: #333; stroke-width: 1.5; }
+rect { stroke: #333; stroke-width: 1.5; }
+text { font-family: monospace; font-size: 12px; fill: #111; }
+text.label { font-size: 11px; fill: #555; }
+rect.literal { fill: #fff7d6; }
+rect.class { fill: #e3efff; }
+rect.anchor { fill: #eee; }
+rect.backref { fill: #f0e3ff; }
+rect.raw { fill: #ffe3e3; }
+rect.group { fill: none; stroke: #888; }
+rect.capture { fill: none; stroke: #2a6; }
+rect.lookaround { fill: #e9f9ee; stroke: #2a6; stroke-dasharray: 6 3; }
+rect.negative-lookaround { fill: #fdecec; stroke: #c33; stroke-dasharray: 6 3; }
+circle { fill: #333; }
+</style>
+`
+
+// railItem is an element of a railroad diagram. Its size is measured from the line
+// running through it, up and down are the heights above and below the line
+type railItem interface {
+    size() (width, up, down float64)
+    draw(w *railWriter, x, y float64)
+}
+
+// railWriter writes the SVG elements of a railroad diagram
+type railWriter struct {
+    strings.Builder
+}
+
+func (w *railWriter) path(format string, a ...interface{}) {
+    fmt.Fprintf(w, "<path d=\"%s\"/>\n", fmt.Sprintf(format, a...))
+}
+
+func (w *railWriter) line(x1, y, x2 float64) {
+    if x2 > x1 {
+        w.path("M%g %gH%g", x1, y, x2)
+    }
+}
+
+func (w *railWriter) text(class string, x, y float64, s string) {
+    if class != "" {
+        class = fmt.Sprintf(" class=\"%s\"", class)
+    }
+    fmt.Fprintf(w, "<text%s x=\"%g\" y=\"%g\" text-anchor=\"middle\">%s</text>\n",
+        class, x, y, html.EscapeString(s))
+}
+
+func textWidth(s string) float64 {
+    return float64(len([]rune(s))) * railCharWidth
+}
+
+// railText is a box with text in it, for segments which match without any nesting
+type railText struct {
+    label string
+    class string
+}
+
+func (t *railText) size() (float64, float64, float64) {
+    return textWidth(t.label) + 2*railPadding, railBoxHeight / 2, railBoxHeight / 2
+}
+
+func (t *railText) draw(w *railWriter, x, y float64) {
+    width, _, _ := t.size()
+    rx := 0.0
+    if t.class == "literal" || t.class == "anchor" {
+        rx = railBoxHeight / 2
+    }
+    fmt.Fprintf(w, "<rect class=\"%s\" x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" rx=\"%g\"/>\n",
+        t.class, x, y-railBoxHeight/2, width, railBoxHeight, rx)
+    w.text("", x+width/2, y+4, t.label)
+}
+
+// railSequence is a list of items matched one after the other
+type railSequence struct {
+    items []railItem
+}
+
+func (s *railSequence) size() (float64, float64, float64) {
+    if len(s.items) == 0 {
+        return railGap, 0, 0
+    }
+    var width, up, down float64
+    for i, item := range s.items {
+        w, u, d := item.size()
+        if i > 0 {
+            width += railGap
+        }
+        width += w
+        up, down = maxFloat(up, u), maxFloat(down, d)
+    }
+    return width, up, down
+}
+
+func (s *railSequence) draw(w *railWriter, x, y float64) {
+    if len(s.items) == 0 {
+        w.line(x, y, x+railGap)
+    }
+    for i, item := range s.items {
+        if i > 0 {
+            w.line(x, y, x+railGap)
+            x += railGap
+        }
+        item.draw(w, x, y)
+        width, _, _ := item.size()
+        x += width
+    }
+}
+
+// railChoice is a list of alternatives branching from the line, the first one on it
+type railChoice struct {
+    items []railItem
+}
+
+func (c *railChoice) size() (float64, float64, float64) {
+    var width, up, down float64
+    for i, item := range c.items {
+        w, u, d := item.size()
+        width = maxFloat(width, w)
+        if i == 0 {
+            up, down = u, d
+        } else {
+            down += railSeparation + u + d
+        }
+    }
+    return width + 4*railArc, up, down
+}
+
+func (c *railChoice) draw(w *railWriter, x, y float64) {
+    width, _, _ := c.size()
+    a := railArc
+    end := x + width
+    by := y
+    var prevDown float64
+    for i, item := range c.items {
+        iw, u, d := item.size()
+        if i == 0 {
+            w.line(x, y, x+2*a)
+        } else {
+            by += prevDown + railSeparation + u
+            w.path("M%g %ga%g %g 0 0 1 %g %gV%ga%g %g 0 0 0 %g %g",
+                x, y, a, a, a, a, by-a, a, a, a, a)
+            w.path("M%g %ga%g %g 0 0 0 %g %gV%ga%g %g 0 0 1 %g %g",
+                end-2*a, by, a, a, a, -a, y+a, a, a, a, -a)
+        }
+        item.draw(w, x+2*a, by)
+        w.line(x+2*a+iw, by, end-2*a)
+        if i == 0 {
+            w.line(end-2*a, y, end)
+        }
+        prevDown = d
+    }
+}
+
+// railLoop is an item which is repeated or skipped, with a loop running back under it
+// for repetitions and a path over it to skip it
+type railLoop struct {
+    item railItem
+    label string
+    repeat, optional bool
+}
+
+func (l *railLoop) size() (float64, float64, float64) {
+    width, up, down := l.item.size()
+    if l.optional {
+        up += railArc
+    }
+    if l.repeat {
+        down += railArc
+        if l.label != "" {
+            down += railLabelHeight
+        }
+    }
+    return width + 4*railArc, up, down
+}
+
+func (l *railLoop) draw(w *railWriter, x, y float64) {
+    iw, iu, id := l.item.size()
+    width, _, _ := l.size()
+    a := railArc
+    end := x + width
+    w.line(x, y, x+2*a)
+    l.item.draw(w, x+2*a, y)
+    w.line(x+2*a+iw, y, end)
+
+    if l.repeat {
+        bottom := y + id + a
+        w.path("M%g %ga%g %g 0 0 1 %g %gV%ga%g %g 0 0 1 %g %gH%ga%g %g 0 0 1 %g %gV%ga%g %g 0 0 1 %g %g",
+            x+2*a+iw, y, a, a, a, a, bottom-a, a, a, -a, a, x+2*a, a, a, -a, -a, y+a, a, a, a, -a)
+        if l.label != "" {
+            w.text("label", x+width/2, bottom+railLabelHeight-4, l.label)
+        }
+    }
+    if l.optional {
+        top := y - iu - a
+        w.path("M%g %ga%g %g 0 0 0 %g %gV%ga%g %g 0 0 1 %g %gH%ga%g %g 0 0 1 %g %gV%ga%g %g 0 0 0 %g %g",
+            x, y, a, a, a, -a, top+a, a, a, a, -a, end-2*a, a, a, a, a, y-a, a, a, a, a)
+    }
+}
+
+// railBox is a group drawn as a box around its body, labeled above it
+type railBox struct {
+    item railItem
+    label string
+    class string
+}
+
+func (b *railBox) size() (float64, float64, float64) {
+    width, up, down := b.item.size()
+    width = maxFloat(width, textWidth(b.label))
+    return width + 2*railPadding, up + railPadding + railLabelHeight, down + railPadding
+}
+
+func (b *railBox) draw(w *railWriter, x, y float64) {
+    width, up, down := b.size()
+    iw, iu, _ := b.item.size()
+    top := y - iu - railPadding
+    fmt.Fprintf(w, "<rect class=\"%s\" x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" rx=\"4\"/>\n",
+        b.class, x, top, width, up+down-railLabelHeight)
+    w.text("label", x+width/2, top-4, b.label)
+    inner := x + (width-iw)/2
+    w.line(x, y, inner)
+    b.item.draw(w, inner, y)
+    w.line(inner+iw, y, x+width)
+}
+
+func maxFloat(a, b float64) float64 {
+    if a > b {
+        return a
+    }
+    return b
+}
+
+// railroad converts the nodes of a syntax tree into the items of a railroad diagram
+type railroad struct {
+    e explainer
+    numbers *groupNumbers
+}
+
+func (rr *railroad) item(n Node) railItem {
+    switch n := n.(type) {
+    case *Concat:
+        s := &railSequence{}
+        for _, sub := range n.Nodes {
+            s.items = append(s.items, rr.item(sub))
+        }
+        return s
+    case *Alternation:
+        c := &railChoice{}
+        for _, alt := range n.Alternatives {
+            c.items = append(c.items, rr.item(alt))
+        }
+        return c
+    case *Literal:
+        return &railText{fmt.Sprintf("%q", n.Text), "literal"}
+    case *Raw:
+        return &railText{n.Text, "raw"}
+    case *Class:
+        label := classNoun(n).one
+        for _, article := range []string{"a ", "an ", "any "} {
+            label = strings.TrimPrefix(label, article)
+        }
+        return &railText{label, "class"}
+    case *Repeat:
+        return rr.repeat(n)
+    case *Group:
+        return rr.group(n)
+    case *Assertion:
+        return &railText{rr.e.assertion(n), "anchor"}
+    case *Backref:
+        if n.Name != "" {
+            return &railText{fmt.Sprintf("group '%s' again", n.Name), "backref"}
+        }
+        return &railText{fmt.Sprintf("group #%d again", rr.numbers.refs[n]), "backref"}
+    case *Recursion:
+        switch num := rr.numbers.calls[n]; {
+        case n.Name != "":
+            return &railText{fmt.Sprintf("group '%s' recursively", n.Name), "backref"}
+        case num == 0:
+            return &railText{"whole pattern recursively", "backref"}
+        default:
+            return &railText{fmt.Sprintf("group #%d recursively", num), "backref"}
+        }
+    case *Verb:
+        return &railText{render(PCREFlavor, n), "anchor"}
+    }
+    return &railSequence{}
+}
+
+func (rr *railroad) repeat(n *Repeat) railItem {
+    l := &railLoop{
+        item: rr.item(n.Sub),
+        optional: n.Min == 0,
+        repeat: n.Max != 0 && n.Max != 1,
+    }
+    switch {
+    case n.Min == n.Max:
+        l.label = fmt.Sprintf("%d times", n.Min)
+    case n.Max == -1:
+        l.label = fmt.Sprintf("%d+ times", n.Min)
+    default:
+        l.label = fmt.Sprintf("%d-%d times", n.Min, n.Max)
+    }
+    switch {
+    case n.Mode == Possessive:
+        l.label += ", possessive"
+    case (n.Mode == Lazy) != rr.e.ungreedy:
+        l.label += ", lazy"
+    }
+    if !l.repeat {
+        l.label = ""
+    }
+    return l
+}
+
+func (rr *railroad) group(n *Group) railItem {
+    if n.Kind == ConditionalGroup {
+        yes, no := conditionalBranches(n)
+        c := &railChoice{items: []railItem{rr.item(yes), &railSequence{}}}
+        if no != nil {
+            c.items[1] = rr.item(no)
+        }
+        label := fmt.Sprintf("if group #%d captured", rr.numbers.conds[n])
+        if n.Name != "" {
+            label = fmt.Sprintf("if group '%s' captured", n.Name)
+        }
+        return &railBox{c, label, "group"}
+    }
+    body := rr.item(n.Body)
+    switch n.Kind {
+    case CaptureGroup:
+        return &railBox{body, fmt.Sprintf("group #%d", rr.numbers.groups[n]), "capture"}
+    case NamedCaptureGroup:
+        return &railBox{body, fmt.Sprintf("group '%s' #%d", n.Name, rr.numbers.groups[n]), "capture"}
+    case FlagGroup:
+        var flags []string
+        state := "+"
+        for _, f := range n.Flags {
+            if f == '-' {
+                state = "-"
+                continue
+            }
+            flags = append(flags, state+flagPhrases[f])
+        }
+        return &railBox{body, strings.Join(flags, ", "), "group"}
+    case AtomicGroup:
+        return &railBox{body, "atomic", "group"}
+    case BranchResetGroup:
+        return &railBox{body, "branch reset", "group"}
+    case PosLookahead:
+        return &railBox{body, "followed by", "lookaround"}
+    case NegLookahead:
+        return &railBox{body, "not followed by", "negative-lookaround"}
+    case PosLookbehind:
+        return &railBox{body, "preceded by", "lookaround"}
+    case NegLookbehind:
+        return &railBox{body, "not preceded by", "negative-lookaround"}
+    case DefineGroup:
+        return &railBox{body, "definitions", "group"}
+    }
+    return body
+}
+
+// RenderRailroad writes a standalone SVG railroad diagram of the regex. Groups are
+// drawn as labeled boxes, quantifiers as loops labeled with their bounds, alternatives
+// as branches and lookarounds as shaded boxes
+func (r *RejexBuilder) RenderRailroad(w io.Writer) error {
+    root := r.Tree()
+    rr := railroad{
+        e: explainer{flags: r.flags, ungreedy: r.flags[UngreedyFlag]},
+        numbers: numberGroups(root),
+    }
+    item := rr.item(root)
+    width, up, down := item.size()
+    width += 2*railMargin + 2*railGap
+    height := up + down + 2*railMargin
+
+    var out railWriter
+    fmt.Fprintf(&out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\">\n",
+        width, height, width, height)
+    out.WriteString(railStyle)
+    y := railMargin + up
+    fmt.Fprintf(&out, "<circle cx=\"%g\" cy=\"%g\" r=\"4\"/>\n", railMargin, y)
+    out.line(railMargin, y, railMargin+railGap)
+    item.draw(&out, railMargin+railGap, y)
+    out.line(width-railMargin-railGap, y, width-railMargin)
+    fmt.Fprintf(&out, "<circle cx=\"%g\" cy=\"%g\" r=\"4\"/>\n", width-railMargin, y)
+    out.WriteString("</svg>\n")
+
+    _, err := io.WriteString(w, out.String())
+    return err
+}
@@ -0,0 +1,129 @@
+package rejex
+
+import (
+    "bytes"
+    "encoding/xml"
+    "errors"
+    "io"
+    "strconv"
+    "testing"
+)
+
+// svgElement is an element of a rendered diagram, with the attributes the tests check
+type svgElement struct {
+    name string
+    attrs map[string]string
+    text string
+}
+
+// parseSVG decodes a diagram into the list of its elements, failing if it is not
+// well formed XML
+func parseSVG(t *testing.T, b []byte) []svgElement {
+    var elements []svgElement
+    open := false
+    dec := xml.NewDecoder(bytes.NewReader(b))
+    for {
+        tok, err := dec.Token()
+        if err == io.EOF {
+            return elements
+        }
+        if err != nil {
+            t.Fatalf("diagram is not well formed: %v\n%s", err, b)
+        }
+        switch tok := tok.(type) {
+        case xml.StartElement:
+            e := svgElement{name: tok.Name.Local, attrs: map[string]string{}}
+            for _, attr := range tok.Attr {
+                e.attrs[attr.Name.Local] = attr.Value
+            }
+            elements = append(elements, e)
+            open = true
+        case xml.EndElement:
+            open = false
+        case xml.CharData:
+            if open {
+                elements[len(elements)-1].text += string(tok)
+            }
+        }
+    }
+}
+
+func attrFloat(t *testing.T, e svgElement, name string) float64 {
+    f, err := strconv.ParseFloat(e.attrs[name], 64)
+    if err != nil {
+        t.Fatalf("%s of %s is not a number: %v", name, e.name, err)
+    }
+    return f
+}
+
+func TestRenderRailroad(t *testing.T) {
+    tests := []struct {
+        flavor RejexFlavor
+        pattern string
+        labels []string
+        classes []string
+    }{
+        {GoFlavor, `^(?P<year>\d{4})-(?:Jan|Feb)`,
+            []string{"start of text", "group 'year' #1", "4 times", "digit", `"-"`, `"Jan"`, `"Feb"`},
+            []string{"anchor", "capture", "class", "literal"}},
+        {PCREFlavor, `(?<=<)(a)+?\1(?!&)(?>b)*+`,
+            []string{"preceded by", `"<"`, "group #1", "1+ times, lazy", "group #1 again", "not followed by", `"&"`, "atomic", "0+ times, possessive"},
+            []string{"lookaround", "negative-lookaround", "backref", "group"}},
+        {PCREFlavor, `(?(DEFINE)(?<d>\d))(?&d)(?R)?(?(1)a|b)(?i:c)`,
+            []string{"definitions", "group 'd' recursively", "whole pattern recursively", "if group #1 captured", "+case insensitive"},
+            []string{"group", "backref"}},
+        {GoFlavor, ``, nil, nil},
+    }
+    for _, test := range tests {
+        var b bytes.Buffer
+        if err := fromString(test.flavor, test.pattern).RenderRailroad(&b); err != nil {
+            t.Fatal(err)
+        }
+        elements := parseSVG(t, b.Bytes())
+        if len(elements) == 0 || elements[0].name != "svg" {
+            t.Fatalf("%s is not rendered as an SVG document:\n%s", test.pattern, b.String())
+        }
+        width, height := attrFloat(t, elements[0], "width"), attrFloat(t, elements[0], "height")
+
+        texts, classes := map[string]bool{}, map[string]bool{}
+        for _, e := range elements[1:] {
+            switch e.name {
+            case "text":
+                texts[e.text] = true
+                if x, y := attrFloat(t, e, "x"), attrFloat(t, e, "y"); x < 0 || x > width || y < 0 || y > height {
+                    t.Errorf("%q of %s is drawn outside of the diagram at %g,%g", e.text, test.pattern, x, y)
+                }
+            case "rect":
+                classes[e.attrs["class"]] = true
+                x, y := attrFloat(t, e, "x"), attrFloat(t, e, "y")
+                w, h := attrFloat(t, e, "width"), attrFloat(t, e, "height")
+                if x < 0 || y < 0 || x+w > width || y+h > height {
+                    t.Errorf("box of %s is drawn outside of the diagram at %g,%g", test.pattern, x, y)
+                }
+            }
+        }
+        for _, label := range test.labels {
+            if !texts[label] {
+                t.Errorf("diagram of %s has no %q label, has %v", test.pattern, label, texts)
+            }
+        }
+        for _, class := range test.classes {
+            if !classes[class] {
+                t.Errorf("diagram of %s has no %s box", test.pattern, class)
+            }
+        }
+    }
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+    return 0, errors.New("write failed")
+}
+
+func TestRenderRailroadWriteError(t *testing.T) {
+    if err := NewRejex().Characters("a").RenderRailroad(failingWriter{}); err == nil {
+        t.Errorf("error of the writer is not returned")
+    }
+}
@@ -0,0 +1,376 @@
+package rejex
+
+import (
+    "fmt"
+    "math"
+    "math/rand"
+    "strings"
+)
+
+// Complexity is the worst case time a backtracking regex engine takes to fail to
+// match an input, relative to the length of the input
+type Complexity int
+
+const (
+    LinearComplexity Complexity = iota
+    PolynomialComplexity
+    ExponentialComplexity
+)
+
+func (c Complexity) String() string {
+    switch c {
+    case PolynomialComplexity:
+        return "polynomial"
+    case ExponentialComplexity:
+        return "exponential"
+    }
+    return "linear"
+}
+
+// ReDoSFinding is a segment of a regex which makes backtracking engines take polynomial
+// or exponential time on some inputs. The AttackString is made of the Prefix, followed by
+// the Pump repeated many times and the Suffix which makes the match fail
+type ReDoSFinding struct {
+    Segment string
+    Position int
+    Cause string
+    Complexity Complexity
+    Degree int
+    Prefix, Pump, Suffix string
+    AttackString string
+
+    node Node
+}
+
+// ReDoSReport is the result of analyzing a regex for catastrophic backtracking, the
+// Complexity is the worst complexity of all the findings
+type ReDoSReport struct {
+    Complexity Complexity
+    Degree int
+    Findings []ReDoSFinding
+}
+
+const (
+    // redosSeed seeds the random source used to generate pumps and prefixes
+    redosSeed = 1
+    // exponentialPumps and polynomialPumps are the number of times the pump is repeated
+    // in the attack strings of each complexity
+    exponentialPumps = 30
+    polynomialPumps = 5000
+)
+
+// failingSuffixes are the characters tried after the pumps to make the match fail
+var failingSuffixes = []string{"!", "\x00", "\n", " ", "a", "0", "-"}
+
+// redosCandidate is a segment which could cause catastrophic backtracking when the
+// pump is repeated, to be confirmed by measuring the matching time
+type redosCandidate struct {
+    node Node
+    cause string
+    pump string
+    exponential bool
+}
+
+// analyzer looks for catastrophic backtracking in a syntax tree
+type analyzer struct {
+    r *RejexBuilder
+    root Node
+    m *matcher
+    g *generator
+    parents map[Node]Node
+}
+
+// examplesOf returns up to n distinct strings matched by a node of the tree, shortest first
+func (a *analyzer) examplesOf(n Node, count int) []string {
+    sub := newMatcher(a.r.flavor, n, a.r.flags)
+    var examples []string
+    seen := map[string]bool{}
+    for attempt := 0; attempt < maxAttempts && len(examples) < count; attempt++ {
+        s := a.g.generate(n)
+        if s != "" && !seen[s] && sub.fullMatch(s) {
+            seen[s] = true
+            examples = append(examples, s)
+        }
+    }
+    for i := 1; i < len(examples); i++ {
+        for j := i; j > 0 && len(examples[j]) < len(examples[j-1]); j-- {
+            examples[j], examples[j-1] = examples[j-1], examples[j]
+        }
+    }
+    return examples
+}
+
+// prefixOf generates a string matched by the nodes which precede a node in the tree
+func (a *analyzer) prefixOf(n Node) string {
+    var before []Node
+    for child, parent := n, a.parents[n]; parent != nil; child, parent = parent, a.parents[parent] {
+        concat, ok := parent.(*Concat)
+        if !ok {
+            continue
+        }
+        for i, sub := range concat.Nodes {
+            if sub == child {
+                before = append(append([]Node{}, concat.Nodes[:i]...), before...)
+                break
+            }
+        }
+    }
+    return a.g.generate(&Concat{before})
+}
+
+// nestedCandidates finds repeated bodies which can match a string in more than one way,
+// either because of quantifiers nested in them or ambiguous alternatives
+func (a *analyzer) nestedCandidates(rep *Repeat) []redosCandidate {
+    if rep.Max != -1 && rep.Max < 2 {
+        return nil
+    }
+    var candidates []redosCandidate
+
+    var nested bool
+    var alternations []*Alternation
+    walkNodes(rep.Sub, func(n Node) {
+        switch n := n.(type) {
+        case *Repeat:
+            nested = nested || n.Max == -1
+        case *Alternation:
+            alternations = append(alternations, n)
+        }
+    })
+
+    body := newMatcher(a.r.flavor, rep.Sub, a.r.flags)
+    if nested {
+        for _, w := range a.examplesOf(rep.Sub, 5) {
+            // a body which matches both w and ww splits repetitions of w in many ways
+            if body.fullMatch(w + w) {
+                candidates = append(candidates, redosCandidate{rep, "nested quantifiers", w, true})
+                break
+            }
+        }
+    }
+
+    for _, alt := range alternations {
+        for i, first := range alt.Alternatives {
+            for _, second := range alt.Alternatives[i+1:] {
+                other := newMatcher(a.r.flavor, second, a.r.flags)
+                for _, w := range a.examplesOf(first, 5) {
+                    if !other.fullMatch(w) {
+                        continue
+                    }
+                    a.g.overrides = map[Node]Node{alt: &Literal{w}}
+                    pump := a.g.generate(rep.Sub)
+                    a.g.overrides = nil
+                    if body.fullMatch(pump) {
+                        candidates = append(candidates, redosCandidate{rep, "ambiguous alternation", pump, true})
+                        return candidates
+                    }
+                }
+            }
+        }
+    }
+    return candidates
+}
+
+// repeatedSet returns the characters repeated by an unbounded quantifier of a single
+// character, and false for other nodes
+func (a *analyzer) repeatedSet(n Node) (runeSet, bool) {
+    rep, ok := n.(*Repeat)
+    if !ok || rep.Max != -1 {
+        return nil, false
+    }
+    switch sub := rep.Sub.(type) {
+    case *Class:
+        return a.m.classSet(sub, a.m.flags), true
+    case *Literal:
+        if c := []rune(sub.Text); len(c) == 1 {
+            return runeSet{{c[0], c[0]}}, true
+        }
+    }
+    return nil, false
+}
+
+// overlappingCandidates finds consecutive unbounded quantifiers which can match the
+// same characters, each of them can take any share of a run of those characters
+func (a *analyzer) overlappingCandidates(concat *Concat) []redosCandidate {
+    var candidates []redosCandidate
+    for i := 0; i < len(concat.Nodes); i++ {
+        set, ok := a.repeatedSet(concat.Nodes[i])
+        if !ok {
+            continue
+        }
+        j := i + 1
+        for ; j < len(concat.Nodes); j++ {
+            next, ok := a.repeatedSet(concat.Nodes[j])
+            if !ok || len(set.intersect(next)) == 0 {
+                break
+            }
+            set = set.intersect(next)
+        }
+        if j-i > 1 {
+            pump := string(set.pick(a.g.rnd))
+            candidates = append(candidates, redosCandidate{concat.Nodes[i], "overlapping quantifiers", pump, false})
+            i = j - 1
+        }
+    }
+    return candidates
+}
+
+// searchCandidate returns an unbounded quantifier as a candidate, which backtracks over
+// a run of the characters it matches at each position the search for a match starts at
+func (a *analyzer) searchCandidate(rep *Repeat) []redosCandidate {
+    if rep.Max != -1 {
+        return nil
+    }
+    for _, w := range a.examplesOf(rep.Sub, 1) {
+        return []redosCandidate{{rep, "retrying the search at each position", w, false}}
+    }
+    return nil
+}
+
+// steps returns the work done by the matcher to search for a match in an input, starting
+// at each position in turn like the engines do, and whether it gave up
+func (a *analyzer) steps(input string) (int, bool) {
+    a.m.find(input)
+    return a.m.searchSteps, a.m.gaveUp
+}
+
+// confirm measures how the matching time grows with the number of pumps and returns
+// the finding if it grows faster than linearly
+func (a *analyzer) confirm(c redosCandidate) (ReDoSFinding, bool) {
+    prefix := a.prefixOf(c.node)
+    short, long := 20, 40
+    if c.exponential {
+        short, long = 8, 16
+    }
+
+    for _, suffix := range failingSuffixes {
+        s1, gaveUp1 := a.steps(prefix + strings.Repeat(c.pump, short) + suffix)
+        s2, gaveUp2 := a.steps(prefix + strings.Repeat(c.pump, long) + suffix)
+        if gaveUp1 && !c.exponential {
+            continue
+        }
+
+        f := ReDoSFinding{
+            Segment: a.r.methodOf(c.node),
+            Position: a.r.positionOf(a.r.flavor, c.node),
+            Cause: c.cause,
+            Prefix: prefix,
+            Pump: c.pump,
+            Suffix: suffix,
+            node: c.node,
+        }
+        ratio := float64(s2) / float64(s1)
+        switch {
+        case gaveUp1 || gaveUp2 || ratio > 32:
+            f.Complexity = ExponentialComplexity
+            f.AttackString = prefix + strings.Repeat(c.pump, exponentialPumps) + suffix
+        case ratio >= 3:
+            f.Complexity = PolynomialComplexity
+            f.Degree = int(math.Round(math.Log2(ratio)))
+            f.AttackString = prefix + strings.Repeat(c.pump, polynomialPumps) + suffix
+        default:
+            continue
+        }
+        return f, true
+    }
+    return ReDoSFinding{}, false
+}
+
+// AnalyzeReDoS looks for segments of the regex which make backtracking engines, such
+// as those of the ECMA and Perl flavors, take polynomial or exponential time to fail to
+// match some inputs. The time is that of a search trying each position of the input
+// in turn. Each finding comes with an attack string which triggers it
+func (r *RejexBuilder) AnalyzeReDoS() ReDoSReport {
+    root := r.Tree()
+    a := analyzer{
+        r: r,
+        root: root,
+        m: newMatcher(r.flavor, root, r.flags),
+        parents: map[Node]Node{},
+    }
+    a.g = &generator{m: a.m, rnd: rand.New(rand.NewSource(redosSeed))}
+
+    // searches are only reported when the other candidates are not confirmed, as their
+    // work is part of that of every other finding
+    var candidates, searches []redosCandidate
+    valid := true
+    walkNodes(root, func(n Node) {
+        switch n := n.(type) {
+        case *Raw:
+            valid = false
+        case *Concat:
+            for _, sub := range n.Nodes {
+                a.parents[sub] = n
+            }
+            candidates = append(candidates, a.overlappingCandidates(n)...)
+        case *Alternation:
+            for _, alt := range n.Alternatives {
+                a.parents[alt] = n
+            }
+        case *Repeat:
+            a.parents[n.Sub] = n
+            candidates = append(candidates, a.nestedCandidates(n)...)
+            searches = append(searches, a.searchCandidate(n)...)
+        case *Group:
+            a.parents[n.Body] = n
+        }
+    })
+
+    var report ReDoSReport
+    if !valid {
+        return report
+    }
+    a.confirmAll(&report, candidates)
+    if len(report.Findings) == 0 {
+        a.confirmAll(&report, searches)
+    }
+    if report.Complexity == ExponentialComplexity {
+        report.Degree = 0
+    }
+    return report
+}
+
+// confirmAll adds the confirmed candidates to a report
+func (a *analyzer) confirmAll(report *ReDoSReport, candidates []redosCandidate) {
+    for _, c := range candidates {
+        f, ok := a.confirm(c)
+        if !ok {
+            continue
+        }
+        report.Findings = append(report.Findings, f)
+        if f.Complexity > report.Complexity {
+            report.Complexity = f.Complexity
+        }
+        if f.Degree > report.Degree {
+            report.Degree = f.Degree
+        }
+    }
+}
+
+// WarnReDoS makes Build() and BuildFor() report the findings of AnalyzeReDoS as warnings
+// for the flavors running on backtracking engines. The analysis matches attack strings
+// against the regex, which takes a while for large regexes
+func (r *RejexBuilder) WarnReDoS() *RejexBuilder {
+    r.warnReDoS = true
+    return r
+}
+
+// redosWarnings returns the findings of AnalyzeReDoS as warnings, positioned in the
+// pattern rendered for the flavor
+func (r *RejexBuilder) redosWarnings(flavor RejexFlavor) []RejexError {
+    var warnings []RejexError
+    for _, f := range r.AnalyzeReDoS().Findings {
+        complexity := f.Complexity.String()
+        if f.Complexity == PolynomialComplexity {
+            complexity = fmt.Sprintf("polynomial (n^%d)", f.Degree)
+        }
+        warnings = append(warnings, RejexError{
+            Position: r.positionOf(flavor, f.node),
+            Err: fmt.Sprintf(
+                "'%s()' can take %s time to fail because of %s, such as for %q repeated followed by %q",
+                f.Segment, complexity, f.Cause, f.Pump, f.Suffix,
+            ),
+            Warning: true,
+        })
+    }
+    return warnings
+}
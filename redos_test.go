@@ -0,0 +1,43 @@
+package rejex
+
+import "testing"
+
+func TestAnalyzeReDoS(t *testing.T) {
+    tests := []struct {
+        pattern string
+        complexity Complexity
+        degree int
+    }{
+        {`(a+)+$`, ExponentialComplexity, 0},
+        {`^(a|a)*$`, ExponentialComplexity, 0},
+        {`^(\w+\s?)*$`, ExponentialComplexity, 0},
+        {`a*a*$`, PolynomialComplexity, 3},
+        {`\s+$`, PolynomialComplexity, 2},
+        {`\d+-`, PolynomialComplexity, 2},
+        {`abc`, LinearComplexity, 0},
+        {`a+`, LinearComplexity, 0},
+        {`^\s+$`, LinearComplexity, 0},
+        {`x\s+$`, LinearComplexity, 0},
+        {`^[a-z]+@[a-z]+$`, LinearComplexity, 0},
+        {`^(?>a+)+$`, LinearComplexity, 0},
+        {`^(a|b)*$`, LinearComplexity, 0},
+    }
+    for _, test := range tests {
+        report := fromString(PCREFlavor, test.pattern).AnalyzeReDoS()
+        if report.Complexity != test.complexity || report.Degree != test.degree {
+            t.Errorf("%s is analyzed as %s of degree %d, want %s of degree %d: %+v",
+                test.pattern, report.Complexity, report.Degree, test.complexity, test.degree, report.Findings)
+        }
+    }
+}
+
+// TestWarnReDoS checks that the findings are only reported by builds after WarnReDoS
+func TestWarnReDoS(t *testing.T) {
+    if _, errs := NewECMARejexFromString(`(a+)+$`).Build(); len(errs) > 0 {
+        t.Errorf("findings are reported without WarnReDoS: %v", errs)
+    }
+    _, errs := NewECMARejexFromString(`(a+)+$`, true).WarnReDoS().Build()
+    if len(errs) != 1 || !errs[0].Warning {
+        t.Errorf("findings are reported as %v", errs)
+    }
+}
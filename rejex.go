@@ -1,50 +1,57 @@
 package rejex
 
 import (
-	"fmt"
-    "strings"
-    "regexp"
+    "fmt"
+    "regexp/syntax"
 )
 
-const (
-    anchor = "ANCHOR"
-    quantifier = "QUANTIFIER"
-    characters = "CHARACTERS"
-    meta = "META"
-)
-
-// RejexError is an error reported while constructing a regex
+// RejexError is an error reported while constructing a regex. Warnings are reported
+// for regexes which are valid but can behave badly, such as taking exponential time
 type RejexError struct {
     Position int
     Err string
+    Warning bool
 }
 
 // Error returns a formatted error message
 func (e *RejexError) Error() string {
+    if e.Warning {
+        return fmt.Sprintf("Warning while building regex at position %d: %s", e.Position, e.Err)
+    }
     return fmt.Sprintf("Error while building regex at position %d: %s", e.Position, e.Err)
 }
 
-// RejexBuilder defines a regex string before being fully constructed
+// RejexBuilder defines a regex before being fully constructed. Each method in the
+// chain adds nodes to a syntax tree which is rendered for the flavor by Build(). Its
+// methods are not restricted to the flavor, the builders returned by the constructors
+// such as GoRejex only expose the methods their flavor supports
 type RejexBuilder struct {
-    strings.Builder
-
     flags map[RejexFlag]bool
+    // options holds the start options written at the very start of the pattern, such
+    // as UTF for `(*UTF)`
+    options []string
     flavor RejexFlavor
 
     negateNext bool
-    lastSegmentType string
 
-    groupActive bool
-    groupContent []string
-    groupNestingLevel int
-
-    selectionActive bool
-    selectionContent string
+    // frames holds the root of the pattern followed by the groups that are open
+    frames []*frame
+    selection *Class
+    // segments holds the name of the chain method which produced each node
+    segments map[Node]string
+    optimize bool
+    // warnReDoS is set when the findings of AnalyzeReDoS are reported by the builds
+    warnReDoS bool
 
     ignoreErrors bool
     Errors []RejexError
+}
 
-    // bufferedQuantifier string
+// frame holds the contents of the pattern root or of a group that has not been
+// ended yet, a new alternative is started every time Or() is used
+type frame struct {
+    group *Group
+    alternatives [][]Node
 }
 
 func createRejexBuilder(flavor RejexFlavor, ignoreErrors []bool) *RejexBuilder {
@@ -56,153 +63,293 @@ func createRejexBuilder(flavor RejexFlavor, ignoreErrors []bool) *RejexBuilder {
     }
 
     r := RejexBuilder{
-        groupContent: make([]string, 2),
+        frames: []*frame{{alternatives: make([][]Node, 1)}},
+        segments: map[Node]string{},
         ignoreErrors: ie,
     }
 
     r.flavor = flavor
-    switch flavor {
-    case GoFlavor:
-        r.flags = goFlavorFlags
-    case ECMAFlavor:
-        r.flags = ecmaFlavorFlags
-    case PerlFlavor:
-        r.flags = perlFlavorFlags
+    flags := flavor.Flags()
+    r.flags = make(map[RejexFlag]bool, len(flags))
+    for f, b := range flags {
+        r.flags[f] = b
     }
 
     return &r
 }
 
-// NewRejex creates a new RejexBuilder object used to construct a regex. This uses
+// NewRejex creates a new builder used to construct a regex. This uses
 // the Go flavored syntax.
-func NewRejex(ignoreErrors ...bool) GoFlavorInterface {
+func NewRejex(ignoreErrors ...bool) *GoRejex {
     r := createRejexBuilder(GoFlavor, ignoreErrors)
-    return GoFlavorInterface(r)
+    return newGoRejex(r)
 }
 
-// NewRejexFromString creates a new RejexBuilder object used to construct a regex and
-// populates it with a provided regex string, this string is not validated to be valid
-// syntax. This uses the Go flavored syntax.
-func NewRejexFromString(s string, ignoreErrors ...bool) GoFlavorInterface {
+// NewRejexFromString creates a new builder used to construct a regex and
+// populates it with the segments of a provided regex string, syntax errors in the string
+// are reported as errors. This uses the Go flavored syntax.
+func NewRejexFromString(s string, ignoreErrors ...bool) *GoRejex {
     r := createRejexBuilder(GoFlavor, ignoreErrors)
-    r.WriteString(s)
-    return GoFlavorInterface(r)
+    r.appendPattern(s)
+    return newGoRejex(r)
 }
 
-// NewECMARejex creates a new RejexBuilder object used to construct a regex. This uses
+// NewECMARejex creates a new builder used to construct a regex. This uses
 // the ECMAScript flavored syntax.
-func NewECMARejex(ignoreErrors ...bool) ECMAFlavorInterface {
+func NewECMARejex(ignoreErrors ...bool) *ECMARejex {
     r := createRejexBuilder(ECMAFlavor, ignoreErrors)
-    return ECMAFlavorInterface(r)
+    return newECMARejex(r)
 }
 
-// NewECMARejexFromString creates a new RejexBuilder object used to construct a regex and
-// populates it with a provided regex string, this string is not validated to be valid
-// syntax. This uses the ECMAScript flavored syntax.
-func NewECMARejexFromString(s string, ignoreErrors ...bool) ECMAFlavorInterface {
+// NewECMARejexFromString creates a new builder used to construct a regex and
+// populates it with the segments of a provided regex string, syntax errors in the string
+// are reported as errors. This uses the ECMAScript flavored syntax.
+func NewECMARejexFromString(s string, ignoreErrors ...bool) *ECMARejex {
     r := createRejexBuilder(ECMAFlavor, ignoreErrors)
-    r.WriteString(s)
-    return ECMAFlavorInterface(r)
+    r.appendPattern(s)
+    return newECMARejex(r)
 }
 
-// NewPerlRejex creates a new RejexBuilder object used to construct a regex. This uses
+// NewPerlRejex creates a new builder used to construct a regex. This uses
 // the Perl flavored syntax.
-func NewPerlRejex(ignoreErrors ...bool) PerlFlavorInterface {
-    r := createRejexBuilder(GoFlavor, ignoreErrors)
-    return PerlFlavorInterface(r)
+func NewPerlRejex(ignoreErrors ...bool) *PerlRejex {
+    r := createRejexBuilder(PerlFlavor, ignoreErrors)
+    return newPerlRejex(r)
+}
+
+// NewPerlRejexFromString creates a new builder used to construct a regex and
+// populates it with the segments of a provided regex string, syntax errors in the string
+// are reported as errors. This uses the Perl flavored syntax.
+func NewPerlRejexFromString(s string, ignoreErrors ...bool) *PerlRejex {
+    r := createRejexBuilder(PerlFlavor, ignoreErrors)
+    r.appendPattern(s)
+    return newPerlRejex(r)
+}
+
+// NewPCRERejex creates a new builder used to construct a regex. This uses
+// the PCRE2 flavored syntax.
+func NewPCRERejex(ignoreErrors ...bool) *PCRERejex {
+    r := createRejexBuilder(PCREFlavor, ignoreErrors)
+    return newPCRERejex(r)
+}
+
+// NewPCRERejexFromString creates a new builder used to construct a regex and
+// populates it with the segments of a provided regex string, syntax errors in the string
+// are reported as errors. This uses the PCRE2 flavored syntax.
+func NewPCRERejexFromString(s string, ignoreErrors ...bool) *PCRERejex {
+    r := createRejexBuilder(PCREFlavor, ignoreErrors)
+    r.appendPattern(s)
+    return newPCRERejex(r)
+}
+
+// NewPythonRejex creates a new builder used to construct a regex. This uses
+// the syntax of the re module of Python.
+func NewPythonRejex(ignoreErrors ...bool) *PythonRejex {
+    r := createRejexBuilder(PythonFlavor, ignoreErrors)
+    return newPythonRejex(r)
+}
+
+// NewPythonRejexFromString creates a new builder used to construct a regex and
+// populates it with the segments of a provided regex string, syntax errors in the string
+// are reported as errors. This uses the syntax of the re module of Python.
+func NewPythonRejexFromString(s string, ignoreErrors ...bool) *PythonRejex {
+    r := createRejexBuilder(PythonFlavor, ignoreErrors)
+    r.appendPattern(s)
+    return newPythonRejex(r)
+}
+
+// NewFromString creates a new builder of any flavor, such as one found with LookupFlavor,
+// and populates it with the segments of a provided regex string, syntax errors in the
+// string are reported as errors. The methods of the builder are not restricted to the
+// flavor
+func NewFromString(flavor RejexFlavor, s string, ignoreErrors ...bool) *RejexBuilder {
+    r := createRejexBuilder(flavor, ignoreErrors)
+    r.appendPattern(s)
+    return r
 }
 
-// NewPerlRejexFromString creates a new RejexBuilder object used to construct a regex and
-// populates it with a provided regex string, this string is not validated to be valid
-// syntax. This uses the Perl flavored syntax.
-func NewPerlRejexFromString(s string, ignoreErrors ...bool) PerlFlavorInterface {
-    r := createRejexBuilder(GoFlavor, ignoreErrors)
-    r.WriteString(s)
-    return PerlFlavorInterface(r)
+// Tree returns the syntax tree of the pattern constructed so far. Groups and
+// selection sets which have not been ended are not part of the tree
+func (r *RejexBuilder) Tree() Node {
+    return joinAlternatives(r.frames[0].alternatives)
 }
 
 // Build constructs the final regex string and returns it along with a list of errors
 func (r *RejexBuilder) Build() (string, []RejexError) {
+    return r.BuildFor(r.flavor)
+}
+
+// BuildFor constructs the final regex string in the syntax of the provided flavor and
+// returns it along with a list of errors. Constructs with a different syntax in the
+// flavor are translated, those which cannot be expressed in it are reported as errors.
+// Regexes of the Go flavor are also validated with the regexp/syntax package, and
+// after WarnReDoS() the findings of AnalyzeReDoS are reported as warnings for the other
+// flavors
+func (r *RejexBuilder) BuildFor(flavor RejexFlavor) (string, []RejexError) {
     r.negateNext = false
 
-    if r.selectionActive {
-        r.addError("Building without closing selection set")
+    // errors of the state the builder is in are only reported by this build
+    var open []RejexError
+    if r.selection != nil {
+        open = append(open, r.errorAt(0, "Building without closing selection set"))
     }
-    if r.groupActive {
-        r.addError("Building without closing group")
+    if len(r.frames) > 1 {
+        open = append(open, r.errorAt(0, "Building without closing group"))
+    }
+
+    root, _ := r.renderedTree(flavor)
+    builtRejex, errs := renderPattern(flavor, root, r.flags, r.options)
+    switch {
+    case len(errs) > 0:
+    case flavor.Base() == GoFlavor && hasRaw(root):
+        // regex strings which could not be parsed are written verbatim and their syntax
+        // errors are already reported
+    case flavor.Base() == GoFlavor:
+        // the regexp package is the authority on what the Go flavor accepts
+        if _, err := syntax.Parse(builtRejex, syntax.Perl); err != nil {
+            errs = append(errs, *r.syntaxError(builtRejex, err))
+        }
+    case r.warnReDoS:
+        // the other flavors run on backtracking engines
+        errs = append(errs, r.redosWarnings(flavor)...)
     }
+    errs = append(append(append([]RejexError{}, r.Errors...), open...), errs...)
 
     if !r.ignoreErrors {
-        for _, err := range r.Errors {
+        for _, err := range errs {
             fmt.Println(err.Error())
         }
     }
 
-    var flagStr, builtRejex string
-    switch r.flavor {
-    case GoFlavor:
-        flagStr = "(?"
-        for f, b := range r.flags {
-            if b { flagStr += string(f) }
-        }
-        flagStr += ")"
-        if flagStr == "(?)" {
-            builtRejex = r.String()
-        } else {
-            builtRejex = flagStr + r.String()
-        }
-    case ECMAFlavor:
-        flagStr = ""
-        for f, b := range r.flags {
-            if b { flagStr += string(f) }
+    return builtRejex, errs
+}
+
+// Convert translates a regex string from one flavor to another and returns it along
+// with a list of errors, which includes syntax errors in the string and constructs
+// which cannot be expressed in the target flavor
+func Convert(pattern string, from, to RejexFlavor) (string, []RejexError) {
+    r := createRejexBuilder(from, []bool{true})
+    r.appendPattern(pattern)
+    return r.BuildFor(to)
+}
+
+// currentNodes returns the nodes of the alternative currently being constructed
+func (r *RejexBuilder) currentNodes() []Node {
+    f := r.frames[len(r.frames)-1]
+    return f.alternatives[len(f.alternatives)-1]
+}
+
+func (r *RejexBuilder) setCurrentNodes(nodes []Node) {
+    f := r.frames[len(r.frames)-1]
+    f.alternatives[len(f.alternatives)-1] = nodes
+}
+
+// appendNode adds a node to the end of the pattern, or its characters to the open
+// selection set
+func (r *RejexBuilder) appendNode(n Node) *RejexBuilder {
+    if r.selection != nil {
+        r.addToSelection(n)
+    } else {
+        r.record(n)
+        r.setCurrentNodes(append(r.currentNodes(), n))
+    }
+
+    r.negateNext = false
+    return r
+}
+
+func (r *RejexBuilder) addToSelection(n Node) {
+    switch n := n.(type) {
+    case *Class:
+        for _, item := range n.Items {
+            if item.Kind == AnyCharItem || item.Kind == GraphemeItem || item.Kind == LinebreakItem {
+                r.addError("Only single characters can be used in a selection set")
+                return
+            }
         }
-        builtRejex = fmt.Sprintf("/%s/%s", r.String(), flagStr)
-    case PerlFlavor:
-        flagStr = ""
-        for f, b := range r.flags {
-            if b { flagStr += string(f) }
+        r.selection.Items = append(r.selection.Items, n.Items...)
+    case *Literal:
+        for _, c := range n.Text {
+            r.selection.Items = append(r.selection.Items, charItem(c))
         }
-        builtRejex = fmt.Sprintf("/%s/%s", r.String(), flagStr)
+    default:
+        r.addError("Only characters can be used in a selection set")
     }
+}
 
-    return builtRejex, r.Errors
+// appendClass adds a character class made of the provided items, negated if Not()
+// preceded it
+func (r *RejexBuilder) appendClass(items ...ClassItem) *RejexBuilder {
+    return r.appendNode(&Class{Items: items, Negated: r.negateNext})
 }
 
-func (r *RejexBuilder) appendSegment(segmentType string, segment string, alt ...string) *RejexBuilder {
-    var toWrite string
-    if !r.negateNext || len(alt) == 0 {
-        toWrite = segment
-    } else {
-        toWrite = alt[0]
+// appendPattern adds the segments of a regex string to the pattern. The string is
+// written verbatim if it cannot be parsed
+func (r *RejexBuilder) appendPattern(s string) *RejexBuilder {
+    groups, _ := r.captureGroups()
+    n, flags, options, err := parsePattern(r.flavor, s, groups)
+    if err != nil {
+        r.addErrorAt(err.Position, err.Err)
+        return r.appendNode(&Raw{s})
     }
-
-    if r.selectionActive {
-        r.selectionContent += toWrite
-    } else if r.groupActive {
-        r.groupContent[r.groupNestingLevel] += toWrite
-    } else {
-        r.WriteString(toWrite)
+    for _, f := range flags {
+        r.flags[f] = true
     }
+    r.options = append(r.options, options...)
 
-    r.negateNext = false
-    r.lastSegmentType = segmentType
+    alts := []Node{n}
+    if a, ok := n.(*Alternation); ok {
+        alts = a.Alternatives
+    }
+    for i, alt := range alts {
+        if i > 0 {
+            r.Or()
+        }
+        if c, ok := alt.(*Concat); ok {
+            for _, sub := range c.Nodes {
+                r.appendNode(sub)
+            }
+        } else {
+            r.appendNode(alt)
+        }
+    }
     return r
 }
 
+// fragment returns the node for a regex string passed to a method. The string is
+// written verbatim if it cannot be parsed
+func (r *RejexBuilder) fragment(s string) Node {
+    groups, _ := r.captureGroups()
+    n, err := parseFragment(r.flavor, s, groups)
+    if err != nil {
+        r.addErrorAt(err.Position, err.Err)
+        return &Raw{s}
+    }
+    return n
+}
+
 func (r *RejexBuilder) addError(err string) {
-    r.Errors = append(r.Errors,
-        RejexError{
-            r.Len(),
-            err,
-        },
-    )
+    r.addErrorAt(0, err)
+}
+
+// addErrorAt reports an error at an offset from the end of the pattern constructed so far
+func (r *RejexBuilder) addErrorAt(offset int, err string) {
+    r.Errors = append(r.Errors, r.errorAt(offset, err))
+}
+
+// errorAt returns an error at an offset from the end of the pattern constructed so far
+func (r *RejexBuilder) errorAt(offset int, err string) RejexError {
+    return RejexError{
+        Position: len(render(r.flavor, r.Tree())) + offset,
+        Err: err,
+    }
 }
 
 // General
 
 // Not queues the following segment to be negated, converting '\d' to '\D' for instance
 func (r *RejexBuilder) Not() *RejexBuilder {
-    if r.selectionActive {
+    if r.selection != nil {
         r.addError(
             "Negation cannot be used in a selection set, use `BeginNonSelectionSet()` instead",
         )
@@ -214,25 +361,26 @@ func (r *RejexBuilder) Not() *RejexBuilder {
 
 // Characters matches the exact input provided to it
 func (r *RejexBuilder) Characters(s string) *RejexBuilder {
-    return r.appendSegment(characters, s)
+    if r.selection != nil {
+        return r.AnyFrom(s)
+    }
+    return r.appendNode(r.fragment(s))
 }
 
 // EscapedCharacters matches the input provided after escaping the
 // regex special characters from it
 func (r *RejexBuilder) EscapedCharacters(s string) *RejexBuilder {
-    segment := regexp.QuoteMeta(s)
-    return r.appendSegment(characters, segment)
+    return r.appendNode(&Literal{s})
 }
 
 // AnyChar matches any single character
 func (r *RejexBuilder) AnyChar() *RejexBuilder {
-    return r.appendSegment(characters, ".")
+    return r.appendNode(&Class{Items: []ClassItem{{Kind: AnyCharItem}}})
 }
 
 // Literally matches the provided input enclosed in an escape sequence (\Q...\E)
 func (r *RejexBuilder) Literally(s string) *RejexBuilder {
-    segment := fmt.Sprintf("\\Q%s\\E", s)
-    return r.appendSegment(characters, segment)
+    return r.appendNode(&Literal{s})
 }
 
 // Anchors
@@ -240,144 +388,194 @@ func (r *RejexBuilder) Literally(s string) *RejexBuilder {
 // Starting matches the beginning of a string or the beginning of a line
 // when the multiline flag is set. It does not match any character
 func (r *RejexBuilder) Starting() *RejexBuilder {
-    return r.appendSegment(anchor, "^")
+    return r.appendNode(&Assertion{AssertLineStart})
 }
 
 // AbsoluteStarting represents the absolute beginning of a string
 // unlike Starting, the multiline flag doesn't affect this, it always matches
 // the very beginning of a string. It does not match any character
 func (r *RejexBuilder) AbsoluteStarting() *RejexBuilder {
-    return r.appendSegment(anchor, "\\A")
+    return r.appendNode(&Assertion{AssertTextStart})
 }
 
 // Ending matches the end of a string or the end of a line
 // when the multiline flag is set. It does not match any character
 func (r *RejexBuilder) Ending() *RejexBuilder {
-    return r.appendSegment(anchor, "$")
+    return r.appendNode(&Assertion{AssertLineEnd})
 }
 
 // AbsoluteEnding represents the absolute end of a string
 // unlike Ending, the multiline flag doesn't affect this, it always matches
 // the very end of a string. It does not match any character
 func (r *RejexBuilder) AbsoluteEnding() *RejexBuilder {
-    return r.appendSegment(anchor, "\\z")
+    return r.appendNode(&Assertion{AssertTextEnd})
 }
 
 // WordBoundary matches the end or beginning of any word, it does not match
 // any character but is an anchor between a word character and a non
 // word character
 func (r *RejexBuilder) WordBoundary() *RejexBuilder {
-    return r.appendSegment(anchor, "\\b", "\\B")
+    if r.negateNext {
+        return r.appendNode(&Assertion{AssertNonWordBoundary})
+    }
+    return r.appendNode(&Assertion{AssertWordBoundary})
 }
 
 // EndOfLastMatch matches at the end of the previous match during the second
 // and following match attempts. Matches at the start of the string during the
 // first match attempt
 func (r *RejexBuilder) EndOfLastMatch() *RejexBuilder {
-    return r.appendSegment(anchor, "\\G")
+    return r.appendNode(&Assertion{AssertLastMatchEnd})
 }
 
+// ResetMatchStart makes the reported match start at this position, the segments
+// preceding it still have to match but are not part of the match (\K). It does not
+// match any character
+func (r *RejexBuilder) ResetMatchStart() *RejexBuilder {
+    return r.appendNode(&Assertion{AssertMatchStart})
+}
 
 // Quantifiers
 
-func checkForGroup(s, q string) string {
-    if len(s) > 1 {
-        return fmt.Sprintf("(?:%s)%s", s, q)
-    } else {
-        return fmt.Sprintf("%s%s", s, q)
+// quantify repeats the provided input, or the preceding segment if the input is empty
+func (r *RejexBuilder) quantify(s string, min, max int) *RejexBuilder {
+    if r.selection != nil {
+        r.addError("Quantifiers cannot be used in a selection set")
+        return r
+    }
+    if s != "" {
+        return r.appendNode(&Repeat{Sub: r.fragment(s), Min: min, Max: max})
+    }
+
+    nodes := r.currentNodes()
+    if len(nodes) == 0 {
+        r.addError("No preceding segment to quantify")
+        return r
+    }
+    rep := &Repeat{Sub: nodes[len(nodes)-1], Min: min, Max: max}
+    r.record(rep)
+    nodes[len(nodes)-1] = rep
+    r.negateNext = false
+    return r
+}
+
+// checkCounts reports an error if the counts of a quantifier are negative or the minimum
+// exceeds the maximum, and returns whether they are valid
+func (r *RejexBuilder) checkCounts(min, max int) bool {
+    switch {
+    case min < 0 || max < 0:
+        r.addError("The counts of a quantifier cannot be negative")
+        return false
+    case min > max:
+        r.addError(fmt.Sprintf("The minimum count %d of a quantifier exceeds the maximum %d", min, max))
+        return false
     }
+    return true
 }
 
 // ZeroOrOneOf matches exactly 0 or 1 occurance of the provided input
 // the input can be an empty string for this to affect the segment preceding it.
 // Matches as many characters as it can
 func (r *RejexBuilder) ZeroOrOneOf(s string) *RejexBuilder {
-    segment := checkForGroup(s, "?")
-    return r.appendSegment(quantifier, segment)
+    return r.quantify(s, 0, 1)
 }
 
 // ZeroOrMoreOf matches any number of occurances of the provided input
 // the input can be an empty string for this to affect the segment preceding it.
 // Matches as many characters as it can
 func (r *RejexBuilder) ZeroOrMoreOf(s string) *RejexBuilder {
-    segment := checkForGroup(s, "*")
-    return r.appendSegment(quantifier, segment)
+    return r.quantify(s, 0, -1)
 }
 
 // OneOrMoreOf matches more than 1 occurances of the provided input
 // the input can be an empty string for this to affect the segment preceding it.
 // Matches as many characters as it can
 func (r *RejexBuilder) OneOrMoreOf(s string) *RejexBuilder {
-    segment := checkForGroup(s, "+")
-    return r.appendSegment(quantifier, segment)
+    return r.quantify(s, 1, -1)
 }
 
 // NOf matches exactly n occurances of the provided input
 // the input can be an empty string for this to affect the segment preceding it.
 // Matches as many characters as it can
 func (r *RejexBuilder) NOf(s string, n int) *RejexBuilder {
-    segment := checkForGroup(s, fmt.Sprintf("{%d}", n))
-    return r.appendSegment(quantifier, segment)
+    if !r.checkCounts(n, n) {
+        return r
+    }
+    return r.quantify(s, n, n)
 }
 
 // NOrMoreOf matches more than n occurances of the provided input
 // the input can be an empty string for this to affect the segment preceding it.
 // Matches as many characters as it can
 func (r *RejexBuilder) NOrMoreOf(s string, n int) *RejexBuilder {
-    segment := checkForGroup(s, fmt.Sprintf("{%d,}", n))
-    return r.appendSegment(quantifier, segment)
+    if !r.checkCounts(n, n) {
+        return r
+    }
+    return r.quantify(s, n, -1)
 }
 
 // NToMOf matches more than n and upto m occurances of the provided input
 // the input can be an empty string for this to affect the segment preceding it.
 // Matches as many characters as it can, fewer than m
 func (r *RejexBuilder) NToMOf(s string, n, m int) *RejexBuilder {
-    segment := checkForGroup(s, fmt.Sprintf("{%d,%d}", n, m))
-    return r.appendSegment(quantifier, segment)
+    if !r.checkCounts(n, m) {
+        return r
+    }
+    return r.quantify(s, n, m)
 }
 
 // Meta
 
+// setRepeatMode changes the mode of the quantifier preceding it
+func (r *RejexBuilder) setRepeatMode(mode RepeatMode, method string) *RejexBuilder {
+    nodes := r.currentNodes()
+    if r.selection == nil && len(nodes) > 0 {
+        if rep, ok := nodes[len(nodes)-1].(*Repeat); ok && rep.Mode == Greedy {
+            rep.Mode = mode
+            return r
+        }
+    }
+    r.addError(
+        fmt.Sprintf("'%s()' should only be used after a quantifier", method),
+    )
+    return r
+}
+
 // PreferFewer when used after a quantifier (such as OneOrMoreOf or NOf) makes
 // the segment match as few characters as it can, opposite of their default behaviour
 func (r *RejexBuilder) PreferFewer() *RejexBuilder {
-    if r.lastSegmentType == quantifier {
-        r.appendSegment(meta, "?")
-    } else {
-        r.addError(
-            "'PreferFewer()' should only be used after a quantifier",
-        )
-    }
-    return r
+    return r.setRepeatMode(Lazy, "PreferFewer")
 }
 
 // PossessiveQuantifier when used after a quantifier (such as OneOrMoreOf or NOf)
 // makes the segment match as many items as possible, without trying any permutations
 // with less matches even if the remainder of the regex fails.
 func (r *RejexBuilder) PossessiveQuantifier() *RejexBuilder {
-    if r.lastSegmentType == quantifier {
-        r.appendSegment(meta, "+")
-    } else {
-        r.addError(
-            "'PossessiveQuantifier()' should only be used after a quantifier",
-        )
-    }
-    return r
+    return r.setRepeatMode(Possessive, "PossessiveQuantifier")
 }
 
 // Or represents an alternative between whatever precedes it and whatever follows it.
 // Can be used within a group construct. Can be repeated to provide more than 2 alternatives
 func (r *RejexBuilder) Or() *RejexBuilder {
-    return r.appendSegment(meta, "|")
+    if r.selection != nil {
+        r.addError("'Or()' cannot be used in a selection set")
+        return r
+    }
+    f := r.frames[len(r.frames)-1]
+    f.alternatives = append(f.alternatives, nil)
+    r.negateNext = false
+    return r
 }
 
 // EitherOr matches any of the provided input strings by chaining together segments using
 // the Or syntax. This uses a non-capturing group by default
 func (r *RejexBuilder) EitherOr(s ...string) *RejexBuilder {
-    var segment string
     if len(s) > 1 {
-        segment = fmt.Sprintf("(?:%s)", strings.Join(s, "|"))
-        r.appendSegment(characters, segment)
+        alt := &Alternation{}
+        for _, option := range s {
+            alt.Alternatives = append(alt.Alternatives, r.fragment(option))
+        }
+        r.appendNode(&Group{Kind: NonCaptureGroup, Body: alt})
     } else {
         r.addError(
             "Not enough options specified in 'EitherOr()'",
@@ -389,12 +587,8 @@ func (r *RejexBuilder) EitherOr(s ...string) *RejexBuilder {
 // CapturedPatternByNum matches a previusly captured group with the provided
 // group number
 func (r *RejexBuilder) CapturedPatternByNum(n int) *RejexBuilder {
-    if n > 0 && n < 100 {
-        segment := fmt.Sprintf("\\%d", n)
-        r.appendSegment(meta, segment)
-    } else if n < 0 && r.flavor == PerlFlavor {
-        segment := fmt.Sprintf("\\g%d", n)
-        r.appendSegment(meta, segment)
+    if n > 0 && n < 100 || n < 0 && r.flavor.Supports(RelativeBackrefFeature) {
+        r.appendNode(&Backref{Num: n})
     } else {
         r.addError("Pattern number out of bounds")
     }
@@ -405,46 +599,79 @@ func (r *RejexBuilder) CapturedPatternByNum(n int) *RejexBuilder {
 // CapturedPatternByName matches a previusly captured group with the provided
 // group name
 func (r *RejexBuilder) CapturedPatternByName(s string) *RejexBuilder {
-    segment := fmt.Sprintf("\\k<%s>", s)
-    return r.appendSegment(meta, segment)
+    return r.appendNode(&Backref{Name: s})
+}
+
+// Recurse matches the whole regex again at this position, which is used to match
+// nested constructs such as balanced parentheses
+func (r *RejexBuilder) Recurse() *RejexBuilder {
+    return r.appendNode(&Recursion{})
+}
+
+// SubroutineByNum matches the pattern of the group with the provided group number
+// again, like a subroutine call, rather than the text it captured. A negative number
+// refers to the groups preceding it
+func (r *RejexBuilder) SubroutineByNum(n int) *RejexBuilder {
+    if n != 0 {
+        r.appendNode(&Recursion{Num: n})
+    } else {
+        r.addError("Group number out of bounds")
+    }
+    return r
+}
+
+// SubroutineByName matches the pattern of the group with the provided group name
+// again, like a subroutine call, rather than the text it captured
+func (r *RejexBuilder) SubroutineByName(s string) *RejexBuilder {
+    return r.appendNode(&Recursion{Name: s})
+}
+
+// BacktrackingVerb controls what happens when the regex engine backtracks over it,
+// such as "SKIP" to not try matching from the positions skipped over or "FAIL" to
+// backtrack right away. A verb can be given a name as in "MARK:name"
+func (r *RejexBuilder) BacktrackingVerb(s string) *RejexBuilder {
+    v, err := parseVerb(s)
+    if err != nil {
+        r.addError(err.Error())
+        return r
+    }
+    return r.appendNode(v)
 }
 
 // Group Constructs
 
-func (r *RejexBuilder) startNewGroup(s string) *RejexBuilder {
-    if !r.selectionActive {
-        r.groupActive = true
-        r.groupNestingLevel++
-        r.groupContent[r.groupNestingLevel] = s
+func (r *RejexBuilder) startNewGroup(g *Group) *RejexBuilder {
+    if r.selection == nil {
+        r.record(g)
+        r.frames = append(r.frames, &frame{group: g, alternatives: make([][]Node, 1)})
     } else {
         r.addError(
             "Group constructs do not work inside a selection set",
         )
     }
+    r.negateNext = false
     return r
 }
 
 // BeginCaptureGroup represents the start of a new capture group with a group number
 func (r *RejexBuilder) BeginCaptureGroup() *RejexBuilder {
-    return r.startNewGroup("(")
+    return r.startNewGroup(&Group{Kind: CaptureGroup})
 }
 
 // BeginNamedCaptureGroup represents the start of a new capture group with a group name
 func (r *RejexBuilder) BeginNamedCaptureGroup(name string) *RejexBuilder {
-    segment := fmt.Sprintf("(?P<%s>", name)
-    return r.startNewGroup(segment)
+    return r.startNewGroup(&Group{Kind: NamedCaptureGroup, Name: name})
 }
 
 // BeginNonCaptureGroup represents the start of a new group with no group number or name
 func (r *RejexBuilder) BeginNonCaptureGroup() *RejexBuilder {
-    return r.startNewGroup("(?:")
+    return r.startNewGroup(&Group{Kind: NonCaptureGroup})
 }
 
 // BeginGroupWithFlags represents the start of a new group which use the provided flags.
 // These flags only affect the pattern within this group
 func (r *RejexBuilder) BeginGroupWithFlags(f []RejexFlag) *RejexBuilder {
-    segment := fmt.Sprintf("(?%s:", string(f))
-    return r.startNewGroup(segment)
+    return r.startNewGroup(&Group{Kind: FlagGroup, Flags: f})
 }
 
 // BeginAtomicGroup represents the start of a new group which prevents the
@@ -453,54 +680,78 @@ func (r *RejexBuilder) BeginGroupWithFlags(f []RejexFlag) *RejexBuilder {
 // backtrack over the group if a quantifier or alternation makes it optional.
 // But it will not backtrack into the group to try other permutations of the group
 func (r *RejexBuilder) BeginAtomicGroup() *RejexBuilder {
-    return r.startNewGroup("(?>")
+    return r.startNewGroup(&Group{Kind: AtomicGroup})
 }
 
 // BeginBranchResetGroup represents the start of a new group which if it has
 // multiple alternatives with capturing groups, then the capturing group
 // numbers are the same in all the alternatives
 func (r *RejexBuilder) BeginBranchResetGroup() *RejexBuilder {
-    return r.startNewGroup("(?|")
+    return r.startNewGroup(&Group{Kind: BranchResetGroup})
+}
+
+// BeginConditionalGroupByNum represents the start of a new group which matches its
+// first alternative if the group with the provided group number has captured text, and
+// its second alternative, if it has one, otherwise
+func (r *RejexBuilder) BeginConditionalGroupByNum(n int) *RejexBuilder {
+    if n == 0 {
+        r.addError("Group number out of bounds")
+    }
+    return r.startNewGroup(&Group{Kind: ConditionalGroup, Num: n})
+}
+
+// BeginConditionalGroupByName represents the start of a new group which matches its
+// first alternative if the group with the provided group name has captured text, and
+// its second alternative, if it has one, otherwise
+func (r *RejexBuilder) BeginConditionalGroupByName(name string) *RejexBuilder {
+    return r.startNewGroup(&Group{Kind: ConditionalGroup, Name: name})
+}
+
+// BeginDefineGroup represents the start of a new group which is never matched, the
+// groups defined in it are only used as subroutines
+func (r *RejexBuilder) BeginDefineGroup() *RejexBuilder {
+    return r.startNewGroup(&Group{Kind: DefineGroup})
 }
 
 // BeginPosLookahead represents the start of a new group which only allows the preceding
 // segment to match when the pattern in this group follows it but without actualy matching
 // this pattern
 func (r *RejexBuilder) BeginPosLookahead() *RejexBuilder {
-    return r.startNewGroup("(?=")
+    return r.startNewGroup(&Group{Kind: PosLookahead})
 }
 
 // BeginNegLookahead represents the start of a new group which only allows the preceding
 // segment to match when the pattern in this group does not follow it but without actualy matching
 // this pattern
 func (r *RejexBuilder) BeginNegLookahead() *RejexBuilder {
-    return r.startNewGroup("(?!")
+    return r.startNewGroup(&Group{Kind: NegLookahead})
 }
 
 // BeginPosLookbehind represents the start of a new group which only allows the following
 // segment to match when the pattern in this group precedes it but without actualy matching
 // this pattern
 func (r *RejexBuilder) BeginPosLookbehind() *RejexBuilder {
-    return r.startNewGroup("(?<=")
+    return r.startNewGroup(&Group{Kind: PosLookbehind})
 }
 
 // BeginNegLookbehind represents the start of a new group which only allows the following
 // segment to match when the pattern in this group does not precede it but without actualy matching
 // this pattern
 func (r *RejexBuilder) BeginNegLookbehind() *RejexBuilder {
-    return r.startNewGroup("(?<!")
+    return r.startNewGroup(&Group{Kind: NegLookbehind})
 }
 
 // EndGroup represents the end of the last opened group
 func (r *RejexBuilder) EndGroup() *RejexBuilder {
-    if r.groupActive {
-        segment := r.groupContent[r.groupNestingLevel] + ")"
-        r.groupContent = r.groupContent[:r.groupNestingLevel]
-        r.groupNestingLevel--
-        if r.groupNestingLevel == 0 {
-            r.groupActive = false
-        }
-        r.appendSegment(characters, segment)
+    if r.selection != nil {
+        r.addError(
+            "Cannot end group, selection set still open",
+        )
+    } else if len(r.frames) > 1 {
+        f := r.frames[len(r.frames)-1]
+        r.frames = r.frames[:len(r.frames)-1]
+        f.group.Body = joinAlternatives(f.alternatives)
+        r.appendNode(f.group)
     } else {
         r.addError(
             "Cannot end group, no group open",
@@ -511,9 +762,10 @@ func (r *RejexBuilder) EndGroup() *RejexBuilder {
 
 // BeginSelectionSet represents the start of a set of characters out of which only one needs be matched
 func (r *RejexBuilder) BeginSelectionSet() *RejexBuilder {
-    if !r.selectionActive {
-        r.selectionActive = true
-        r.selectionContent = "["
+    if r.selection == nil {
+        r.selection = &Class{Negated: r.negateNext}
+        r.record(r.selection)
+        r.negateNext = false
     } else {
         r.addError("Cannot nest selection sets")
     }
@@ -522,9 +774,10 @@ func (r *RejexBuilder) BeginSelectionSet() *RejexBuilder {
 
 // BeginNonSelectionSet represents the start of a set of characters out of which none should be matched
 func (r *RejexBuilder) BeginNonSelectionSet() *RejexBuilder {
-    if !r.selectionActive {
-        r.selectionActive = true
-        r.selectionContent = "[^"
+    if r.selection == nil {
+        r.selection = &Class{Negated: !r.negateNext}
+        r.record(r.selection)
+        r.negateNext = false
     } else {
         r.addError("Cannot nest selection sets")
     }
@@ -533,11 +786,10 @@ func (r *RejexBuilder) BeginNonSelectionSet() *RejexBuilder {
 
 // EndGroup represents the end of the last opened selection set
 func (r *RejexBuilder) EndSelectionSet() *RejexBuilder {
-    if r.selectionActive {
-        segment := r.selectionContent + "]"
-        r.selectionContent = ""
-        r.selectionActive = false
-        r.appendSegment(characters, segment)
+    if r.selection != nil {
+        c := r.selection
+        r.selection = nil
+        r.appendNode(c)
     } else {
         r.addError(
             "Cannot end selection set, no set open",
@@ -0,0 +1,564 @@
+package rejex
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "unicode/utf8"
+)
+
+const (
+    metaChars = `\.+*?()|[]{}^$`
+    classMetaChars = `\]^-[`
+)
+
+// renderer writes the regex syntax of a syntax tree for a particular flavor, translating
+// constructs which use a different syntax in the flavor and recording the constructs
+// which cannot be expressed in it
+type renderer struct {
+    strings.Builder
+    flavor RejexFlavor
+
+    // flags holds the flags set for the whole pattern
+    flags map[RejexFlag]bool
+    // options holds the start options of the pattern
+    options []string
+    // numbers holds the group numbers of the pattern, which references are checked
+    // against. It is only set when rendering a complete pattern
+    numbers *groupNumbers
+    // invertGreedy is set when the ungreedy flag has to be emulated by inverting
+    // the mode of every quantifier
+    invertGreedy bool
+    // unicode is set when the pattern uses constructs requiring the unicode flag
+    unicode bool
+    // verbose is set while the verbose flag is in effect, whitespace and '#' have to
+    // be escaped outside of selection sets
+    verbose bool
+    // ascii is set when the pattern is written as bytes, which only hold ASCII characters
+    ascii bool
+    groups int
+    // lookarounds is the number of lookarounds enclosing the node being written
+    lookarounds int
+
+    // prefix is the length of the syntax written before the pattern
+    prefix int
+    spans []span
+    errs []RejexError
+}
+
+func render(flavor RejexFlavor, n Node) string {
+    w := renderer{flavor: flavor}
+    w.node(n)
+    return w.String()
+}
+
+// renderPattern renders a complete pattern along with its flags and start options,
+// wrapped in the syntax of the flavor
+func renderPattern(flavor RejexFlavor, n Node, flags map[RejexFlag]bool, options []string) (string, []RejexError) {
+    w := renderer{flavor: flavor, flags: flags, options: options}
+    return w.pattern(n), w.errs
+}
+
+func (w *renderer) pattern(n Node) string {
+    var set []RejexFlag
+    for _, f := range setFlags(w.flags) {
+        if _, ok := w.flavor.Flags()[f]; ok {
+            set = append(set, f)
+            continue
+        }
+        switch f {
+        case UngreedyFlag:
+            w.invertGreedy = true
+        case GlobalFlag, UnicodeFlag:
+            // these don't change what the pattern matches in flavors without them
+        default:
+            w.fail("The '%c' flag is not supported by the %s flavor", f, w.flavor.Name())
+        }
+    }
+    w.charsets(set)
+    if b, ok := w.flavor.(bytesFlavor); ok {
+        w.ascii = b.bytesPattern(w.flags)
+    }
+    w.verbose = w.flags[VerboseFlag]
+
+    var options strings.Builder
+    if len(w.options) > 0 && w.require(StartOptionFeature) {
+        for _, opt := range w.options {
+            fmt.Fprintf(&options, "(*%s)", opt)
+        }
+    }
+
+    w.numbers = numberGroups(n)
+    w.node(n)
+    prefix, suffix := w.flavor.RenderFlags(set, w.unicode)
+    // start options precede everything else, including the flags
+    prefix = options.String() + prefix
+    w.prefix = len(prefix)
+    return prefix + w.String() + suffix
+}
+
+// setFlags returns the flags which are set, in a stable order
+func setFlags(flags map[RejexFlag]bool) []RejexFlag {
+    var set []RejexFlag
+    for f, b := range flags {
+        if b { set = append(set, f) }
+    }
+    sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+    return set
+}
+
+func (w *renderer) fail(format string, a ...interface{}) {
+    w.errs = append(w.errs, RejexError{Position: w.Len(), Err: fmt.Sprintf(format, a...)})
+}
+
+// require records an error if the flavor does not support the provided feature
+func (w *renderer) require(f Feature) bool {
+    if w.flavor.Supports(f) {
+        return true
+    }
+    w.fail(unsupported(f, w.flavor))
+    return false
+}
+
+func (w *renderer) node(n Node) {
+    start := w.Len()
+    defer func() {
+        w.spans = append(w.spans, span{start, w.Len(), n})
+    }()
+
+    switch n := n.(type) {
+    case *Concat:
+        for i, sub := range n.Nodes {
+            // a numbered backreference followed by a digit would be read as a larger number
+            if i > 0 && numberedBackref(n.Nodes[i-1]) {
+                if text := render(w.flavor, sub); text != "" && text[0] >= '0' && text[0] <= '9' {
+                    w.WriteString("(?:)")
+                }
+            }
+            if _, ok := sub.(*Alternation); ok && len(n.Nodes) > 1 {
+                w.WriteString("(?:")
+                w.node(sub)
+                w.WriteString(")")
+            } else {
+                w.node(sub)
+            }
+        }
+    case *Alternation:
+        for i, alt := range n.Alternatives {
+            if i > 0 {
+                w.WriteString("|")
+            }
+            w.node(alt)
+        }
+    case *Literal:
+        for _, c := range n.Text {
+            w.char(c, false)
+        }
+    case *Raw:
+        w.WriteString(n.Text)
+    case *Class:
+        w.class(n)
+    case *Repeat:
+        w.repeat(n)
+    case *Group:
+        w.group(n)
+    case *Assertion:
+        w.assertion(n)
+    case *Backref:
+        w.backref(n)
+    case *Recursion:
+        w.recursion(n)
+    case *Verb:
+        w.require(VerbFeature)
+        if n.Arg != "" {
+            fmt.Fprintf(w, "(*%s:%s)", n.Name, n.Arg)
+        } else {
+            fmt.Fprintf(w, "(*%s)", n.Name)
+        }
+    }
+}
+
+// bytesFlavor is implemented by flavors which write some patterns as bytes rather than text
+type bytesFlavor interface {
+    bytesPattern(flags map[RejexFlag]bool) bool
+}
+
+// char writes a single character, inside of a selection set if inClass is set
+func (w *renderer) char(c rune, inClass bool) {
+    if w.ascii && c >= utf8.RuneSelf {
+        w.fail("Non-ASCII character '%c' cannot be used in a pattern of bytes", c)
+    }
+    // characters outside of the BMP are two code units without the unicode flag
+    if c > 0xFFFF {
+        w.unicode = true
+    }
+    if w.verbose && !inClass && (c == ' ' || c == '#') {
+        // the other whitespace characters are written as escapes by the flavors
+        w.WriteByte('\\')
+        w.WriteRune(c)
+        return
+    }
+    w.WriteString(w.flavor.RenderLiteral(c, inClass))
+}
+
+func isShorthand(item ClassItem) bool {
+    return item.Kind != RangeItem
+}
+
+func (w *renderer) class(c *Class) {
+    if len(c.Items) == 1 && isShorthand(c.Items[0]) {
+        item := c.Items[0]
+        item.Negated = item.Negated != c.Negated
+        w.classItem(item)
+        return
+    }
+
+    w.WriteString("[")
+    if c.Negated {
+        w.WriteString("^")
+    }
+    for _, item := range c.Items {
+        w.classItem(item)
+    }
+    w.WriteString("]")
+}
+
+func (w *renderer) classItem(item ClassItem) {
+    switch item.Kind {
+    case RangeItem:
+        w.char(item.Lo, true)
+        if item.Hi != item.Lo {
+            w.WriteString("-")
+            w.char(item.Hi, true)
+        }
+        return
+    case UnicodeItem:
+        w.require(UnicodeClassFeature)
+        w.unicode = true
+    case GraphemeItem:
+        w.require(GraphemeFeature)
+    case LinebreakItem:
+        w.require(LinebreakFeature)
+    }
+    w.WriteString(w.flavor.RenderClass(item))
+}
+
+// numberedBackref reports whether a node is a backreference written as a group number
+func numberedBackref(n Node) bool {
+    ref, ok := n.(*Backref)
+    return ok && ref.Name == ""
+}
+
+// needsGroup reports whether a node has to be enclosed in a non-capturing group
+// to be quantified as a single unit
+func needsGroup(n Node) bool {
+    switch n := n.(type) {
+    case *Literal:
+        return utf8.RuneCountInString(n.Text) != 1
+    case *Raw:
+        return len(n.Text) > 1
+    case *Concat:
+        return len(n.Nodes) != 1 || needsGroup(n.Nodes[0])
+    case *Alternation, *Repeat, *Assertion:
+        return true
+    }
+    return false
+}
+
+// fixedWidth returns the number of characters matched by a node which always matches
+// the same number of them. Syntax provided verbatim is assumed to match none
+func fixedWidth(n Node) (int, bool) {
+    switch n := n.(type) {
+    case *Literal:
+        return utf8.RuneCountInString(n.Text), true
+    case *Class:
+        for _, item := range n.Items {
+            if item.Kind == GraphemeItem || item.Kind == LinebreakItem {
+                return 0, false
+            }
+        }
+        return 1, true
+    case *Concat:
+        total := 0
+        for _, sub := range n.Nodes {
+            width, ok := fixedWidth(sub)
+            if !ok {
+                return 0, false
+            }
+            total += width
+        }
+        return total, true
+    case *Alternation:
+        width := -1
+        for _, alt := range n.Alternatives {
+            w, ok := fixedWidth(alt)
+            if !ok || width != -1 && w != width {
+                return 0, false
+            }
+            width = w
+        }
+        return width, true
+    case *Repeat:
+        width, ok := fixedWidth(n.Sub)
+        if !ok || n.Min != n.Max && width != 0 {
+            return 0, false
+        }
+        return width * n.Min, true
+    case *Group:
+        switch n.Kind {
+        case PosLookahead, NegLookahead, PosLookbehind, NegLookbehind, DefineGroup:
+            return 0, true
+        case ConditionalGroup:
+            yes, no := conditionalBranches(n)
+            if no == nil {
+                no = &Concat{}
+            }
+            return fixedWidth(&Alternation{[]Node{yes, no}})
+        }
+        return fixedWidth(n.Body)
+    case *Backref, *Recursion:
+        return 0, false
+    }
+    return 0, true
+}
+
+func (w *renderer) repeat(n *Repeat) {
+    if limit := w.flavor.MaxRepeat(); limit > 0 && (n.Min > limit || n.Max > limit) {
+        w.fail("Repetition counts above %d are not supported by the %s flavor", limit, w.flavor.Name())
+    }
+    if needsGroup(n.Sub) {
+        w.WriteString("(?:")
+        w.node(n.Sub)
+        w.WriteString(")")
+    } else {
+        w.node(n.Sub)
+    }
+
+    switch {
+    case n.Min == 0 && n.Max == 1:
+        w.WriteString("?")
+    case n.Min == 0 && n.Max == -1:
+        w.WriteString("*")
+    case n.Min == 1 && n.Max == -1:
+        w.WriteString("+")
+    case n.Max == -1:
+        fmt.Fprintf(w, "{%d,}", n.Min)
+    case n.Min == n.Max:
+        fmt.Fprintf(w, "{%d}", n.Min)
+    default:
+        fmt.Fprintf(w, "{%d,%d}", n.Min, n.Max)
+    }
+
+    mode := n.Mode
+    if w.invertGreedy && mode == Greedy {
+        mode = Lazy
+    } else if w.invertGreedy && mode == Lazy {
+        mode = Greedy
+    }
+    switch mode {
+    case Lazy:
+        w.WriteString("?")
+    case Possessive:
+        w.require(PossessiveFeature)
+        w.WriteString("+")
+    }
+}
+
+func (w *renderer) group(n *Group) {
+    switch n.Kind {
+    case CaptureGroup, NamedCaptureGroup:
+        w.groups++
+    case FlagGroup:
+        w.groupFlags(n.Flags)
+    case AtomicGroup:
+        w.require(AtomicGroupFeature)
+    case BranchResetGroup:
+        w.require(BranchResetFeature)
+    case PosLookahead, NegLookahead:
+        w.require(LookaheadFeature)
+    case PosLookbehind, NegLookbehind:
+        if w.require(LookbehindFeature) && !w.flavor.Supports(VariableLookbehindFeature) {
+            w.lookbehindWidth(n.Body)
+        }
+    case ConditionalGroup:
+        if w.require(ConditionalFeature) {
+            if alt, ok := n.Body.(*Alternation); ok && len(alt.Alternatives) > 2 {
+                w.fail("Conditional group contains more than two alternatives")
+            }
+            w.reference(n.Num, n.Name)
+            // relative conditions are converted to the absolute group number
+            if num := w.groups + 1 + n.Num; n.Num < 0 && num > 0 && !w.flavor.Supports(RelativeBackrefFeature) {
+                abs := *n
+                abs.Num = num
+                w.WriteString(w.flavor.RenderGroup(&abs))
+                w.node(n.Body)
+                w.WriteString(")")
+                return
+            }
+        }
+    case DefineGroup:
+        if w.require(RecursionFeature) {
+            if _, ok := n.Body.(*Alternation); ok {
+                w.fail("DEFINE group contains more than one alternative")
+            }
+        }
+    }
+    w.WriteString(w.flavor.RenderGroup(n))
+    switch n.Kind {
+    case PosLookahead, NegLookahead, PosLookbehind, NegLookbehind:
+        w.lookarounds++
+        defer func() { w.lookarounds-- }()
+    case FlagGroup:
+        verbose := w.verbose
+        w.verbose = flagState(n.Flags, VerboseFlag, verbose)
+        defer func() { w.verbose = verbose }()
+    }
+    w.node(n.Body)
+    w.WriteString(")")
+}
+
+// lookbehindWidth records an error if the body of a lookbehind does not match a fixed
+// number of characters, which is required of each of its alternatives separately by
+// the flavors supporting alternatives of different lengths
+func (w *renderer) lookbehindWidth(body Node) {
+    alts := []Node{body}
+    if alt, ok := body.(*Alternation); ok && w.flavor.Supports(LookbehindAlternativesFeature) {
+        alts = alt.Alternatives
+    }
+    for _, alt := range alts {
+        if _, ok := fixedWidth(alt); !ok {
+            w.fail(unsupported(VariableLookbehindFeature, w.flavor))
+            return
+        }
+    }
+}
+
+// reference records an error if a group referenced by its number or name does not exist,
+// a negative number is relative to the reference
+func (w *renderer) reference(num int, name string) {
+    switch {
+    case w.numbers == nil:
+    case name != "":
+        if _, ok := w.numbers.names[name]; !ok {
+            w.fail("Reference to group '%s' which does not exist", name)
+        }
+    case num < 0 && w.groups+1+num <= 0:
+        w.fail("Relative reference %d does not refer to a group", num)
+    case num > w.numbers.count:
+        w.fail("Reference to group %d which does not exist", num)
+    }
+}
+
+// groupFlags records the flags of a flag group which are not supported by the flavor,
+// along with charset flags which are combined, turned off or used in the wrong kind of
+// pattern. The locale flag of the whole pattern makes it a pattern of bytes, which is
+// the only kind the locale flag can be used in and the unicode flag cannot
+func (w *renderer) groupFlags(flags []RejexFlag) {
+    if !w.require(GroupFlagsFeature) {
+        return
+    }
+    for _, f := range flags {
+        if _, ok := w.flavor.Flags()[f]; !ok && f != '-' {
+            w.fail("The '%c' flag is not supported by the %s flavor", f, w.flavor.Name())
+        }
+    }
+    on, off, _ := strings.Cut(string(flags), "-")
+    for _, f := range charsetFlags {
+        if strings.ContainsRune(off, rune(f)) {
+            w.fail("The '%c' flag cannot be turned off", f)
+        }
+    }
+    switch {
+    case w.flags[LocaleFlag] && strings.ContainsRune(on, rune(UnicodeFlag)):
+        w.fail("The '%c' flag cannot be used in a pattern of bytes", UnicodeFlag)
+    case !w.flags[LocaleFlag] && strings.ContainsRune(on, rune(LocaleFlag)):
+        w.fail("The '%c' flag can only be used in a pattern of bytes, made by setting it for the whole pattern", LocaleFlag)
+    }
+    w.charsets([]RejexFlag(on))
+}
+
+// charsets records an error if more than one of the exclusive charset flags is set
+func (w *renderer) charsets(flags []RejexFlag) {
+    n := 0
+    for _, f := range charsetFlags {
+        if strings.ContainsRune(string(flags), rune(f)) {
+            n++
+        }
+    }
+    if n > 1 {
+        w.fail("Only one of the %s flags can be used", string(charsetFlags))
+    }
+}
+
+func (w *renderer) assertion(n *Assertion) {
+    // without the multiline flag the line anchors only match at the ends of the text
+    // so they can stand in for the absolute anchors
+    lineAnchored := !w.flags[MultilineFlag]
+
+    kind := n.Kind
+    switch kind {
+    case AssertTextStart:
+        if lineAnchored && !w.flavor.Supports(AbsoluteAnchorFeature) {
+            kind = AssertLineStart
+        } else {
+            w.require(AbsoluteAnchorFeature)
+        }
+    case AssertTextEnd:
+        if lineAnchored && !w.flavor.Supports(AbsoluteAnchorFeature) {
+            kind = AssertLineEnd
+        } else {
+            w.require(AbsoluteAnchorFeature)
+        }
+    case AssertTextEndNewline:
+        end := AssertLineEnd
+        if w.flavor.Supports(AbsoluteAnchorFeature) {
+            end = AssertTextEnd
+        }
+        if (lineAnchored || end == AssertTextEnd) && !w.flavor.Supports(NewlineEndAnchorFeature) &&
+            w.flavor.Supports(LookaheadFeature) {
+            // (?=\n?$) or (?=\n?\z)
+            w.WriteString(w.flavor.RenderGroup(&Group{Kind: PosLookahead}))
+            w.char('\n', false)
+            w.WriteString("?" + w.flavor.RenderAssertion(end) + ")")
+            return
+        }
+        w.require(NewlineEndAnchorFeature)
+    case AssertLastMatchEnd:
+        w.require(LastMatchEndFeature)
+    case AssertMatchStart:
+        if w.require(MatchStartFeature) && w.lookarounds > 0 {
+            w.fail("Match start resets are not allowed in lookarounds")
+        }
+    }
+    w.WriteString(w.flavor.RenderAssertion(kind))
+}
+
+func (w *renderer) backref(n *Backref) {
+    if w.require(BackrefFeature) {
+        if n.Name != "" {
+            w.require(NamedBackrefFeature)
+        } else if n.Num < 0 && !w.flavor.Supports(RelativeBackrefFeature) {
+            // relative references are converted to the absolute group number
+            if num := w.groups + 1 + n.Num; num > 0 {
+                w.WriteString(w.flavor.RenderBackref(&Backref{Num: num}))
+                return
+            }
+            w.fail("Relative backreference %d does not refer to a group", n.Num)
+        }
+    }
+    w.WriteString(w.flavor.RenderBackref(n))
+}
+
+func (w *renderer) recursion(n *Recursion) {
+    if w.require(RecursionFeature) {
+        w.reference(n.Num, n.Name)
+    }
+    switch {
+    case n.Name != "":
+        fmt.Fprintf(w, "(?&%s)", n.Name)
+    case n.Num == 0:
+        w.WriteString("(?R)")
+    default:
+        fmt.Fprintf(w, "(?%d)", n.Num)
+    }
+}
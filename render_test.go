@@ -0,0 +1,134 @@
+package rejex
+
+import (
+    "reflect"
+    "testing"
+)
+
+// roundTrips holds patterns of each flavor which are written back as they are
+var roundTrips = map[RejexFlavor][]string{
+    GoFlavor: {
+        `^[a-z]+\d*$`,
+        `(?P<year>\d{4})-(?P<month>\d{2})`,
+        `(?i)ab(?-i:c)|[^\s\w]`,
+        `a{2,5}?b{3}c*?`,
+        `\Aa\.b\z`,
+        `\p{Greek}\PL`,
+        `(?s:.)\b\B`,
+    },
+    ECMAFlavor: {
+        `/^(?<n>a)\k<n>$/`,
+        `/(?<=a)b(?!c)/`,
+        `/[^\d\-]+?/i`,
+        `/\p{Script=Greek}/u`,
+    },
+    PerlFlavor: {
+        `/(a)\g{-1}\1/`,
+        `/(?>a+)b++c?+/`,
+        `/(?|(a)|(b))\G/`,
+        `/(?<n>a)\k<n>\X/ims`,
+    },
+    PCREFlavor: {
+        `(?<n>a)\k<n>(?&n)`,
+        `(*SKIP)(*FAIL)|a`,
+        `(?(DEFINE)(?<d>\d))(?&d)`,
+        `\Aa\Z\G`,
+    },
+    PythonFlavor: {
+        `(?P<n>a)(?P=n)`,
+        `(?a)\w+\Z`,
+        `(?<=ab)c|(?<!d)e`,
+        `(?i:a)(?s:.)`,
+        `(a)?(?(1)b|c)`,
+    },
+}
+
+// TestRoundTrip checks that each pattern is parsed and rendered back as it was, and that
+// the rendered pattern is parsed to the same tree
+func TestRoundTrip(t *testing.T) {
+    for flavor, patterns := range roundTrips {
+        for _, pattern := range patterns {
+            r := fromString(flavor, pattern)
+            got, errs := r.Build()
+            if failed(errs) || got != pattern {
+                t.Errorf("%s in the %s flavor is rendered as %s %v", pattern, flavor, got, errs)
+                continue
+            }
+            if again := fromString(flavor, got); !reflect.DeepEqual(again.Tree(), r.Tree()) {
+                t.Errorf("%s in the %s flavor is parsed to a different tree when rendered", pattern, flavor)
+            }
+        }
+    }
+}
+
+// TestConvert checks the translation of patterns to the syntax of other flavors
+func TestConvert(t *testing.T) {
+    tests := []struct {
+        from RejexFlavor
+        pattern string
+        to RejexFlavor
+        want string
+    }{
+        {GoFlavor, `(?i)ab`, ECMAFlavor, `/ab/i`},
+        {GoFlavor, `(?s).`, PerlFlavor, `/./s`},
+        {GoFlavor, `\Aa\z`, ECMAFlavor, `/^a$/`},
+        {GoFlavor, `\Aa\z`, PythonFlavor, `\Aa\Z`},
+        {GoFlavor, `\p{Greek}`, ECMAFlavor, `/\p{Script=Greek}/u`},
+        {GoFlavor, `[[:alpha:]]`, ECMAFlavor, `/[A-Za-z]/`},
+        {PCREFlavor, `(?<n>a)\k<n>`, PythonFlavor, `(?P<n>a)(?P=n)`},
+        {PythonFlavor, `(?P<n>a)(?P=n)`, ECMAFlavor, `/(?<n>a)\k<n>/`},
+        {PythonFlavor, `a\Z`, GoFlavor, `a\z`},
+        {ECMAFlavor, `/a$/m`, GoFlavor, `(?m)a$`},
+    }
+    for _, test := range tests {
+        got, errs := fromString(test.from, test.pattern).BuildFor(test.to)
+        if len(errs) > 0 || got != test.want {
+            t.Errorf("%s in the %s flavor is rendered in the %s flavor as %s %v, want %s", test.pattern, test.from, test.to, got, errs, test.want)
+        }
+    }
+}
+
+// TestConvertUnsupported checks that constructs which cannot be expressed in a flavor are
+// reported as errors
+func TestConvertUnsupported(t *testing.T) {
+    tests := []struct {
+        from RejexFlavor
+        pattern string
+        to RejexFlavor
+    }{
+        {PCREFlavor, `(a)\1`, GoFlavor},
+        {PCREFlavor, `(?<=a)b`, GoFlavor},
+        {PCREFlavor, `a++`, ECMAFlavor},
+        {PCREFlavor, `(?R)`, ECMAFlavor},
+        {PCREFlavor, `\p{Greek}`, PythonFlavor},
+        {GoFlavor, `(?i:a)b`, ECMAFlavor},
+        {PythonFlavor, `(?x)a b`, GoFlavor},
+    }
+    for _, test := range tests {
+        if _, errs := fromString(test.from, test.pattern).BuildFor(test.to); !failed(errs) {
+            t.Errorf("%s in the %s flavor is rendered in the %s flavor without errors", test.pattern, test.from, test.to)
+        }
+    }
+}
+
+// TestECMAAstral checks that characters outside of the BMP add the unicode flag, without
+// which they are written as two code units
+func TestECMAAstral(t *testing.T) {
+    tests := []struct {
+        builder *RejexBuilder
+        want string
+    }{
+        {NewECMARejex().Characters("😀").Builder(), `/😀/u`},
+        {NewECMARejex().Characters("😀").OneOrMoreOf("").Builder(), `/😀+/u`},
+        {NewECMARejex().AnyFromCharRange("😀", "🙏").Builder(), `/[😀-🙏]/u`},
+        {NewECMARejex().AnyFromCharRange("a", "z").Builder(), `/[a-z]/`},
+        {fromString(GoFlavor, `[\x{1F600}-\x{1F64F}]`), `/[😀-🙏]/u`},
+        {fromString(GoFlavor, `(?i)a\x{1F600}{2}`), `/a😀{2}/iu`},
+    }
+    for _, test := range tests {
+        got, errs := test.builder.BuildFor(ECMAFlavor)
+        if len(errs) > 0 || got != test.want {
+            t.Errorf("rendered as %s %v, want %s", got, errs, test.want)
+        }
+    }
+}
@@ -0,0 +1,129 @@
+package rejex
+
+import (
+    "reflect"
+    "runtime"
+    "strings"
+    "unicode"
+)
+
+var packagePath = reflect.TypeOf(RejexBuilder{}).PkgPath()
+
+// span is the range of the rendered pattern a node was written to
+type span struct {
+    start, end int
+    node Node
+}
+
+// callerName returns the name of the exported function or method of this package
+// which was called from outside of it and led to this call. The tests of the package
+// count as outside of it
+func callerName() string {
+    pcs := make([]uintptr, 32)
+    n := runtime.Callers(2, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+
+    var name string
+    for {
+        frame, more := frames.Next()
+        if !strings.HasPrefix(frame.Function, packagePath+".") || strings.HasSuffix(frame.File, "_test.go") {
+            break
+        }
+        fn := frame.Function[strings.LastIndex(frame.Function, ".")+1:]
+        if fn != "" && unicode.IsUpper(rune(fn[0])) {
+            name = fn
+        }
+        if !more {
+            break
+        }
+    }
+    return name
+}
+
+// record notes the chain method which produced a node, unless one was noted already
+func (r *RejexBuilder) record(n Node) {
+    if _, ok := r.segments[n]; ok {
+        return
+    }
+    if name := callerName(); name != "" {
+        r.segments[n] = name
+    }
+}
+
+// segmentAt returns the chain method which produced the innermost node rendered at
+// a position of a pattern rendered for the flavor
+func (r *RejexBuilder) segmentAt(flavor RejexFlavor, pos int) string {
+    root, origins := r.renderedTree(flavor)
+    w := renderer{flavor: flavor, flags: r.flags, options: r.options}
+    w.pattern(root)
+
+    var name string
+    size := -1
+    for _, s := range w.spans {
+        start, end := s.start+w.prefix, s.end+w.prefix
+        if pos < start || pos >= end && !(pos == end && end == start) {
+            continue
+        }
+        call, ok := r.segments[s.node]
+        if !ok {
+            call, ok = r.segments[origins[s.node]]
+        }
+        if ok && (size < 0 || end-start <= size) {
+            name, size = call, end-start
+        }
+    }
+    return name
+}
+
+// methodOf returns the chain method which produced a node, or the method which would
+// produce it for nodes parsed from a regex string
+func (r *RejexBuilder) methodOf(n Node) string {
+    if name, ok := r.segments[n]; ok && !strings.HasPrefix(name, "New") {
+        return name
+    }
+    switch n := n.(type) {
+    case *Class:
+        calls := classCalls(r.flavor, n)
+        if calls[0].method == "Not" {
+            return calls[1].method
+        }
+        return calls[0].method
+    case *Repeat:
+        return quantifierCall(n, "").method
+    case *Group:
+        if n.Kind == ConditionalGroup && n.Name != "" {
+            return "BeginConditionalGroupByName"
+        }
+        return groupMethods[n.Kind]
+    case *Assertion:
+        if c, ok := assertionMethods[n.Kind]; ok {
+            return c.method
+        }
+        if n.Kind == AssertNonWordBoundary {
+            return "WordBoundary"
+        }
+    case *Backref:
+        if n.Name != "" {
+            return "CapturedPatternByName"
+        }
+        return "CapturedPatternByNum"
+    case *Recursion:
+        return recursionCall(n).method
+    case *Verb:
+        return "BacktrackingVerb"
+    }
+    return "Characters"
+}
+
+// positionOf returns the position of a node in the pattern rendered for the flavor
+func (r *RejexBuilder) positionOf(flavor RejexFlavor, n Node) int {
+    root, origins := r.renderedTree(flavor)
+    w := renderer{flavor: flavor, flags: r.flags, options: r.options}
+    w.pattern(root)
+    for _, s := range w.spans {
+        if s.node == n || origins[s.node] == n {
+            return s.start
+        }
+    }
+    return 0
+}
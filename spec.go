@@ -0,0 +1,668 @@
+package rejex
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "reflect"
+    "strconv"
+    "strings"
+
+    "gopkg.in/yaml.v3"
+)
+
+// Spec is a declarative description of a pattern which can be stored as JSON or YAML,
+// such as `{"seq":[{"anchor":"start"},{"class":"digit","repeat":{"min":2,"max":4}}]}`.
+// The pattern is either a sequence or an alternation of entries
+type Spec struct {
+    // Flavor is the name of a registered flavor such as "go", "ecma" or "perl", Go by
+    // default
+    Flavor string `json:"flavor,omitempty" yaml:"flavor,omitempty"`
+    // Flags holds the letters of the flags set on the pattern, such as "im"
+    Flags string `json:"flags,omitempty" yaml:"flags,omitempty"`
+    // Options holds the start options of the pattern, such as "UTF"
+    Options []string `json:"options,omitempty" yaml:"options,omitempty"`
+    Seq []SpecNode `json:"seq,omitempty" yaml:"seq,omitempty"`
+    Alt []SpecNode `json:"alt,omitempty" yaml:"alt,omitempty"`
+}
+
+// SpecNode is a single entry of a spec, it sets exactly one of the fields describing
+// what it matches. A group sets Group along with either Seq or Alt as its body, and any
+// entry can be repeated
+type SpecNode struct {
+    // Group is the kind of a group, such as "capture" or "lookahead"
+    Group string `json:"group,omitempty" yaml:"group,omitempty"`
+    // Name is the name of a capture group
+    Name string `json:"name,omitempty" yaml:"name,omitempty"`
+    // Flags holds the letters of the flags of a flags group, those following a '-' are turned off
+    Flags string `json:"flags,omitempty" yaml:"flags,omitempty"`
+    // Seq matches its entries one after the other
+    Seq []SpecNode `json:"seq,omitempty" yaml:"seq,omitempty"`
+    // Alt matches any one of its entries
+    Alt []SpecNode `json:"alt,omitempty" yaml:"alt,omitempty"`
+    // Text matches its text exactly, like EscapedCharacters
+    Text string `json:"text,omitempty" yaml:"text,omitempty"`
+    // Raw is regex syntax of the flavor, like Characters
+    Raw string `json:"raw,omitempty" yaml:"raw,omitempty"`
+    // Class is the name of a class method, such as "digit" for AnyDigit
+    Class string `json:"class,omitempty" yaml:"class,omitempty"`
+    // Unicode is the name of a unicode class, like UnicodeClass
+    Unicode string `json:"unicode,omitempty" yaml:"unicode,omitempty"`
+    // AnyFrom matches any of its characters, like AnyFrom
+    AnyFrom string `json:"anyFrom,omitempty" yaml:"anyFrom,omitempty"`
+    // Range holds the first and last characters of a range, like AnyFromCharRange
+    Range []string `json:"range,omitempty" yaml:"range,omitempty"`
+    // Set matches any of its classes, like a selection set
+    Set []SpecNode `json:"set,omitempty" yaml:"set,omitempty"`
+    // Anchor is the name of an anchor method, such as "start" for Starting
+    Anchor string `json:"anchor,omitempty" yaml:"anchor,omitempty"`
+    // Ref is the number of the group referenced by a backreference
+    Ref *int `json:"ref,omitempty" yaml:"ref,omitempty"`
+    // RefName is the name of the group referenced by a backreference
+    RefName string `json:"refName,omitempty" yaml:"refName,omitempty"`
+    // Subroutine is the number of the group whose pattern is matched again, like
+    // SubroutineByNum, 0 matching the whole pattern again like Recurse
+    Subroutine *int `json:"subroutine,omitempty" yaml:"subroutine,omitempty"`
+    // SubroutineName is the name of the group whose pattern is matched again
+    SubroutineName string `json:"subroutineName,omitempty" yaml:"subroutineName,omitempty"`
+    // Verb is a backtracking control verb, like BacktrackingVerb
+    Verb string `json:"verb,omitempty" yaml:"verb,omitempty"`
+
+    // Not negates a class, a set or a word boundary
+    Not bool `json:"not,omitempty" yaml:"not,omitempty"`
+    Repeat *SpecRepeat `json:"repeat,omitempty" yaml:"repeat,omitempty"`
+}
+
+// SpecRepeat repeats a spec entry between Min and Max times, without an upper bound if
+// Max is not set. Mode is "greedy" by default, "lazy" or "possessive"
+type SpecRepeat struct {
+    Min int `json:"min,omitempty" yaml:"min,omitempty"`
+    Max *int `json:"max,omitempty" yaml:"max,omitempty"`
+    Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+// specClasses holds the methods constructing the classes named in specs
+var specClasses = map[string]string{
+    "any": "AnyChar",
+    "digit": "AnyDigit",
+    "word": "AnyWordChar",
+    "whitespace": "AnyWhitespace",
+    "letter": "AnyLetter",
+    "uppercase": "AnyUppercase",
+    "lowercase": "AnyLowercase",
+    "alnum": "AnyAlNumChar",
+    "punctuation": "AnyPunctuation",
+    "graphic": "AnyGraphicChar",
+    "ascii": "AnyASCIIChar",
+    "control": "AnyControlChar",
+    "line-ending": "LineEnding",
+    "grapheme": "AnyUnicodeGrapheme",
+    "linebreak": "AnyLinebreak",
+    "unicode-letter": "AnyUnicodeLetter",
+    "unicode-uppercase": "AnyUnicodeUppercase",
+    "unicode-lowercase": "AnyUnicodeLowercase",
+    "unicode-whitespace": "AnyUnicodeWhitespace",
+    "unicode-symbol": "AnyUnicodeSymbol",
+    "unicode-number": "AnyUnicodeNumber",
+    "unicode-punctuation": "AnyUnicodePunctuation",
+}
+
+var specAnchors = map[string]AssertionKind{
+    "start": AssertLineStart,
+    "absolute-start": AssertTextStart,
+    "end": AssertLineEnd,
+    "absolute-end": AssertTextEnd,
+    "word-boundary": AssertWordBoundary,
+    "last-match-end": AssertLastMatchEnd,
+}
+
+var specGroups = map[string]GroupKind{
+    "capture": CaptureGroup,
+    "non-capture": NonCaptureGroup,
+    "flags": FlagGroup,
+    "atomic": AtomicGroup,
+    "branch-reset": BranchResetGroup,
+    "lookahead": PosLookahead,
+    "neg-lookahead": NegLookahead,
+    "lookbehind": PosLookbehind,
+    "neg-lookbehind": NegLookbehind,
+    "define": DefineGroup,
+}
+
+var specModes = map[string]RepeatMode{
+    "": Greedy,
+    "greedy": Greedy,
+    "lazy": Lazy,
+    "possessive": Possessive,
+}
+
+// flavorInterfaces holds the interface of the chain methods available in each flavor
+var flavorInterfaces = map[RejexFlavor]reflect.Type{
+    GoFlavor: reflect.TypeOf((*GoFlavorInterface)(nil)).Elem(),
+    ECMAFlavor: reflect.TypeOf((*ECMAFlavorInterface)(nil)).Elem(),
+    PerlFlavor: reflect.TypeOf((*PerlFlavorInterface)(nil)).Elem(),
+    PCREFlavor: reflect.TypeOf((*PCREFlavorInterface)(nil)).Elem(),
+    PythonFlavor: reflect.TypeOf((*PythonFlavorInterface)(nil)).Elem(),
+}
+
+// specName returns the key of a map holding a value
+func specName[V comparable](names map[string]V, v V) string {
+    for name, value := range names {
+        if value == v && name != "" {
+            return name
+        }
+    }
+    return ""
+}
+
+// specWriter converts a syntax tree into spec entries
+type specWriter struct {
+    flavor RejexFlavor
+}
+
+// sequence returns the entries of a node used as the body of a group or of the pattern,
+// along with the alternatives if it is an alternation
+func (s specWriter) sequence(n Node) ([]SpecNode, []SpecNode) {
+    switch n := n.(type) {
+    case *Alternation:
+        var alts []SpecNode
+        for _, alt := range n.Alternatives {
+            alts = append(alts, s.node(alt))
+        }
+        return nil, alts
+    case *Concat:
+        var seq []SpecNode
+        for _, sub := range n.Nodes {
+            seq = append(seq, s.node(sub))
+        }
+        return seq, nil
+    }
+    return []SpecNode{s.node(n)}, nil
+}
+
+func (s specWriter) node(n Node) SpecNode {
+    switch n := n.(type) {
+    case *Concat, *Alternation:
+        seq, alt := s.sequence(n)
+        return SpecNode{Seq: seq, Alt: alt}
+    case *Literal:
+        return SpecNode{Text: n.Text}
+    case *Raw:
+        return SpecNode{Raw: n.Text}
+    case *Class:
+        return s.class(n)
+    case *Repeat:
+        sub := s.node(n.Sub)
+        if sub.Repeat != nil {
+            sub = SpecNode{Seq: []SpecNode{sub}}
+        }
+        sub.Repeat = &SpecRepeat{Min: n.Min}
+        if n.Max != -1 {
+            max := n.Max
+            sub.Repeat.Max = &max
+        }
+        if n.Mode != Greedy {
+            sub.Repeat.Mode = specName(specModes, n.Mode)
+        }
+        return sub
+    case *Group:
+        if n.Kind == ConditionalGroup {
+            return SpecNode{Raw: render(s.flavor, n)}
+        }
+        g := SpecNode{Group: specName(specGroups, n.Kind), Name: n.Name, Flags: flagsText(n.Flags)}
+        if n.Kind == NamedCaptureGroup {
+            g.Group = "capture"
+        }
+        g.Seq, g.Alt = s.sequence(n.Body)
+        return g
+    case *Assertion:
+        if n.Kind == AssertNonWordBoundary {
+            return SpecNode{Anchor: "word-boundary", Not: true}
+        }
+        if name := specName(specAnchors, n.Kind); name != "" {
+            return SpecNode{Anchor: name}
+        }
+        return SpecNode{Raw: render(s.flavor, n)}
+    case *Backref:
+        if n.Name != "" {
+            return SpecNode{RefName: n.Name}
+        }
+        num := n.Num
+        return SpecNode{Ref: &num}
+    case *Recursion:
+        if n.Name != "" {
+            return SpecNode{SubroutineName: n.Name}
+        }
+        num := n.Num
+        return SpecNode{Subroutine: &num}
+    case *Verb:
+        if n.Arg != "" {
+            return SpecNode{Verb: n.Name + ":" + n.Arg}
+        }
+        return SpecNode{Verb: n.Name}
+    }
+    return SpecNode{}
+}
+
+// class returns the entry of a class, made of the calls constructing it
+func (s specWriter) class(c *Class) SpecNode {
+    calls := classCalls(s.flavor, c)
+    if calls[0].method == "BeginSelectionSet" || calls[0].method == "BeginNonSelectionSet" {
+        set := SpecNode{Not: c.Negated}
+        for _, call := range calls[1 : len(calls)-1] {
+            set.Set = append(set.Set, classEntry(call))
+        }
+        return set
+    }
+    if calls[0].method == "Not" {
+        n := classEntry(calls[1])
+        n.Not = true
+        return n
+    }
+    return classEntry(calls[0])
+}
+
+// classEntry returns the entry of a call constructing a class
+func classEntry(c chainCall) SpecNode {
+    var args []string
+    for _, arg := range c.args {
+        s, _ := strconv.Unquote(arg)
+        args = append(args, s)
+    }
+    switch c.method {
+    case "AnyFrom":
+        return SpecNode{AnyFrom: args[0]}
+    case "AnyFromCharRange":
+        return SpecNode{Range: args}
+    case "UnicodeClass":
+        return SpecNode{Unicode: args[0]}
+    }
+    return SpecNode{Class: specName(specClasses, c.method)}
+}
+
+func flagsText(flags []RejexFlag) string {
+    var b strings.Builder
+    for _, f := range flags {
+        b.WriteRune(rune(f))
+    }
+    return b.String()
+}
+
+// Spec returns the spec describing the pattern constructed so far
+func (r *RejexBuilder) Spec() (*Spec, error) {
+    if r.selection != nil || len(r.frames) > 1 {
+        return nil, fmt.Errorf("Cannot describe a pattern with an open group or selection set")
+    }
+    s := specWriter{r.flavor}
+    spec := &Spec{Flavor: strings.ToLower(r.flavor.Name()), Flags: flagsText(setFlags(r.flags)), Options: r.options}
+    if c, ok := r.Tree().(*Concat); !ok || len(c.Nodes) > 0 {
+        spec.Seq, spec.Alt = s.sequence(r.Tree())
+    }
+    return spec, nil
+}
+
+// marshalSpec encodes the spec of the builder as JSON, leaving characters such as '&'
+// and '<' which are common in patterns unescaped
+func (r *RejexBuilder) marshalSpec(indent string) ([]byte, error) {
+    spec, err := r.Spec()
+    if err != nil {
+        return nil, err
+    }
+    var b bytes.Buffer
+    enc := json.NewEncoder(&b)
+    enc.SetEscapeHTML(false)
+    enc.SetIndent("", indent)
+    if err := enc.Encode(spec); err != nil {
+        return nil, err
+    }
+    return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// Marshal returns the spec describing the pattern as indented JSON, which is also valid
+// YAML and can be loaded back with Load
+func (r *RejexBuilder) Marshal() ([]byte, error) {
+    return r.marshalSpec("  ")
+}
+
+// MarshalJSON encodes the builder as the spec describing its pattern
+func (r *RejexBuilder) MarshalJSON() ([]byte, error) {
+    return r.marshalSpec("")
+}
+
+// MarshalYAML encodes the builder as the spec describing its pattern
+func (r *RejexBuilder) MarshalYAML() (interface{}, error) {
+    spec, err := r.Spec()
+    if err != nil {
+        return nil, err
+    }
+    return spec, nil
+}
+
+// specLoader calls the chain methods described by the entries of a spec. Methods which
+// are not part of the interface of the flavor are reported as errors
+type specLoader struct {
+    r *RejexBuilder
+    methods reflect.Type
+}
+
+func (l *specLoader) call(path, method string, args ...interface{}) error {
+    f, restricted := methodFeatures[method]
+    if _, ok := l.methods.MethodByName(method); !ok || restricted && !l.r.flavor.Supports(f) {
+        return fmt.Errorf("%s: '%s()' is not supported in the %s flavor", path, method, l.r.flavor.Name())
+    }
+    in := make([]reflect.Value, len(args))
+    for i, arg := range args {
+        in[i] = reflect.ValueOf(arg)
+    }
+    reflect.ValueOf(l.r).MethodByName(method).Call(in)
+    return nil
+}
+
+// kinds returns the names of the fields describing what an entry matches
+func (n SpecNode) kinds() []string {
+    var kinds []string
+    add := func(set bool, name string) {
+        if set {
+            kinds = append(kinds, name)
+        }
+    }
+    add(n.Seq != nil, "seq")
+    add(n.Alt != nil, "alt")
+    add(n.Text != "", "text")
+    add(n.Raw != "", "raw")
+    add(n.Class != "", "class")
+    add(n.Unicode != "", "unicode")
+    add(n.AnyFrom != "", "anyFrom")
+    add(n.Range != nil, "range")
+    add(n.Set != nil, "set")
+    add(n.Anchor != "", "anchor")
+    add(n.Ref != nil, "ref")
+    add(n.RefName != "", "refName")
+    add(n.Subroutine != nil, "subroutine")
+    add(n.SubroutineName != "", "subroutineName")
+    add(n.Verb != "", "verb")
+    return kinds
+}
+
+// sequence calls the methods of the entries of a sequence, or of the alternatives of
+// an alternation, written directly into the enclosing group
+func (l *specLoader) sequence(path string, seq, alt []SpecNode) error {
+    if seq != nil && alt != nil {
+        return fmt.Errorf("%s: Both 'seq' and 'alt' are set", path)
+    }
+    for i, n := range seq {
+        if err := l.node(fmt.Sprintf("%sseq[%d]", path, i), n, len(seq) == 1); err != nil {
+            return err
+        }
+    }
+    for i, n := range alt {
+        if i > 0 {
+            if err := l.call(path, "Or"); err != nil {
+                return err
+            }
+        }
+        if err := l.node(fmt.Sprintf("%salt[%d]", path, i), n, false); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// node calls the methods of an entry, alone reports whether it is the only entry of
+// a group so an alternation can be written without a group of its own
+func (l *specLoader) node(path string, n SpecNode, alone bool) error {
+    kinds := n.kinds()
+    if n.Group != "" {
+        if len(kinds) > 1 || len(kinds) == 1 && kinds[0] != "seq" && kinds[0] != "alt" {
+            return fmt.Errorf("%s: A group can only contain 'seq' or 'alt'", path)
+        }
+        return l.group(path, n)
+    }
+    switch {
+    case len(kinds) == 0:
+        return fmt.Errorf("%s: Entry does not describe what it matches", path)
+    case len(kinds) > 1:
+        return fmt.Errorf("%s: Only one of %s can be set", path, strings.Join(kinds, ", "))
+    }
+    if n.Name != "" || n.Flags != "" {
+        return fmt.Errorf("%s: 'name' and 'flags' can only be set on a group", path)
+    }
+    if n.Not && n.Class == "" && n.Unicode == "" && n.AnyFrom == "" && n.Range == nil && n.Set == nil && n.Anchor != "word-boundary" {
+        return fmt.Errorf("%s: Only classes, sets and word boundaries can be negated", path)
+    }
+
+    var err error
+    switch {
+    case n.Seq != nil && n.Repeat != nil, n.Alt != nil && (n.Repeat != nil || !alone):
+        // sequences and alternations are grouped to be repeated or followed by other entries
+        return l.group(path, SpecNode{Group: "non-capture", Seq: n.Seq, Alt: n.Alt, Repeat: n.Repeat})
+    case n.Seq != nil:
+        for i, sub := range n.Seq {
+            if err := l.node(fmt.Sprintf("%s.seq[%d]", path, i), sub, alone && len(n.Seq) == 1); err != nil {
+                return err
+            }
+        }
+        return nil
+    case n.Alt != nil:
+        err = l.sequence(path+".", nil, n.Alt)
+    case n.Text != "":
+        err = l.call(path, "EscapedCharacters", n.Text)
+    case n.Raw != "":
+        err = l.call(path, "Characters", n.Raw)
+    case n.Set != nil:
+        err = l.set(path, n)
+    case n.Anchor != "":
+        err = l.anchor(path, n)
+    case n.Ref != nil:
+        err = l.call(path, "CapturedPatternByNum", *n.Ref)
+    case n.RefName != "":
+        err = l.call(path, "CapturedPatternByName", n.RefName)
+    case n.Subroutine != nil && *n.Subroutine == 0:
+        err = l.call(path, "Recurse")
+    case n.Subroutine != nil:
+        err = l.call(path, "SubroutineByNum", *n.Subroutine)
+    case n.SubroutineName != "":
+        err = l.call(path, "SubroutineByName", n.SubroutineName)
+    case n.Verb != "":
+        err = l.call(path, "BacktrackingVerb", n.Verb)
+    default:
+        if n.Not {
+            err = l.call(path, "Not")
+        }
+        if err == nil {
+            err = l.class(path, n)
+        }
+    }
+    if err != nil {
+        return err
+    }
+    return l.repeat(path, n.Repeat)
+}
+
+// class calls the method of an entry matching a single character
+func (l *specLoader) class(path string, n SpecNode) error {
+    switch {
+    case n.Class != "":
+        method, ok := specClasses[n.Class]
+        if !ok {
+            return fmt.Errorf("%s: Unknown class '%s'", path, n.Class)
+        }
+        return l.call(path, method)
+    case n.Unicode != "":
+        return l.call(path, "UnicodeClass", n.Unicode)
+    case n.AnyFrom != "":
+        return l.call(path, "AnyFrom", n.AnyFrom)
+    case n.Range != nil:
+        if len(n.Range) != 2 {
+            return fmt.Errorf("%s: A range should have 2 characters", path)
+        }
+        return l.call(path, "AnyFromCharRange", n.Range[0], n.Range[1])
+    case n.Text != "":
+        return l.call(path, "EscapedCharacters", n.Text)
+    }
+    return fmt.Errorf("%s: Only classes can be used in a set", path)
+}
+
+func (l *specLoader) set(path string, n SpecNode) error {
+    begin := "BeginSelectionSet"
+    if n.Not {
+        begin = "BeginNonSelectionSet"
+    }
+    if err := l.call(path, begin); err != nil {
+        return err
+    }
+    for i, item := range n.Set {
+        itemPath := fmt.Sprintf("%s.set[%d]", path, i)
+        if len(item.kinds()) != 1 || item.Not || item.Repeat != nil || item.Group != "" {
+            return fmt.Errorf("%s: Only single classes can be used in a set", itemPath)
+        }
+        if err := l.class(itemPath, item); err != nil {
+            return err
+        }
+    }
+    return l.call(path, "EndSelectionSet")
+}
+
+func (l *specLoader) anchor(path string, n SpecNode) error {
+    kind, ok := specAnchors[n.Anchor]
+    if !ok {
+        return fmt.Errorf("%s: Unknown anchor '%s'", path, n.Anchor)
+    }
+    if n.Not {
+        if err := l.call(path, "Not"); err != nil {
+            return err
+        }
+    }
+    return l.call(path, assertionMethods[kind].method)
+}
+
+func (l *specLoader) group(path string, n SpecNode) error {
+    kind, ok := specGroups[n.Group]
+    if !ok {
+        return fmt.Errorf("%s: Unknown group '%s'", path, n.Group)
+    }
+    if n.Not {
+        return fmt.Errorf("%s: Only classes, sets and word boundaries can be negated", path)
+    }
+    if n.Name != "" && kind != CaptureGroup || n.Flags != "" && kind != FlagGroup {
+        return fmt.Errorf("%s: 'name' can only be set on capture groups and 'flags' on flags groups", path)
+    }
+
+    var err error
+    switch {
+    case n.Name != "":
+        err = l.call(path, groupMethods[NamedCaptureGroup], n.Name)
+    case kind == FlagGroup:
+        err = l.call(path, groupMethods[kind], []RejexFlag(n.Flags))
+    default:
+        err = l.call(path, groupMethods[kind])
+    }
+    if err != nil {
+        return err
+    }
+    if err := l.sequence(path+".", n.Seq, n.Alt); err != nil {
+        return err
+    }
+    if err := l.call(path, "EndGroup"); err != nil {
+        return err
+    }
+    return l.repeat(path, n.Repeat)
+}
+
+// repeat calls the quantifier of a repeated entry
+func (l *specLoader) repeat(path string, rep *SpecRepeat) error {
+    if rep == nil {
+        return nil
+    }
+    path += ".repeat"
+    n := &Repeat{Min: rep.Min, Max: -1}
+    if rep.Max != nil {
+        n.Max = *rep.Max
+    }
+    if n.Min < 0 || n.Max != -1 && n.Max < n.Min {
+        return fmt.Errorf("%s: Invalid repeat bounds", path)
+    }
+    mode, ok := specModes[rep.Mode]
+    if !ok {
+        return fmt.Errorf("%s: Unknown mode '%s'", path, rep.Mode)
+    }
+
+    var err error
+    switch c := quantifierCall(n, ""); c.method {
+    case "NOf", "NOrMoreOf":
+        err = l.call(path, c.method, "", n.Min)
+    case "NToMOf":
+        err = l.call(path, c.method, "", n.Min, n.Max)
+    default:
+        err = l.call(path, c.method, "")
+    }
+    switch {
+    case err != nil:
+    case mode == Lazy:
+        err = l.call(path, "PreferFewer")
+    case mode == Possessive:
+        err = l.call(path, "PossessiveQuantifier")
+    }
+    return err
+}
+
+// Load reads a spec as JSON or YAML and returns the builder of the pattern it describes.
+// Unknown fields, malformed entries and methods which are not available in the flavor
+// of the spec are reported as an error, errors of the chain methods are reported by the
+// builder like with any other chain
+func Load(reader io.Reader, ignoreErrors ...bool) (*RejexBuilder, error) {
+    // JSON is a subset of YAML
+    dec := yaml.NewDecoder(reader)
+    dec.KnownFields(true)
+    var spec Spec
+    if err := dec.Decode(&spec); err != nil {
+        return nil, fmt.Errorf("Invalid spec: %w", err)
+    }
+    // the reader holds a single spec
+    var extra yaml.Node
+    if err := dec.Decode(&extra); err != io.EOF {
+        if err == nil {
+            err = errors.New("Unexpected data after the spec")
+        }
+        return nil, fmt.Errorf("Invalid spec: %w", err)
+    }
+
+    flavor, ok := LookupFlavor(spec.Flavor)
+    if spec.Flavor == "" {
+        flavor, ok = GoFlavor, true
+    }
+    if !ok {
+        return nil, fmt.Errorf("Unknown flavor '%s'", spec.Flavor)
+    }
+    // flavors registered outside of this package have the methods of their base flavor
+    methods := flavorInterfaces[flavor.Base()]
+
+    l := specLoader{r: createRejexBuilder(flavor, ignoreErrors), methods: methods}
+    if err := l.sequence("", spec.Seq, spec.Alt); err != nil {
+        return nil, err
+    }
+    if spec.Flags != "" {
+        var flags []interface{}
+        for _, f := range spec.Flags {
+            if _, ok := flavor.Flags()[RejexFlag(f)]; !ok {
+                return nil, fmt.Errorf("Flag '%c' is not supported in the %s flavor", f, flavor.Name())
+            }
+            flags = append(flags, RejexFlag(f))
+        }
+        if err := l.call("flags", "AddFlags", flags...); err != nil {
+            return nil, err
+        }
+    }
+    if len(spec.Options) > 0 {
+        var options []interface{}
+        for _, opt := range spec.Options {
+            options = append(options, opt)
+        }
+        if err := l.call("options", "AddStartOptions", options...); err != nil {
+            return nil, err
+        }
+    }
+    return l.r, nil
+}
@@ -0,0 +1,75 @@
+package rejex
+
+import (
+    "bytes"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// TestSpecRoundTrip checks that loading the spec of a pattern constructs the same tree
+// with the same flags
+func TestSpecRoundTrip(t *testing.T) {
+    builders := []*RejexBuilder{
+        NewRejex().Starting().AnyDigit().OneOrMoreOf("").Ending().Builder(),
+        NewRejex().BeginNamedCaptureGroup("x").Characters("a").Or().EscapedCharacters("b.").EndGroup().ZeroOrMoreOf("").Builder(),
+        NewRejex().Not().AnyFrom("abc").AnyFromCharRange("0", "9").NOrMoreOf("", 2).PreferFewer().Builder(),
+        NewECMARejex().BeginPosLookahead().Characters("a").EndGroup().UnicodeClass("Greek").Builder(),
+        NewPCRERejex().BeginAtomicGroup().AnyWordChar().EndGroup().Builder(),
+        NewPythonRejex().Characters("x").Builder(),
+        fromString(GoFlavor, `(?i)(a|b)+\bc{2,5}$`),
+        fromString(PerlFlavor, `/(?>a)(b)\1/s`),
+        fromString(PCREFlavor, `(*UTF)a(?=b)`),
+        fromString(PythonFlavor, `(?a)(?P<n>\w)(?P=n)`),
+        fromString(PCREFlavor, `(?(DEFINE)(?<d>\d))(?&d)(?R)?(?1)(?-1)(*SKIP)(*MARK:x)`),
+    }
+    for _, r := range builders {
+        pattern, _ := r.Build()
+        data, err := r.Marshal()
+        if err != nil {
+            t.Errorf("%s is not marshalled: %v", pattern, err)
+            continue
+        }
+        loaded, err := Load(bytes.NewReader(data))
+        if err != nil {
+            t.Errorf("the spec of %s is not loaded: %v\n%s", pattern, err, data)
+            continue
+        }
+        if loaded.flavor != r.flavor || !reflect.DeepEqual(loaded.Tree(), r.Tree()) || !reflect.DeepEqual(loaded.flags, r.flags) || !reflect.DeepEqual(loaded.options, r.options) {
+            got, _ := loaded.Build()
+            t.Errorf("the spec of %s is loaded as %s\n%s", pattern, got, data)
+        }
+    }
+}
+
+// TestLoadErrors checks that malformed specs are reported as errors
+func TestLoadErrors(t *testing.T) {
+    for _, spec := range []string{
+        `{"flavor": "cobol"}`,
+        `{"seq": [{"unknown": 1}]}`,
+        `{"flavor": "go", "seq": [{"unicode": "Greek", "text": "a"}]}`,
+        `{"flavor": "python", "seq": [{"unicode": "Greek"}]}`,
+        `[`,
+        `{"seq": [{"text": "a"}]} {"seq": []}`,
+        "{\"seq\": [{\"text\": \"a\"}]}\n---\n{\"seq\": []}",
+        `{"seq": [{"verb": "SKIP"}]}`,
+        `{"seq": [{"subroutine": 0}]}`,
+        `{"flavor": "pcre", "seq": [{"subroutine": 1, "verb": "SKIP"}]}`,
+    } {
+        if _, err := Load(strings.NewReader(spec)); err == nil {
+            t.Errorf("%s is loaded without errors", spec)
+        }
+    }
+}
+
+// TestMarshalEscaping checks that the characters of patterns are not escaped for HTML
+func TestMarshalEscaping(t *testing.T) {
+    data, err := NewRejex().Characters("a&b<c>").Builder().Marshal()
+    if err != nil || !bytes.Contains(data, []byte(`"a&b<c>"`)) {
+        t.Errorf("spec is marshalled as %s: %v", data, err)
+    }
+    data, err = NewRejex().Characters("&").Builder().MarshalJSON()
+    if err != nil || string(data) != `{"flavor":"go","seq":[{"text":"&"}]}` {
+        t.Errorf("spec is marshalled as %s: %v", data, err)
+    }
+}
@@ -1,6 +1,14 @@
 package rejex
 
+var lineEndingItems = []ClassItem{charRange('\n', '\n'), charRange('\r', '\r'), charRange('\v', '\v'), charRange('\f', '\f')}
+
 // LineEnding matches any single character that starts a new line
 func (r *RejexBuilder) LineEnding() *RejexBuilder {
-    return r.appendSegment(characters, "\\n\\r\\v\\f")
+    return r.appendClass(lineEndingItems...)
+}
+
+// AnyLinebreak matches a single line break, either a character that starts a new line or
+// a carriage return followed by a newline as a whole (\R)
+func (r *RejexBuilder) AnyLinebreak() *RejexBuilder {
+    return r.appendNode(&Class{Items: []ClassItem{{Kind: LinebreakItem}}})
 }
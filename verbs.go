@@ -0,0 +1,82 @@
+package rejex
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// backtrackingVerbs holds the backtracking control verbs of PCRE2 along with whether
+// they need a name
+var backtrackingVerbs = map[string]bool{
+    "ACCEPT": false,
+    "FAIL": false,
+    "MARK": true,
+    "COMMIT": false,
+    "PRUNE": false,
+    "SKIP": false,
+    "THEN": false,
+}
+
+// startOptions holds the start options of PCRE2 along with whether they take a number
+// such as `(*LIMIT_MATCH=1000)`
+var startOptions = map[string]bool{
+    "UTF": false,
+    "UCP": false,
+    "NOTEMPTY": false,
+    "NOTEMPTY_ATSTART": false,
+    "NO_AUTO_POSSESS": false,
+    "NO_DOTSTAR_ANCHOR": false,
+    "NO_JIT": false,
+    "NO_START_OPT": false,
+    "CR": false,
+    "LF": false,
+    "CRLF": false,
+    "ANYCRLF": false,
+    "ANY": false,
+    "NUL": false,
+    "BSR_ANYCRLF": false,
+    "BSR_UNICODE": false,
+    "LIMIT_DEPTH": true,
+    "LIMIT_HEAP": true,
+    "LIMIT_MATCH": true,
+}
+
+// parseVerb reads a backtracking control verb written without its parentheses, such as
+// `SKIP` or `MARK:name`. The short forms `F` and `:name` are read as FAIL and MARK
+func parseVerb(s string) (*Verb, error) {
+    name, arg, named := strings.Cut(s, ":")
+    switch name {
+    case "":
+        name = "MARK"
+    case "F":
+        name = "FAIL"
+    }
+    needsArg, ok := backtrackingVerbs[name]
+    switch {
+    case !ok && validStartOption(s):
+        return nil, fmt.Errorf("Start option '(*%s)' can only be used at the start of the pattern", s)
+    case !ok:
+        return nil, fmt.Errorf("Unknown backtracking control verb '(*%s)'", s)
+    case named && arg == "" || needsArg && !named:
+        return nil, fmt.Errorf("'(*%s)' needs a name", name)
+    case strings.ContainsRune(arg, ')'):
+        return nil, fmt.Errorf("Invalid name '%s' of '(*%s)'", arg, name)
+    }
+    return &Verb{Name: name, Arg: arg}, nil
+}
+
+// validStartOption reports whether a start option written without its parentheses, such
+// as `UTF` or `LIMIT_MATCH=1000`, is valid
+func validStartOption(s string) bool {
+    name, value, hasValue := strings.Cut(s, "=")
+    needsValue, ok := startOptions[name]
+    if !ok || needsValue != hasValue {
+        return false
+    }
+    if hasValue {
+        _, err := strconv.ParseUint(value, 10, 32)
+        return err == nil
+    }
+    return true
+}
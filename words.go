@@ -0,0 +1,227 @@
+package rejex
+
+import (
+    "sort"
+    "unicode"
+)
+
+// WordsOptions changes how the words matched by AnyOfWords are tried
+type WordsOptions struct {
+    // LongestFirst tries longer words before the words they start with, so backtracking
+    // flavors match the longest word at a position. Otherwise the words are tried in
+    // the order of the list like with EitherOr
+    LongestFirst bool
+}
+
+// wordsBuilder converts a list of words into a syntax tree, factoring out their common
+// prefixes
+type wordsBuilder struct {
+    flavor RejexFlavor
+    longestFirst bool
+}
+
+// branch is a set of first characters which are followed by the same suffixes
+type branch struct {
+    heads []rune
+    sub Node
+}
+
+// alternatives returns the node matching a list of distinct words in their order, nil if
+// the only word is empty. Words starting with different characters cannot match at the
+// same position so only the position of an empty word has to be kept: the words before
+// it are tried first and the words after it only when the rest of the pattern does not
+// match after the empty word
+func (w wordsBuilder) alternatives(words [][]rune) Node {
+    end := -1
+    for i, word := range words {
+        if len(word) == 0 {
+            end = i
+            break
+        }
+    }
+    if end < 0 {
+        return w.prefixes(words)
+    }
+
+    before := append([][]rune{}, words[:end]...)
+    after := words[end+1:]
+    if w.longestFirst {
+        // the empty word is the shortest so it is tried last
+        before, after = append(before, after...), nil
+    }
+    switch {
+    case len(before) == 0 && len(after) == 0:
+        return nil
+    case len(after) == 0:
+        return &Repeat{Sub: groupWordNode(w.prefixes(before)), Min: 0, Max: 1, Mode: Greedy}
+    }
+    rest := &Repeat{Sub: groupWordNode(w.prefixes(after)), Min: 0, Max: 1, Mode: Lazy}
+    if len(before) == 0 {
+        return rest
+    }
+    alts := []Node{w.prefixes(before)}
+    if alt, ok := alts[0].(*Alternation); ok {
+        alts = alt.Alternatives
+    }
+    return &Alternation{append(alts, rest)}
+}
+
+// run is a sequence of words starting with the same character, with no words starting
+// with a character of the same case folding orbit in between
+type run struct {
+    head rune
+    suffixes [][]rune
+}
+
+// prefixes returns the node matching a list of distinct non empty words, grouped by their
+// first character. Words starting with characters of different case folding orbits
+// cannot match at the same position, even with the case insensitive flag, so only the
+// order of the words within an orbit is kept. Characters followed by the same suffixes
+// are merged into a class unless their orbit holds several runs
+func (w wordsBuilder) prefixes(words [][]rune) Node {
+    var keys []rune
+    orbits := map[rune][]*run{}
+    for _, word := range words {
+        k := foldKey(word[0])
+        runs, ok := orbits[k]
+        if !ok {
+            keys = append(keys, k)
+        }
+        if n := len(runs); n > 0 && runs[n-1].head == word[0] {
+            runs[n-1].suffixes = append(runs[n-1].suffixes, word[1:])
+            continue
+        }
+        orbits[k] = append(runs, &run{head: word[0], suffixes: [][]rune{word[1:]}})
+    }
+    sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+    var branches []*branch
+    bySuffix := map[string]*branch{}
+    for _, k := range keys {
+        runs := orbits[k]
+        for _, r := range runs {
+            sub := w.alternatives(r.suffixes)
+            key := "\x00"
+            if sub != nil {
+                key = render(w.flavor, sub)
+            }
+            if b, ok := bySuffix[key]; ok && len(runs) == 1 {
+                b.heads = append(b.heads, r.head)
+                continue
+            }
+            b := &branch{heads: []rune{r.head}, sub: sub}
+            if len(runs) == 1 {
+                bySuffix[key] = b
+            }
+            branches = append(branches, b)
+        }
+    }
+
+    var alts []Node
+    for _, b := range branches {
+        sort.Slice(b.heads, func(i, j int) bool { return b.heads[i] < b.heads[j] })
+        alts = append(alts, joinWordNodes(headNode(b.heads), b.sub))
+    }
+    if len(alts) == 1 {
+        return alts[0]
+    }
+    return &Alternation{alts}
+}
+
+// foldKey returns the smallest character of the case folding orbit of a character
+func foldKey(c rune) rune {
+    k := c
+    for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+        if f < k {
+            k = f
+        }
+    }
+    return k
+}
+
+// headNode returns a node matching any of a sorted list of characters, with runs of
+// consecutive characters written as ranges
+func headNode(heads []rune) Node {
+    if len(heads) == 1 {
+        return &Literal{string(heads)}
+    }
+    c := &Class{}
+    for i := 0; i < len(heads); {
+        j := i
+        for j+1 < len(heads) && heads[j+1] == heads[j]+1 {
+            j++
+        }
+        if j-i >= 2 {
+            c.Items = append(c.Items, charRange(heads[i], heads[j]))
+        } else {
+            for k := i; k <= j; k++ {
+                c.Items = append(c.Items, charRange(heads[k], heads[k]))
+            }
+        }
+        i = j + 1
+    }
+    return c
+}
+
+// groupWordNode wraps alternations and sequences in a non capturing group
+func groupWordNode(n Node) Node {
+    switch n.(type) {
+    case *Alternation, *Concat:
+        return &Group{Kind: NonCaptureGroup, Body: n}
+    }
+    return n
+}
+
+// joinWordNodes joins a head with the suffixes following it, merging adjacent literals
+func joinWordNodes(head, sub Node) Node {
+    if sub == nil {
+        return head
+    }
+    if _, ok := sub.(*Alternation); ok {
+        sub = &Group{Kind: NonCaptureGroup, Body: sub}
+    }
+    var nodes []Node
+    if c, ok := sub.(*Concat); ok {
+        nodes = c.Nodes
+    } else {
+        nodes = []Node{sub}
+    }
+    if h, ok := head.(*Literal); ok {
+        if l, ok := nodes[0].(*Literal); ok {
+            return joinNodes(append([]Node{&Literal{h.Text + l.Text}}, nodes[1:]...))
+        }
+    }
+    return joinNodes(append([]Node{head}, nodes...))
+}
+
+// AnyOfWords matches any of the provided words, escaped for the flavor. The words are
+// arranged in a prefix tree, so common prefixes are only matched once, and characters
+// followed by the same suffixes are collapsed into classes. The tree matches the same
+// text as the words joined with EitherOr
+func (r *RejexBuilder) AnyOfWords(words []string, opts ...WordsOptions) *RejexBuilder {
+    var o WordsOptions
+    if len(opts) > 0 {
+        o = opts[0]
+    }
+    if len(words) == 0 {
+        r.addError("No words provided to 'AnyOfWords()'")
+        return r
+    }
+
+    var list [][]rune
+    seen := map[string]bool{}
+    for _, word := range words {
+        // a repeated word can only match where its first occurrence already did
+        if !seen[word] {
+            seen[word] = true
+            list = append(list, []rune(word))
+        }
+    }
+    w := wordsBuilder{flavor: r.flavor, longestFirst: o.LongestFirst}
+    n := w.alternatives(list)
+    if n == nil {
+        r.addError("Only empty words provided to 'AnyOfWords()'")
+        return r
+    }
+    return r.appendNode(groupWordNode(n))
+}
@@ -0,0 +1,127 @@
+package rejex
+
+import (
+    "math/rand"
+    "reflect"
+    "regexp"
+    "strings"
+    "testing"
+)
+
+var wordLists = [][]string{
+    {"ab", "a", "ac"},
+    {"aa.", "", "cc"},
+    {"", "a", "ab"},
+    {"ab", "a", "abc"},
+    {"abc", "ab", "a"},
+    {"ab", "c", "ad"},
+    {"cat", "car", "cart", "walking", "talking"},
+    {"a", "a", "", ""},
+    {"ab", "A", "ac"},
+    {"foo", "fo", "bar", "f", "ba"},
+}
+
+// TestAnyOfWordsOrder checks that the words match the same text as their plain
+// alternation, with and without the case insensitive flag
+func TestAnyOfWordsOrder(t *testing.T) {
+    rnd := rand.New(rand.NewSource(1))
+    lists := append([][]string{}, wordLists...)
+    for i := 0; i < 300; i++ {
+        list := make([]string, 1+rnd.Intn(5))
+        for j := range list {
+            w := make([]byte, rnd.Intn(4))
+            for k := range w {
+                w[k] = "aAbc"[rnd.Intn(4)]
+            }
+            list[j] = string(w)
+        }
+        lists = append(lists, list)
+    }
+
+    for _, words := range lists {
+        quoted := make([]string, len(words))
+        for i, w := range words {
+            quoted[i] = regexp.QuoteMeta(w)
+        }
+        for _, flags := range []string{"", "(?i)"} {
+            pattern, errs := NewRejex(true).AnyOfWords(words).Build()
+            if failed(errs) {
+                // lists of empty words are rejected
+                continue
+            }
+            got := regexp.MustCompile(flags + pattern)
+            want := regexp.MustCompile(flags + "(?:" + strings.Join(quoted, "|") + ")")
+            for i := 0; i < 30; i++ {
+                s := make([]byte, rnd.Intn(6))
+                for j := range s {
+                    s[j] = "aAbc."[rnd.Intn(5)]
+                }
+                if !reflect.DeepEqual(got.FindAllStringIndex(string(s), -1), want.FindAllStringIndex(string(s), -1)) {
+                    t.Errorf("%q rendered as %s differs from %s on %q", words, got, want, s)
+                }
+            }
+        }
+    }
+}
+
+func TestAnyOfWordsPatterns(t *testing.T) {
+    tests := []struct {
+        words []string
+        longestFirst bool
+        want string
+    }{
+        {[]string{"walking", "talking", "cat", "car", "cart"}, true, `(?:ca(?:rt?|t)|[tw]alking)`},
+        {[]string{"ab", "a", "ac"}, false, `(?:a(?:b|c??))`},
+        {[]string{"ab", "a", "ac"}, true, `(?:a[bc]?)`},
+        {[]string{"aa.", "", "cc"}, false, `(?:aa\.|(?:cc)??)`},
+    }
+    for _, test := range tests {
+        got, errs := NewRejex().AnyOfWords(test.words, WordsOptions{LongestFirst: test.longestFirst}).Build()
+        if len(errs) > 0 || got != test.want {
+            t.Errorf("%q is rendered as %s %v, want %s", test.words, got, errs, test.want)
+        }
+    }
+}
+
+// TestAnyOfWordsLarge checks that a large list of words matches each of its words and
+// nothing else, and is shorter than their plain alternation
+func TestAnyOfWordsLarge(t *testing.T) {
+    rnd := rand.New(rand.NewSource(1))
+    words := map[string]bool{}
+    var list []string
+    for len(list) < 500 {
+        w := make([]byte, 1+rnd.Intn(6))
+        for i := range w {
+            w[i] = "abcde"[rnd.Intn(5)]
+        }
+        if !words[string(w)] {
+            words[string(w)] = true
+            list = append(list, string(w))
+        }
+    }
+
+    for _, longestFirst := range []bool{false, true} {
+        pattern, errs := NewRejex().AnyOfWords(list, WordsOptions{LongestFirst: longestFirst}).Build()
+        if len(errs) > 0 {
+            t.Fatalf("%d words are built with errors %v", len(list), errs)
+        }
+        if len(pattern) >= len(strings.Join(list, "|")) {
+            t.Errorf("%d words are not shortened: %s", len(list), pattern)
+        }
+        re := regexp.MustCompile("^" + pattern + "$")
+        for _, w := range list {
+            if !re.MatchString(w) {
+                t.Errorf("%q is not matched by the pattern of the words", w)
+            }
+        }
+        for i := 0; i < 2000; i++ {
+            w := make([]byte, 1+rnd.Intn(6))
+            for j := range w {
+                w[j] = "abcde"[rnd.Intn(5)]
+            }
+            if re.MatchString(string(w)) != words[string(w)] {
+                t.Errorf("%q is matched %v by the pattern of the words", w, !words[string(w)])
+            }
+        }
+    }
+}